@@ -0,0 +1,91 @@
+// Package chiroutes provides a types.RouteProvider that walks a chi router.
+package chiroutes
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/next-trace/scg-boost/internal/funcname"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Provider lists the routes of a chi router.
+type Provider struct {
+	r chi.Routes
+}
+
+// New returns a Provider for r, typically a *chi.Mux. Routes added after New
+// are included, since the router is walked on every List.
+func New(r chi.Routes) *Provider {
+	return &Provider{r: r}
+}
+
+// List implements types.RouteProvider. Middlewares are listed outermost
+// first, from the router and any mounted sub-routers and inline groups.
+func (p *Provider) List(context.Context) ([]types.RouteInfo, error) {
+	routes := []types.RouteInfo{}
+	err := chi.Walk(p.r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		routes = append(routes, types.RouteInfo{
+			Method:      method,
+			Path:        route,
+			Handler:     funcname.Of(handler),
+			Middlewares: funcname.All(middlewares...),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	routes = collapseAnyMethod(routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+// anyMethods are the methods chi registers for Handle and HandleFunc.
+var anyMethods = []string{
+	http.MethodConnect, http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodOptions,
+	http.MethodPatch, http.MethodPost, http.MethodPut, http.MethodTrace,
+}
+
+// collapseAnyMethod replaces a route registered for every method, as
+// Handle does, with a single "*" entry.
+func collapseAnyMethod(routes []types.RouteInfo) []types.RouteInfo {
+	key := func(r types.RouteInfo) string {
+		return r.Path + "\x00" + r.Handler + "\x00" + strings.Join(r.Middlewares, ",")
+	}
+	methods := map[string][]string{}
+	for _, r := range routes {
+		methods[key(r)] = append(methods[key(r)], r.Method)
+	}
+	out := routes[:0]
+	done := map[string]bool{}
+	for _, r := range routes {
+		k := key(r)
+		all := true
+		for _, m := range anyMethods {
+			if !slices.Contains(methods[k], m) {
+				all = false
+				break
+			}
+		}
+		if !all {
+			out = append(out, r)
+			continue
+		}
+		if !done[k] {
+			done[k] = true
+			r.Method = "*"
+			out = append(out, r)
+		}
+	}
+	return out
+}
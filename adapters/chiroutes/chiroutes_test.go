@@ -0,0 +1,56 @@
+package chiroutes
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const pkg = "github.com/next-trace/scg-boost/adapters/chiroutes."
+
+type orders struct{}
+
+func (orders) list(w http.ResponseWriter, r *http.Request)   {}
+func (orders) create(w http.ResponseWriter, r *http.Request) {}
+
+func auth(next http.Handler) http.Handler { return next }
+
+func TestList(t *testing.T) {
+	var o orders
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Route("/orders", func(r chi.Router) {
+		r.Use(auth)
+		r.Get("/", o.list)
+		r.With(middleware.NoCache).Post("/", o.create)
+	})
+	r.Handle("/static/*", http.FileServer(http.Dir(".")))
+
+	p := New(r)
+	routes, err := p.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.RouteInfo{
+		{Method: "GET", Path: "/orders/", Handler: pkg + "orders.list",
+			Middlewares: []string{"github.com/go-chi/chi/v5/middleware.RequestID", pkg + "auth"}},
+		{Method: "POST", Path: "/orders/", Handler: pkg + "orders.create",
+			Middlewares: []string{"github.com/go-chi/chi/v5/middleware.RequestID", pkg + "auth", "github.com/go-chi/chi/v5/middleware.NoCache"}},
+		{Method: "*", Path: "/static/*", Handler: "*http.fileHandler",
+			Middlewares: []string{"github.com/go-chi/chi/v5/middleware.RequestID"}},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes =\n%+v\nwant\n%+v", routes, want)
+	}
+
+	// Routes added later are picked up.
+	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	if routes, _ := p.List(context.Background()); len(routes) != 4 || routes[0].Path != "/healthz" {
+		t.Errorf("routes = %+v", routes)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/next-trace/scg-boost/adapters/chiroutes
+
+go 1.26.1
+
+require (
+	github.com/go-chi/chi/v5 v5.3.2
+	github.com/next-trace/scg-boost v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/next-trace/scg-boost => ../..
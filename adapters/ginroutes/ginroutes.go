@@ -0,0 +1,44 @@
+// Package ginroutes provides a types.RouteProvider for a gin engine.
+package ginroutes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/next-trace/scg-boost/internal/funcname"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Provider lists the routes of a gin engine.
+type Provider struct {
+	e *gin.Engine
+}
+
+// New returns a Provider for e. Routes added after New are included.
+func New(e *gin.Engine) *Provider {
+	return &Provider{e: e}
+}
+
+// List implements types.RouteProvider. gin does not expose per-route
+// handler chains, so Middlewares lists the engine's global middlewares
+// (those added with engine.Use); group middlewares are not included.
+func (p *Provider) List(context.Context) ([]types.RouteInfo, error) {
+	middlewares := funcname.All(p.e.Handlers...)
+	routes := []types.RouteInfo{}
+	for _, r := range p.e.Routes() {
+		routes = append(routes, types.RouteInfo{
+			Method:      r.Method,
+			Path:        r.Path,
+			Handler:     funcname.Of(r.HandlerFunc),
+			Middlewares: middlewares,
+		})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
@@ -0,0 +1,45 @@
+package ginroutes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const pkg = "github.com/next-trace/scg-boost/adapters/ginroutes."
+
+type orders struct{}
+
+func (orders) list(c *gin.Context)   {}
+func (orders) create(c *gin.Context) {}
+
+func auth(c *gin.Context)      { c.Next() }
+func requestID(c *gin.Context) { c.Next() }
+
+func TestList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var o orders
+	e := gin.New()
+	e.Use(requestID)
+	api := e.Group("/orders", auth)
+	api.GET("/:id", o.list)
+	api.POST("", o.create)
+
+	routes, err := New(e).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("routes = %+v", routes)
+	}
+	want := types.RouteInfo{Method: "POST", Path: "/orders", Handler: pkg + "orders.create", Middlewares: []string{pkg + "requestID"}}
+	if !reflect.DeepEqual(routes[0], want) {
+		t.Errorf("routes[0] = %+v, want %+v", routes[0], want)
+	}
+	if routes[1].Method != "GET" || routes[1].Path != "/orders/:id" || routes[1].Handler != pkg+"orders.list" {
+		t.Errorf("routes[1] = %+v", routes[1])
+	}
+}
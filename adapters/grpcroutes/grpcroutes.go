@@ -0,0 +1,102 @@
+// Package grpcroutes provides a types.RouteProvider for the services
+// registered on a gRPC server.
+package grpcroutes
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/next-trace/scg-boost/internal/funcname"
+	"github.com/next-trace/scg-boost/types"
+	"google.golang.org/grpc"
+)
+
+// Method is the RouteInfo.Method of gRPC routes.
+const Method = "GRPC"
+
+// ServiceInfoProvider is implemented by *grpc.Server.
+type ServiceInfoProvider interface {
+	GetServiceInfo() map[string]grpc.ServiceInfo
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithInterceptors records the server's interceptors, which gRPC does not
+// expose, as the middlewares of every route. Pass them in the order given
+// to grpc.ChainUnaryInterceptor or grpc.ChainStreamInterceptor.
+func WithInterceptors(interceptors ...any) Option {
+	return func(p *Provider) { p.interceptors = append(p.interceptors, funcname.All(interceptors...)...) }
+}
+
+// WithImplementations supplies the values registered as service
+// implementations. A service's handler symbols come from the first
+// implementation that has all of its methods.
+func WithImplementations(impls ...any) Option {
+	return func(p *Provider) { p.impls = append(p.impls, impls...) }
+}
+
+// Provider lists the methods of a gRPC server's services.
+type Provider struct {
+	s            ServiceInfoProvider
+	interceptors []string
+	impls        []any
+}
+
+// New returns a Provider for s, typically a *grpc.Server.
+func New(s ServiceInfoProvider, opts ...Option) *Provider {
+	p := &Provider{s: s}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// List implements types.RouteProvider. Paths are full method names such as
+// /grpc.health.v1.Health/Check. Without a matching implementation the
+// handler is the service and method name.
+func (p *Provider) List(context.Context) ([]types.RouteInfo, error) {
+	routes := []types.RouteInfo{}
+	for service, info := range p.s.GetServiceInfo() {
+		impl := p.implementation(info.Methods)
+		for _, m := range info.Methods {
+			handler := service + "." + m.Name
+			if impl != nil {
+				if method, ok := impl.MethodByName(m.Name); ok {
+					handler = funcname.Of(method.Func.Interface())
+				}
+			}
+			routes = append(routes, types.RouteInfo{
+				Method:      Method,
+				Path:        "/" + service + "/" + m.Name,
+				Handler:     handler,
+				Middlewares: p.interceptors,
+			})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	return routes, nil
+}
+
+// implementation returns the type of the first implementation with every
+// method in methods.
+func (p *Provider) implementation(methods []grpc.MethodInfo) reflect.Type {
+	for _, impl := range p.impls {
+		t := reflect.TypeOf(impl)
+		if t == nil {
+			continue
+		}
+		ok := true
+		for _, m := range methods {
+			if _, found := t.MethodByName(m.Name); !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package grpcroutes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const pkg = "github.com/next-trace/scg-boost/adapters/grpcroutes."
+
+func logging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(ctx, req)
+}
+
+// echo is a service described by hand, without generated code.
+type echo struct{}
+
+func (*echo) Say(ctx context.Context, in string) (string, error) { return in, nil }
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods:     []grpc.MethodDesc{{MethodName: "Say"}},
+}
+
+func TestList(t *testing.T) {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(logging))
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	e := &echo{}
+	srv.RegisterService(&echoDesc, e)
+
+	routes, err := New(srv, WithInterceptors(logging), WithImplementations(e)).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw := []string{pkg + "logging"}
+	want := []types.RouteInfo{
+		{Method: Method, Path: "/grpc.health.v1.Health/Check", Handler: "grpc.health.v1.Health.Check", Middlewares: mw},
+		{Method: Method, Path: "/grpc.health.v1.Health/List", Handler: "grpc.health.v1.Health.List", Middlewares: mw},
+		{Method: Method, Path: "/grpc.health.v1.Health/Watch", Handler: "grpc.health.v1.Health.Watch", Middlewares: mw},
+		{Method: Method, Path: "/test.Echo/Say", Handler: pkg + "(*echo).Say", Middlewares: mw},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes =\n%+v\nwant\n%+v", routes, want)
+	}
+
+	routes, _ = New(srv, WithImplementations(e, hs)).List(context.Background())
+	if routes[0].Handler != "google.golang.org/grpc/health.(*Server).Check" || routes[0].Middlewares != nil {
+		t.Errorf("routes[0] = %+v", routes[0])
+	}
+}
@@ -0,0 +1,203 @@
+// Package httphealth provides a built-in types.HealthProbe that probes a
+// service's existing HTTP health endpoints, such as /healthz and /readyz.
+package httphealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Defaults used by New.
+const (
+	DefaultBaseURL       = "http://localhost:8080"
+	DefaultLivenessPath  = "/healthz"
+	DefaultReadinessPath = "/readyz"
+	DefaultTimeout       = 2 * time.Second
+)
+
+// maxBody bounds how much of a response body is read.
+const maxBody = 64 << 10
+
+// Endpoint describes one health URL and what a healthy response looks like.
+type Endpoint struct {
+	URL string
+	// ExpectStatus lists accepted status codes; empty accepts any 2xx.
+	ExpectStatus []int
+	// JSONChecks maps dotted paths in a JSON body (e.g. "status" or
+	// "checks.db.status") to their expected values, compared as strings.
+	JSONChecks map[string]string
+}
+
+// Option configures a Probe.
+type Option func(*Probe)
+
+// WithLivenessURL overrides the liveness URL.
+func WithLivenessURL(u string) Option { return func(p *Probe) { p.liveness.URL = u } }
+
+// WithReadinessURL overrides the readiness URL.
+func WithReadinessURL(u string) Option { return func(p *Probe) { p.readiness.URL = u } }
+
+// WithTimeout bounds each request; the caller's context still applies.
+func WithTimeout(d time.Duration) Option { return func(p *Probe) { p.timeout = d } }
+
+// WithExpectStatus sets the accepted status codes for both endpoints.
+func WithExpectStatus(codes ...int) Option {
+	return func(p *Probe) {
+		p.liveness.ExpectStatus = codes
+		p.readiness.ExpectStatus = codes
+	}
+}
+
+// WithJSONCheck requires the JSON body of both endpoints to hold want at the
+// dotted path.
+func WithJSONCheck(path, want string) Option {
+	return func(p *Probe) {
+		for _, e := range []*Endpoint{&p.liveness, &p.readiness} {
+			if e.JSONChecks == nil {
+				e.JSONChecks = map[string]string{}
+			}
+			e.JSONChecks[path] = want
+		}
+	}
+}
+
+// WithHTTPClient sets the client used for requests.
+func WithHTTPClient(c *http.Client) Option { return func(p *Probe) { p.client = c } }
+
+// Probe implements types.HealthProbe over HTTP.
+type Probe struct {
+	client    *http.Client
+	timeout   time.Duration
+	liveness  Endpoint
+	readiness Endpoint
+}
+
+// New returns a Probe for the service at baseURL (DefaultBaseURL when
+// empty), checking DefaultLivenessPath and DefaultReadinessPath. When
+// baseURL already has a path, that URL is probed for both.
+func New(baseURL string, opts ...Option) *Probe {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	live, ready := baseURL, baseURL
+	if u, err := url.Parse(baseURL); err == nil && strings.Trim(u.Path, "/") == "" {
+		base := strings.TrimSuffix(baseURL, "/")
+		live, ready = base+DefaultLivenessPath, base+DefaultReadinessPath
+	}
+	p := &Probe{
+		client:    http.DefaultClient,
+		timeout:   DefaultTimeout,
+		liveness:  Endpoint{URL: live},
+		readiness: Endpoint{URL: ready},
+	}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(p)
+		}
+	}
+	return p
+}
+
+// Liveness implements types.HealthProbe.
+func (p *Probe) Liveness(ctx context.Context) error { return p.check(ctx, p.liveness) }
+
+// Readiness implements types.HealthProbe.
+func (p *Probe) Readiness(ctx context.Context) error { return p.check(ctx, p.readiness) }
+
+// check GETs e.URL and returns an error describing why it is unhealthy.
+func (p *Probe) check(ctx context.Context, e Endpoint) error {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL, nil)
+	if err != nil {
+		return fmt.Errorf("health request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("GET %s: timed out after %s", e.URL, p.timeout)
+		}
+		return fmt.Errorf("GET %s: %w", e.URL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return fmt.Errorf("GET %s: read body: %w", e.URL, err)
+	}
+
+	if !statusOK(resp.StatusCode, e.ExpectStatus) {
+		msg := fmt.Sprintf("GET %s: status %d", e.URL, resp.StatusCode)
+		if snippet := strings.TrimSpace(string(body)); snippet != "" {
+			if len(snippet) > 200 {
+				snippet = snippet[:200] + "..."
+			}
+			msg += ": " + snippet
+		}
+		return errors.New(msg)
+	}
+	if len(e.JSONChecks) == 0 {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("GET %s: body is not JSON: %w", e.URL, err)
+	}
+	paths := make([]string, 0, len(e.JSONChecks))
+	for path := range e.JSONChecks {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		want := e.JSONChecks[path]
+		got, ok := lookup(doc, path)
+		if !ok {
+			return fmt.Errorf("GET %s: body has no %q", e.URL, path)
+		}
+		if got != want {
+			return fmt.Errorf("GET %s: %s = %q, want %q", e.URL, path, got, want)
+		}
+	}
+	return nil
+}
+
+func statusOK(code int, expect []int) bool {
+	if len(expect) == 0 {
+		return code >= 200 && code < 300
+	}
+	return slices.Contains(expect, code)
+}
+
+// lookup resolves a dotted path in a decoded JSON document and formats the
+// value as a string.
+func lookup(doc any, path string) (string, bool) {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if doc, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := doc.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "null", true
+	default:
+		b, _ := json.Marshal(v)
+		return string(b), true
+	}
+}
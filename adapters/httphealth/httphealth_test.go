@@ -0,0 +1,93 @@
+package httphealth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","checks":{"db":{"status":"up"}}}`))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"draining"}`))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbe_DefaultPaths(t *testing.T) {
+	srv := newServer(t)
+	p := New(srv.URL + "/")
+	if err := p.Liveness(context.Background()); err != nil {
+		t.Errorf("Liveness() = %v", err)
+	}
+	err := p.Readiness(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 503") || !strings.Contains(err.Error(), "draining") {
+		t.Errorf("Readiness() = %v, want status and body", err)
+	}
+
+	// 503 accepted explicitly.
+	p = New(srv.URL, WithExpectStatus(http.StatusServiceUnavailable))
+	if err := p.Readiness(context.Background()); err != nil {
+		t.Errorf("Readiness() with expected 503 = %v", err)
+	}
+}
+
+func TestProbe_JSONChecks(t *testing.T) {
+	srv := newServer(t)
+	p := New(srv.URL+"/healthz", WithJSONCheck("checks.db.status", "up"))
+	if err := p.Liveness(context.Background()); err != nil {
+		t.Errorf("Liveness() = %v", err)
+	}
+	if err := p.Readiness(context.Background()); err != nil {
+		t.Errorf("Readiness() on the same URL = %v", err)
+	}
+
+	p = New(srv.URL+"/healthz", WithJSONCheck("status", "healthy"))
+	if err := p.Liveness(context.Background()); err == nil || !strings.Contains(err.Error(), `status = "ok", want "healthy"`) {
+		t.Errorf("Liveness() = %v, want a body mismatch", err)
+	}
+	p = New(srv.URL+"/healthz", WithJSONCheck("checks.cache", "up"))
+	if err := p.Liveness(context.Background()); err == nil || !strings.Contains(err.Error(), "no \"checks.cache\"") {
+		t.Errorf("Liveness() = %v, want a missing field", err)
+	}
+}
+
+func TestProbe_Timeout(t *testing.T) {
+	srv := newServer(t)
+	p := New(srv.URL, WithLivenessURL(srv.URL+"/slow"), WithTimeout(20*time.Millisecond))
+	start := time.Now()
+	err := p.Liveness(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Liveness() = %v, want a timeout", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("timeout not applied")
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	p := New("")
+	if p.liveness.URL != DefaultBaseURL+DefaultLivenessPath || p.readiness.URL != DefaultBaseURL+DefaultReadinessPath {
+		t.Errorf("urls = %q, %q", p.liveness.URL, p.readiness.URL)
+	}
+	p = New("http://localhost:9000", WithReadinessURL("http://localhost:9000/ready"))
+	if p.liveness.URL != "http://localhost:9000/healthz" || p.readiness.URL != "http://localhost:9000/ready" {
+		t.Errorf("urls = %q, %q", p.liveness.URL, p.readiness.URL)
+	}
+}
@@ -0,0 +1,52 @@
+package logfile
+
+import (
+	"bytes"
+	"io"
+)
+
+const chunkSize = 64 << 10
+
+// backScanner yields the lines of r from end toward the start, reading in
+// chunks so tailing a large file only touches its end.
+type backScanner struct {
+	r        io.ReaderAt
+	buf      []byte // unconsumed bytes [bufStart, bufStart+len(buf))
+	bufStart int64
+}
+
+// newBackScanner scans r backwards from offset end.
+func newBackScanner(r io.ReaderAt, end int64) *backScanner {
+	return &backScanner{r: r, bufStart: end}
+}
+
+// prev returns the previous non-empty line and its start offset. It returns
+// io.EOF once the start of the file is reached.
+func (b *backScanner) prev() ([]byte, int64, error) {
+	for {
+		if i := bytes.LastIndexByte(b.buf, '\n'); i >= 0 {
+			line := b.buf[i+1:]
+			start := b.bufStart + int64(i) + 1
+			b.buf = b.buf[:i]
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			return line, start, nil
+		}
+		if b.bufStart == 0 {
+			line := b.buf
+			b.buf = nil
+			if len(bytes.TrimSpace(line)) == 0 {
+				return nil, 0, io.EOF
+			}
+			return line, 0, nil
+		}
+		n := min(int64(chunkSize), b.bufStart)
+		chunk := make([]byte, n, n+int64(len(b.buf)))
+		if _, err := b.r.ReadAt(chunk, b.bufStart-n); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		b.buf = append(chunk, b.buf...)
+		b.bufStart -= n
+	}
+}
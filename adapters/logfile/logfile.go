@@ -0,0 +1,423 @@
+// Package logfile provides a built-in types.LogStore and types.LogQuerier for
+// JSON-lines (slog, zap, zerolog) and logfmt log files. It reads the active
+// file from the tail and follows numbered rotations (app.log.1, app.log.2.gz),
+// so recent entries are found without scanning whole files.
+package logfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultMaxRotated is the number of rotated files searched by default.
+const DefaultMaxRotated = 5
+
+// DefaultGzipCacheBytes bounds the decompressed rotations kept in memory.
+const DefaultGzipCacheBytes = 64 << 20
+
+// ErrInvalidCursor is returned for cursors that are malformed or whose file
+// is no longer among the searched files.
+var ErrInvalidCursor = errors.New("logfile: invalid cursor")
+
+// fingerprintBytes is how much of a file's first line identifies it.
+const fingerprintBytes = 4 << 10
+
+// errorLevels are the levels LastError treats as errors.
+var errorLevels = []string{"error", "critical", "fatal", "panic", "dpanic"}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithMaxRotated limits how many rotated files are searched; 0 searches only
+// the active file.
+func WithMaxRotated(n int) Option { return func(s *Store) { s.maxRotated = n } }
+
+// WithGzipCacheBytes bounds the memory used for decompressed .gz rotations.
+// Least recently used rotations are evicted first; a rotation larger than n
+// is decompressed for each search and not cached. 0 disables the cache.
+func WithGzipCacheBytes(n int64) Option { return func(s *Store) { s.gzCacheMax = n } }
+
+// Store reads log entries from a log file and its rotations.
+type Store struct {
+	path       string
+	maxRotated int
+	gzCacheMax int64
+
+	mu          sync.Mutex
+	gzCache     map[string]*gzFile
+	gzCacheSize int64
+	gzClock     int64
+}
+
+// gzFile caches a decompressed rotation; rotated files do not change, so
+// they are keyed by path and invalidated by size or modification time.
+type gzFile struct {
+	size     int64
+	modTime  time.Time
+	data     []byte
+	lastUsed int64
+}
+
+// New returns a Store for the log file at path.
+func New(path string, opts ...Option) *Store {
+	s := &Store{path: path, maxRotated: DefaultMaxRotated, gzCacheMax: DefaultGzipCacheBytes, gzCache: make(map[string]*gzFile)}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(s)
+		}
+	}
+	return s
+}
+
+// LastError implements types.LogStore. It returns the newest entry at error
+// level or above, with its level in fields["level"].
+func (s *Store) LastError(ctx context.Context) (string, string, map[string]any, error) {
+	page, err := s.SearchLogs(ctx, types.LogQuery{Levels: errorLevels, Limit: 1})
+	if err != nil || len(page.Entries) == 0 {
+		return "", "", nil, err
+	}
+	e := page.Entries[0]
+	fields := make(map[string]any, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+	fields["level"] = e.Level
+	ts := ""
+	if !e.Timestamp.IsZero() {
+		ts = e.Timestamp.Format(time.RFC3339Nano)
+	}
+	return ts, e.Message, fields, nil
+}
+
+// SearchLogs implements types.LogQuerier. Entries are returned newest first.
+// Files are assumed to be written in time order, so scanning stops at the
+// first entry older than q.Since.
+func (s *Store) SearchLogs(ctx context.Context, q types.LogQuery) (*types.LogPage, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	first, offset := 0, int64(-1)
+	if q.Cursor != "" {
+		c, err := parseCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if first, err = s.resolve(files, c); err != nil {
+			return nil, err
+		}
+		offset = c.offset
+	}
+
+	page := &types.LogPage{}
+	resume := "" // position after the last returned entry
+	for i := first; i < len(files); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		r, size, closeFn, err := s.open(files[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue // rotated away while searching
+		}
+		if err != nil {
+			return nil, err
+		}
+		end := size
+		if i == first && offset >= 0 {
+			end = min(offset, size)
+		}
+
+		name := filepath.Base(files[i])
+		fp, err := fingerprint(r, size)
+		if err != nil {
+			closeFn()
+			return nil, err
+		}
+		done, err := scan(r, end, func(e types.LogEntry, start int64) bool {
+			if !q.Since.IsZero() && !e.Timestamp.IsZero() && e.Timestamp.Before(q.Since) {
+				return false
+			}
+			if !q.Match(e) {
+				return true
+			}
+			if len(page.Entries) == limit {
+				// Another match exists, so an older page is worth offering.
+				page.NextCursor = resume
+				return false
+			}
+			page.Entries = append(page.Entries, e)
+			resume = formatCursor(cursor{name: name, fingerprint: fp, offset: start})
+			return true
+		})
+		closeFn()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+	return page, nil
+}
+
+// scan calls fn for each parsed entry of r before end, newest first, until
+// fn returns false. It reports whether fn stopped the scan.
+func scan(r io.ReaderAt, end int64, fn func(e types.LogEntry, start int64) bool) (bool, error) {
+	bs := newBackScanner(r, end)
+	for {
+		line, start, err := bs.prev()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		e, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		if !fn(e, start) {
+			return true, nil
+		}
+	}
+}
+
+// cursor is the position an older page resumes from: the offset of the last
+// returned line in the file identified by fingerprint. The file's base name
+// at the time is a hint; after a rotation the same content has a new name,
+// such as app.log.1 or app.log.1.gz.
+type cursor struct {
+	name        string
+	fingerprint uint64
+	offset      int64
+}
+
+func formatCursor(c cursor) string {
+	return c.name + ":" + strconv.FormatUint(c.fingerprint, 16) + ":" + strconv.FormatInt(c.offset, 10)
+}
+
+func parseCursor(s string) (cursor, error) {
+	j := strings.LastIndexByte(s, ':')
+	i := -1
+	if j > 0 {
+		i = strings.LastIndexByte(s[:j], ':')
+	}
+	if i <= 0 {
+		return cursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	fp, err1 := strconv.ParseUint(s[i+1:j], 16, 64)
+	off, err2 := strconv.ParseInt(s[j+1:], 10, 64)
+	if err1 != nil || err2 != nil || off < 0 {
+		return cursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	return cursor{name: s[:i], fingerprint: fp, offset: off}, nil
+}
+
+// resolve returns the index in files of the file c points into, trying the
+// file with c's name first.
+func (s *Store) resolve(files []string, c cursor) (int, error) {
+	order := make([]int, 0, len(files))
+	for i, f := range files {
+		if filepath.Base(f) == c.name {
+			order = append([]int{i}, order...)
+		} else {
+			order = append(order, i)
+		}
+	}
+	for _, i := range order {
+		r, size, closeFn, err := s.open(files[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		fp, err := fingerprint(r, size)
+		closeFn()
+		if err != nil {
+			return 0, err
+		}
+		if fp == c.fingerprint && c.offset <= size {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: the file it refers to (%s) is no longer searched", ErrInvalidCursor, c.name)
+}
+
+// fingerprint identifies a file by a hash of its first line, which survives
+// renames and compression of rotations.
+func fingerprint(r io.ReaderAt, size int64) (uint64, error) {
+	buf := make([]byte, min(size, fingerprintBytes))
+	if _, err := r.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return 0, err
+	}
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i]
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(buf)
+	return h.Sum64(), nil
+}
+
+var rotationRe = regexp.MustCompile(`^\.(\d+)(\.gz)?$`)
+
+// files returns the active file followed by its rotations, newest first.
+func (s *Store) files() ([]string, error) {
+	out := []string{s.path}
+	if s.maxRotated <= 0 {
+		return out, nil
+	}
+	matches, err := filepath.Glob(globEscape(s.path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	type rotated struct {
+		path string
+		n    int
+	}
+	var rs []rotated
+	for _, m := range matches {
+		sm := rotationRe.FindStringSubmatch(strings.TrimPrefix(m, s.path))
+		if sm == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(sm[1])
+		rs = append(rs, rotated{m, n})
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].n < rs[j].n })
+	for i := 0; i < len(rs) && i < s.maxRotated; i++ {
+		out = append(out, rs[i].path)
+	}
+
+	// Drop cached rotations that were renamed or deleted.
+	s.mu.Lock()
+	for p, c := range s.gzCache {
+		if !slices.Contains(out, p) {
+			s.gzCacheSize -= int64(len(c.data))
+			delete(s.gzCache, p)
+		}
+	}
+	s.mu.Unlock()
+	return out, nil
+}
+
+func globEscape(p string) string {
+	return strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(p)
+}
+
+// open returns a ReaderAt over the (decompressed) file and its size.
+func (s *Store) open(path string) (io.ReaderAt, int64, func(), error) {
+	if !strings.HasSuffix(path, ".gz") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		st, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, 0, nil, err
+		}
+		return f, st.Size(), func() { _ = f.Close() }, nil
+	}
+
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if data := s.cachedGzip(path, st); data != nil {
+		return bytes.NewReader(data), int64(len(data)), func() {}, nil
+	}
+	data, err := readGzip(path)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	s.cacheGzip(path, &gzFile{size: st.Size(), modTime: st.ModTime(), data: data})
+	return bytes.NewReader(data), int64(len(data)), func() {}, nil
+}
+
+// cachedGzip returns the cached decompressed content of path, or nil when it
+// is not cached or the file changed.
+func (s *Store) cachedGzip(path string, st os.FileInfo) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.gzCache[path]
+	if !ok {
+		return nil
+	}
+	if c.size != st.Size() || !c.modTime.Equal(st.ModTime()) {
+		s.gzCacheSize -= int64(len(c.data))
+		delete(s.gzCache, path)
+		return nil
+	}
+	s.gzClock++
+	c.lastUsed = s.gzClock
+	return c.data
+}
+
+// cacheGzip stores c, evicting least recently used rotations to stay within
+// the byte budget. Rotations larger than the budget are not cached.
+func (s *Store) cacheGzip(path string, c *gzFile) {
+	n := int64(len(c.data))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.gzCacheMax {
+		return
+	}
+	if old, ok := s.gzCache[path]; ok {
+		s.gzCacheSize -= int64(len(old.data))
+		delete(s.gzCache, path)
+	}
+	for s.gzCacheSize+n > s.gzCacheMax {
+		oldest := ""
+		for p, e := range s.gzCache {
+			if oldest == "" || e.lastUsed < s.gzCache[oldest].lastUsed {
+				oldest = p
+			}
+		}
+		s.gzCacheSize -= int64(len(s.gzCache[oldest].data))
+		delete(s.gzCache, oldest)
+	}
+	s.gzClock++
+	c.lastUsed = s.gzClock
+	s.gzCache[path] = c
+	s.gzCacheSize += n
+}
+
+func readGzip(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("logfile: %s: %w", path, err)
+	}
+	defer func() { _ = zr.Close() }()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("logfile: %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,206 @@
+package logfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+func writeFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	body := []byte(strings.Join(lines, "\n") + "\n")
+	if strings.HasSuffix(path, ".gz") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		body = buf.Bytes()
+	}
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		level string
+		msg   string
+		ts    string
+		field string
+	}{
+		{`{"time":"2024-05-01T10:00:00.5Z","level":"ERROR","msg":"boom","request_id":"r1"}`, "error", "boom", "2024-05-01T10:00:00.5Z", "r1"},
+		{`{"level":"warn","ts":1714557600.25,"msg":"slow","request_id":"r2"}`, "warn", "slow", "2024-05-01T10:00:00.25Z", "r2"},
+		{`time=2024-05-01T10:00:00Z level=INFO msg="hello world" request_id=r3`, "info", "hello world", "2024-05-01T10:00:00Z", "r3"},
+		{`panic: runtime error`, "", "panic: runtime error", "", ""},
+	}
+	for _, tt := range tests {
+		e, ok := parseLine([]byte(tt.line))
+		if !ok {
+			t.Fatalf("parseLine(%s) not ok", tt.line)
+		}
+		ts := ""
+		if !e.Timestamp.IsZero() {
+			ts = e.Timestamp.UTC().Format(time.RFC3339Nano)
+		}
+		if e.Level != tt.level || e.Message != tt.msg || ts != tt.ts || fmt.Sprint(e.Fields["request_id"]) != fmt.Sprint(orNil(tt.field)) {
+			t.Errorf("parseLine(%s) = %+v (ts %s)", tt.line, e, ts)
+		}
+	}
+	if _, ok := parseLine([]byte(`{"level":"info","msg":"trunc`)); ok {
+		t.Error("truncated JSON line should be skipped")
+	}
+}
+
+func orNil(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func TestStore_RotationsAndPaging(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	line := func(min int, level, msg string) string {
+		return fmt.Sprintf(`{"time":"2024-05-01T10:%02d:00Z","level":%q,"msg":%q}`, min, level, msg)
+	}
+	writeFile(t, path+".2.gz", line(0, "error", "oldest error"), line(1, "info", "a"))
+	writeFile(t, path+".1", line(2, "info", "b"), line(3, "error", "middle error"))
+	writeFile(t, path, line(4, "info", "c"), line(5, "warn", "latest"))
+	writeFile(t, path+".bak", line(9, "error", "not a rotation"))
+
+	s := New(path)
+	ctx := context.Background()
+
+	ts, msg, fields, err := s.LastError(ctx)
+	if err != nil || msg != "middle error" || ts != "2024-05-01T10:03:00Z" || fields["level"] != "error" {
+		t.Fatalf("LastError() = %q %q %v %v", ts, msg, fields, err)
+	}
+
+	var got []string
+	q := types.LogQuery{Limit: 4}
+	for {
+		page, err := s.SearchLogs(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range page.Entries {
+			got = append(got, e.Message)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	want := "latest,c,middle error,b,a,oldest error"
+	if strings.Join(got, ",") != want {
+		t.Errorf("entries = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	page, err := s.SearchLogs(ctx, types.LogQuery{Levels: []string{"error"}, Limit: 10})
+	if err != nil || len(page.Entries) != 2 || page.NextCursor != "" {
+		t.Errorf("error search = %+v, %v", page, err)
+	}
+
+	since := time.Date(2024, 5, 1, 10, 4, 0, 0, time.UTC)
+	page, err = s.SearchLogs(ctx, types.LogQuery{Since: since, Limit: 10})
+	if err != nil || len(page.Entries) != 2 {
+		t.Errorf("since search = %+v, %v", page, err)
+	}
+
+	if page, _ := New(path, WithMaxRotated(0)).SearchLogs(ctx, types.LogQuery{Limit: 10}); len(page.Entries) != 2 {
+		t.Errorf("active file only: got %d entries, want 2", len(page.Entries))
+	}
+}
+
+func TestStore_LargeFileTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var lines []string
+	for i := 0; i < 5000; i++ {
+		lines = append(lines, fmt.Sprintf(`{"level":"info","msg":"line %d","pad":%q}`, i, strings.Repeat("x", 40)))
+	}
+	writeFile(t, path, lines...)
+
+	page, err := New(path).SearchLogs(context.Background(), types.LogQuery{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 3 || page.Entries[0].Message != "line 4999" || page.Entries[2].Message != "line 4997" {
+		t.Errorf("tail = %+v", page.Entries)
+	}
+
+	page, err = New(path).SearchLogs(context.Background(), types.LogQuery{Contains: "line 7", Limit: 1000})
+	if err != nil || len(page.Entries) != 111 {
+		t.Errorf("contains search across chunks: got %d entries, %v", len(page.Entries), err)
+	}
+}
+
+func TestStore_CursorSurvivesRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	line := func(min int, msg string) string {
+		return fmt.Sprintf(`{"time":"2024-05-01T10:%02d:00Z","level":"info","msg":%q}`, min, msg)
+	}
+	writeFile(t, path, line(0, "a"), line(1, "b"), line(2, "c"))
+
+	s := New(path)
+	ctx := context.Background()
+	page, err := s.SearchLogs(ctx, types.LogQuery{Limit: 1})
+	if err != nil || page.Entries[0].Message != "c" || page.NextCursor == "" {
+		t.Fatalf("first page = %+v, %v", page, err)
+	}
+
+	// Rotate: app.log becomes app.log.1.gz and a new app.log starts.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path+".1.gz", line(0, "a"), line(1, "b"), line(2, "c"))
+	writeFile(t, path, line(3, "unrelated"), line(4, "new"), line(5, "newer"))
+
+	page, err = s.SearchLogs(ctx, types.LogQuery{Limit: 1, Cursor: page.NextCursor})
+	if err != nil || len(page.Entries) != 1 || page.Entries[0].Message != "b" {
+		t.Errorf("page after rotation = %+v, %v", page, err)
+	}
+
+	_, err = s.SearchLogs(ctx, types.LogQuery{Cursor: "app.log:12345:10"})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("unknown file: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := s.SearchLogs(ctx, types.LogQuery{Cursor: "app.log:10"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("old cursor format: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestStore_GzipCacheBudget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	big := fmt.Sprintf(`{"level":"info","msg":%q}`, strings.Repeat("x", 1000))
+	writeFile(t, path, big)
+	writeFile(t, path+".1.gz", big)
+	writeFile(t, path+".2.gz", big, big)
+
+	s := New(path, WithGzipCacheBytes(1500))
+	if _, err := s.SearchLogs(context.Background(), types.LogQuery{Limit: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.gzCache[path+".2.gz"]; ok || s.gzCacheSize > 1500 {
+		t.Errorf("cache holds %d bytes (%d files), want only rotations within 1500 bytes", s.gzCacheSize, len(s.gzCache))
+	}
+	if _, ok := s.gzCache[path+".1.gz"]; !ok {
+		t.Error("small rotation not cached")
+	}
+}
@@ -0,0 +1,197 @@
+package logfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Well-known keys written by slog, zap, zerolog, logrus and friends.
+var (
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t"}
+	levelKeys   = []string{"level", "lvl", "severity", "@level"}
+	messageKeys = []string{"msg", "message", "@message"}
+)
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700", // zap ISO8601
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseLine decodes one JSON or logfmt line. Lines that are neither are kept
+// as plain-text messages; it reports false only for blank or truncated JSON
+// lines.
+func parseLine(line []byte) (types.LogEntry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return types.LogEntry{}, false
+	}
+	if line[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var fields map[string]any
+		if err := dec.Decode(&fields); err != nil {
+			return types.LogEntry{}, false
+		}
+		return entryFromFields(fields), true
+	}
+	if fields := parseLogfmt(string(line)); fields != nil {
+		return entryFromFields(fields), true
+	}
+	return types.LogEntry{Message: string(line)}, true
+}
+
+// entryFromFields moves the time, level and message keys out of fields.
+func entryFromFields(fields map[string]any) types.LogEntry {
+	var e types.LogEntry
+	if v, k := lookup(fields, timeKeys); k != "" {
+		if ts, ok := parseTime(v); ok {
+			e.Timestamp = ts
+			delete(fields, k)
+		}
+	}
+	if v, k := lookup(fields, levelKeys); k != "" {
+		if s, ok := v.(string); ok {
+			e.Level = normalizeLevel(s)
+			delete(fields, k)
+		}
+	}
+	if v, k := lookup(fields, messageKeys); k != "" {
+		if s, ok := v.(string); ok {
+			e.Message = s
+			delete(fields, k)
+		}
+	}
+	if len(fields) > 0 {
+		e.Fields = fields
+	}
+	return e
+}
+
+func lookup(fields map[string]any, keys []string) (any, string) {
+	for _, k := range keys {
+		if v, ok := fields[k]; ok {
+			return v, k
+		}
+	}
+	return nil, ""
+}
+
+func parseTime(v any) (time.Time, bool) {
+	switch x := v.(type) {
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t, true
+			}
+		}
+		if f, err := strconv.ParseFloat(x, 64); err == nil {
+			return epoch(f), true
+		}
+	case json.Number:
+		if f, err := x.Float64(); err == nil {
+			return epoch(f), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// epoch interprets f as Unix seconds, milliseconds, microseconds or
+// nanoseconds depending on its magnitude.
+func epoch(f float64) time.Time {
+	switch {
+	case f > 1e17:
+		return time.Unix(0, int64(f)).UTC()
+	case f > 1e14:
+		return time.UnixMicro(int64(f)).UTC()
+	case f > 1e11:
+		return time.UnixMilli(int64(f)).UTC()
+	default:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	}
+}
+
+// normalizeLevel lowercases levels and maps common aliases, so "ERROR",
+// "err" and "error" all filter the same way.
+func normalizeLevel(l string) string {
+	l = strings.ToLower(strings.TrimSpace(l))
+	switch l {
+	case "err", "eror":
+		return "error"
+	case "warning", "wrn":
+		return "warn"
+	case "information", "inf":
+		return "info"
+	case "dbg":
+		return "debug"
+	case "crit":
+		return "critical"
+	}
+	return l
+}
+
+// parseLogfmt parses key=value pairs. It returns nil unless the line has a
+// well-known time, level or message key, so ordinary text is not mistaken
+// for logfmt.
+func parseLogfmt(s string) map[string]any {
+	fields := make(map[string]any)
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' {
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			i++
+			continue
+		}
+		if i >= len(s) || s[i] != '=' {
+			fields[key] = true
+			continue
+		}
+		i++ // '='
+		if i < len(s) && s[i] == '"' {
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil
+			}
+			v, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil
+			}
+			fields[key] = v
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		fields[key] = s[start:i]
+	}
+	for _, keys := range [][]string{timeKeys, levelKeys, messageKeys} {
+		if _, k := lookup(fields, keys); k != "" {
+			return fields
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package memqueue provides an in-memory types.QueueInspector for tests and
+// local development, where no broker is running.
+package memqueue
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Inspector holds queue states set by the caller. It is safe for
+// concurrent use.
+type Inspector struct {
+	mu     sync.Mutex
+	queues map[string]types.QueueStatus
+	err    error
+}
+
+// New returns an Inspector holding queues.
+func New(queues ...types.QueueStatus) *Inspector {
+	in := &Inspector{queues: map[string]types.QueueStatus{}}
+	for _, q := range queues {
+		in.Set(q)
+	}
+	return in
+}
+
+// Set adds or replaces the queue named q.Name.
+func (in *Inspector) Set(q types.QueueStatus) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	q.ConsumerGroups = slices.Clone(q.ConsumerGroups)
+	in.queues[q.Name] = q
+}
+
+// Remove deletes a queue.
+func (in *Inspector) Remove(name string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	delete(in.queues, name)
+}
+
+// Enqueue adds n messages to a queue, creating it if needed. The oldest
+// message time is set when the queue was empty.
+func (in *Inspector) Enqueue(name string, n int64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	q := in.queues[name]
+	q.Name = name
+	if q.Depth == 0 {
+		q.OldestMessage = time.Now()
+	}
+	q.Depth += n
+	for i := range q.ConsumerGroups {
+		q.ConsumerGroups[i].Lag += n
+	}
+	in.queues[name] = q
+}
+
+// SetLag sets the lag and consumer count of a consumer group, adding the
+// group if needed. The queue's consumer count is the sum over its groups.
+func (in *Inspector) SetLag(queue, group string, consumers int, lag int64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	q := in.queues[queue]
+	q.Name = queue
+	i := slices.IndexFunc(q.ConsumerGroups, func(g types.ConsumerGroup) bool { return g.Name == group })
+	if i < 0 {
+		q.ConsumerGroups = append(q.ConsumerGroups, types.ConsumerGroup{Name: group})
+		i = len(q.ConsumerGroups) - 1
+	}
+	q.ConsumerGroups[i].Consumers = consumers
+	q.ConsumerGroups[i].Lag = lag
+	q.Consumers = 0
+	for _, g := range q.ConsumerGroups {
+		q.Consumers += g.Consumers
+	}
+	in.queues[queue] = q
+}
+
+// DeadLetter moves n messages of a queue to its dead letter queue.
+func (in *Inspector) DeadLetter(name string, n int64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	q := in.queues[name]
+	q.Name = name
+	n = min(n, q.Depth)
+	q.Depth -= n
+	q.DeadLetterDepth += n
+	if q.Depth == 0 {
+		q.OldestMessage = time.Time{}
+	}
+	in.queues[name] = q
+}
+
+// SetError makes Queues fail with err until it is cleared with nil.
+func (in *Inspector) SetError(err error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.err = err
+}
+
+// Queues implements types.QueueInspector, returning queues sorted by name.
+func (in *Inspector) Queues(context.Context) ([]types.QueueStatus, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.err != nil {
+		return nil, in.err
+	}
+	out := make([]types.QueueStatus, 0, len(in.queues))
+	for _, q := range in.queues {
+		q.ConsumerGroups = slices.Clone(q.ConsumerGroups)
+		out = append(out, q)
+	}
+	slices.SortFunc(out, func(a, b types.QueueStatus) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
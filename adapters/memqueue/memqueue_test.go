@@ -0,0 +1,50 @@
+package memqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+func TestInspector(t *testing.T) {
+	in := New(types.QueueStatus{Name: "payments", Depth: 3})
+	in.SetLag("orders", "billing", 2, 0)
+	in.SetLag("orders", "shipping", 1, 0)
+	in.Enqueue("orders", 10)
+	in.DeadLetter("orders", 4)
+
+	queues, err := in.Queues(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues) != 2 || queues[0].Name != "orders" || queues[1].Name != "payments" {
+		t.Fatalf("queues = %+v", queues)
+	}
+	orders := queues[0]
+	if orders.Depth != 6 || orders.DeadLetterDepth != 4 || orders.Consumers != 3 || orders.OldestMessage.IsZero() {
+		t.Errorf("orders = %+v", orders)
+	}
+	if len(orders.ConsumerGroups) != 2 || orders.ConsumerGroups[0].Lag != 10 {
+		t.Errorf("groups = %+v", orders.ConsumerGroups)
+	}
+
+	// Returned slices are copies.
+	orders.ConsumerGroups[0].Lag = 0
+	if again, _ := in.Queues(context.Background()); again[0].ConsumerGroups[0].Lag != 10 {
+		t.Error("caller modified the inspector state")
+	}
+
+	in.DeadLetter("orders", 100)
+	in.Remove("payments")
+	queues, _ = in.Queues(context.Background())
+	if len(queues) != 1 || queues[0].Depth != 0 || queues[0].DeadLetterDepth != 10 || !queues[0].OldestMessage.IsZero() {
+		t.Errorf("after dead-lettering everything: %+v", queues)
+	}
+
+	in.SetError(errors.New("broker down"))
+	if _, err := in.Queues(context.Background()); err == nil {
+		t.Error("SetError ignored")
+	}
+}
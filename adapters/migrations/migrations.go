@@ -0,0 +1,328 @@
+// Package migrations provides ready-made types.MigrationReader
+// implementations for golang-migrate, goose and Atlas. Each reader combines
+// the migration files in the repo with the tool's tracking table, read
+// through a types.DBConn.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/next-trace/scg-boost/internal/migfiles"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Default tracking tables of each tool.
+const (
+	DefaultGolangMigrateTable = "schema_migrations"
+	DefaultGooseTable         = "goose_db_version"
+	DefaultAtlasTable         = "atlas_schema_revisions.atlas_schema_revisions"
+)
+
+// Option configures a reader.
+type Option func(*reader)
+
+// WithTable overrides the tracking table (optionally schema-qualified).
+func WithTable(table string) Option { return func(r *reader) { r.table = table } }
+
+type reader struct {
+	db    types.DBConn
+	dir   string
+	table string
+	read  func(ctx context.Context, r *reader, files []migfiles.File) ([]types.MigrationStatus, error)
+}
+
+// NewGolangMigrate returns a MigrationReader for golang-migrate
+// (<version>_<name>.up.sql / .down.sql and the schema_migrations table).
+// golang-migrate only records the current version, so every file at or below
+// it is reported as applied; timestamps and checksums are not tracked.
+func NewGolangMigrate(db types.DBConn, dir string, opts ...Option) types.MigrationReader {
+	return newReader(db, dir, DefaultGolangMigrateTable, readGolangMigrate, opts)
+}
+
+// NewGoose returns a MigrationReader for goose (annotated .sql files and the
+// goose_db_version table).
+func NewGoose(db types.DBConn, dir string, opts ...Option) types.MigrationReader {
+	return newReader(db, dir, DefaultGooseTable, readGoose, opts)
+}
+
+// NewAtlas returns a MigrationReader for Atlas versioned migrations
+// (.sql files with atlas.sum and the atlas_schema_revisions table).
+func NewAtlas(db types.DBConn, dir string, opts ...Option) types.MigrationReader {
+	return newReader(db, dir, DefaultAtlasTable, readAtlas, opts)
+}
+
+func newReader(db types.DBConn, dir, table string, read func(context.Context, *reader, []migfiles.File) ([]types.MigrationStatus, error), opts []Option) *reader {
+	r := &reader{db: db, dir: dir, table: table, read: read}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(r)
+		}
+	}
+	return r
+}
+
+// Status implements types.MigrationReader.
+func (r *reader) Status(ctx context.Context) ([]types.MigrationStatus, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("migrations: nil db")
+	}
+	files, err := migfiles.Load(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.read(ctx, r, files)
+}
+
+func (r *reader) query(ctx context.Context, q string) ([]map[string]any, error) {
+	rows, err := r.db.QueryJSON(ctx, q, map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", r.table, err)
+	}
+	return rows, nil
+}
+
+func readGolangMigrate(ctx context.Context, r *reader, files []migfiles.File) ([]types.MigrationStatus, error) {
+	rows, err := r.query(ctx, "SELECT version, dirty FROM "+r.table)
+	if err != nil {
+		return nil, err
+	}
+
+	current := ""
+	dirty := false
+	if len(rows) > 0 {
+		current = asString(rows[0]["version"])
+		dirty = asBool(rows[0]["dirty"])
+	}
+
+	out := make([]types.MigrationStatus, 0, len(files)+1)
+	found := false
+	for _, f := range files {
+		st := fileStatus(f)
+		if current != "" && migfiles.CompareVersions(f.Version, current) <= 0 {
+			st.Applied = true
+		}
+		if trimZeros(f.Version) == trimZeros(current) {
+			found = true
+			st.Dirty = dirty
+		}
+		out = append(out, st)
+	}
+	if current != "" && !found {
+		out = append(out, types.MigrationStatus{Name: current, Version: current, Applied: true, Dirty: dirty, Missing: true})
+	}
+	return out, nil
+}
+
+func readGoose(ctx context.Context, r *reader, files []migfiles.File) ([]types.MigrationStatus, error) {
+	rows, err := r.query(ctx, "SELECT version_id, is_applied, tstamp FROM "+r.table+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest row per version wins; is_applied=false records a rollback.
+	type record struct {
+		applied bool
+		at      *time.Time
+	}
+	records := make(map[string]record)
+	var order []string
+	for _, row := range rows {
+		v := trimZeros(asString(row["version_id"]))
+		if v == "" || v == "0" {
+			continue
+		}
+		if _, ok := records[v]; !ok {
+			order = append(order, v)
+		}
+		records[v] = record{applied: asBool(row["is_applied"]), at: asTime(row["tstamp"])}
+	}
+
+	return merge(files, order, func(v string) (bool, *time.Time, string, bool) {
+		rec, ok := records[v]
+		return rec.applied, rec.at, "", ok && rec.applied
+	}), nil
+}
+
+func readAtlas(ctx context.Context, r *reader, files []migfiles.File) ([]types.MigrationStatus, error) {
+	rows, err := r.query(ctx, "SELECT version, applied, total, executed_at, error, hash FROM "+r.table+" ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := migfiles.AtlasHashes(r.dir, files)
+	if err != nil {
+		return nil, err
+	}
+
+	type record struct {
+		at    *time.Time
+		hash  string
+		dirty bool
+	}
+	records := make(map[string]record)
+	var order []string
+	for _, row := range rows {
+		v := trimZeros(asString(row["version"]))
+		if v == "" {
+			continue
+		}
+		order = append(order, v)
+		records[v] = record{
+			at:    asTime(row["executed_at"]),
+			hash:  asString(row["hash"]),
+			dirty: asString(row["error"]) != "" || asInt(row["applied"]) < asInt(row["total"]),
+		}
+	}
+
+	out := merge(files, order, func(v string) (bool, *time.Time, string, bool) {
+		rec, ok := records[v]
+		return ok, rec.at, rec.hash, ok
+	})
+	for i := range out {
+		st := &out[i]
+		rec, ok := records[trimZeros(st.Version)]
+		if !ok {
+			continue
+		}
+		st.Dirty = rec.dirty
+		if st.Missing {
+			continue
+		}
+		st.Checksum = hashes[fileByVersion(files, st.Version).Path]
+		st.ChecksumMismatch = st.AppliedChecksum != "" && st.Checksum != "" && st.AppliedChecksum != st.Checksum
+	}
+	return out, nil
+}
+
+// merge combines migration files with tracking records. lookup reports,
+// for a normalized version, whether it is applied, when, the recorded
+// checksum, and whether it is a known record at all.
+func merge(files []migfiles.File, recorded []string, lookup func(v string) (applied bool, at *time.Time, sum string, known bool)) []types.MigrationStatus {
+	latestApplied := ""
+	for _, v := range recorded {
+		if applied, _, _, _ := lookup(v); applied && (latestApplied == "" || migfiles.CompareVersions(v, latestApplied) > 0) {
+			latestApplied = v
+		}
+	}
+
+	onDisk := make(map[string]bool, len(files))
+	out := make([]types.MigrationStatus, 0, len(files))
+	for _, f := range files {
+		v := trimZeros(f.Version)
+		onDisk[v] = true
+		st := fileStatus(f)
+		applied, at, sum, _ := lookup(v)
+		st.Applied = applied
+		st.AppliedAt = at
+		st.AppliedChecksum = sum
+		if !applied && latestApplied != "" && migfiles.CompareVersions(v, latestApplied) < 0 {
+			st.OutOfOrder = true
+		}
+		out = append(out, st)
+	}
+	for _, v := range recorded {
+		if onDisk[v] {
+			continue
+		}
+		if applied, at, sum, known := lookup(v); known && applied {
+			out = append(out, types.MigrationStatus{Name: v, Version: v, Applied: true, AppliedAt: at, AppliedChecksum: sum, Missing: true})
+		}
+	}
+	return out
+}
+
+func fileStatus(f migfiles.File) types.MigrationStatus {
+	name := f.Version
+	if f.Name != "" {
+		name += "_" + f.Name
+	}
+	return types.MigrationStatus{Name: name, Version: f.Version, Checksum: f.Checksum}
+}
+
+func fileByVersion(files []migfiles.File, version string) migfiles.File {
+	for _, f := range files {
+		if f.Version == version {
+			return f
+		}
+	}
+	return migfiles.File{}
+}
+
+func trimZeros(v string) string {
+	t := ""
+	for i := 0; i < len(v); i++ {
+		if v[i] != '0' {
+			t = v[i:]
+			break
+		}
+	}
+	if t == "" && v != "" {
+		return "0"
+	}
+	return t
+}
+
+func asString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func asBool(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case string:
+		b, _ := strconv.ParseBool(x)
+		return b
+	case []byte:
+		b, _ := strconv.ParseBool(string(x))
+		return b
+	case int64:
+		return x != 0
+	default:
+		return false
+	}
+}
+
+func asInt(v any) int64 {
+	switch x := v.(type) {
+	case int64:
+		return x
+	case int:
+		return int64(x)
+	case float64:
+		return int64(x)
+	default:
+		n, _ := strconv.ParseInt(asString(v), 10, 64)
+		return n
+	}
+}
+
+func asTime(v any) *time.Time {
+	switch x := v.(type) {
+	case time.Time:
+		t := x.UTC()
+		return &t
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, x); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
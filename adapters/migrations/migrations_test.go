@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-boost/internal/migfiles"
+)
+
+// fakeDB returns canned rows for the tracking table query.
+type fakeDB struct {
+	rows  []map[string]any
+	query string
+}
+
+func (f *fakeDB) QueryJSON(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	f.query = query
+	return f.rows, nil
+}
+func (f *fakeDB) Schemas(context.Context) ([]string, error)        { return nil, nil }
+func (f *fakeDB) Tables(context.Context, string) ([]string, error) { return nil, nil }
+func (f *fakeDB) Columns(context.Context, string, string) ([]map[string]any, error) {
+	return nil, nil
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGolangMigrate(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"1_init.up.sql":    "CREATE TABLE a (id int);",
+		"1_init.down.sql":  "DROP TABLE a;",
+		"2_more.up.sql":    "CREATE TABLE b (id int);",
+		"3_pending.up.sql": "CREATE TABLE c (id int);",
+	})
+	db := &fakeDB{rows: []map[string]any{{"version": int64(2), "dirty": true}}}
+
+	got, err := NewGolangMigrate(db, dir).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !strings.Contains(db.query, "schema_migrations") {
+		t.Errorf("query = %q, want schema_migrations", db.query)
+	}
+	if len(got) != 3 {
+		t.Fatalf("len = %d, want 3", len(got))
+	}
+	if !got[0].Applied || !got[1].Applied || got[2].Applied {
+		t.Errorf("applied = %v %v %v, want true true false", got[0].Applied, got[1].Applied, got[2].Applied)
+	}
+	if !got[1].Dirty || got[0].Dirty {
+		t.Errorf("dirty flags = %v %v, want only version 2 dirty", got[0].Dirty, got[1].Dirty)
+	}
+	if got[0].Name != "1_init" || got[0].Checksum == "" {
+		t.Errorf("status[0] = %+v", got[0])
+	}
+}
+
+func TestGoose(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"00001_a.sql": "-- +goose Up\nCREATE TABLE a (id int);\n",
+		"00002_b.sql": "-- +goose Up\nCREATE TABLE b (id int);\n",
+		"00003_c.sql": "-- +goose Up\nCREATE TABLE c (id int);\n",
+	})
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{rows: []map[string]any{
+		{"version_id": int64(0), "is_applied": true, "tstamp": ts},
+		{"version_id": int64(1), "is_applied": true, "tstamp": ts},
+		{"version_id": int64(3), "is_applied": true, "tstamp": ts},
+		{"version_id": int64(4), "is_applied": true, "tstamp": ts},
+	}}
+
+	got, err := NewGoose(db, dir).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("len = %d, want 4: %+v", len(got), got)
+	}
+	if !got[0].Applied || got[0].AppliedAt == nil || !got[0].AppliedAt.Equal(ts) {
+		t.Errorf("status[0] = %+v", got[0])
+	}
+	if got[1].Applied || !got[1].OutOfOrder {
+		t.Errorf("status[1] = %+v, want pending and out of order", got[1])
+	}
+	if !got[3].Missing || got[3].Version != "4" {
+		t.Errorf("status[3] = %+v, want missing version 4", got[3])
+	}
+}
+
+func TestAtlas(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"20240101000000_a.sql": "CREATE TABLE a (id int);",
+		"20240102000000_b.sql": "CREATE TABLE b (id int);",
+		"atlas.sum":            "h1:total\n20240101000000_a.sql h1:AAA=\n20240102000000_b.sql h1:BBB=\n",
+	})
+	db := &fakeDB{rows: []map[string]any{
+		{"version": "20240101000000", "applied": int64(1), "total": int64(1), "error": "", "hash": "AAA="},
+		{"version": "20240102000000", "applied": int64(0), "total": int64(2), "error": "boom", "hash": "XXX="},
+	}}
+
+	got, err := NewAtlas(db, dir, WithTable("public.atlas_schema_revisions")).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !strings.Contains(db.query, "public.atlas_schema_revisions") {
+		t.Errorf("query = %q, want custom table", db.query)
+	}
+	if got[0].ChecksumMismatch || got[0].Dirty {
+		t.Errorf("status[0] = %+v, want clean", got[0])
+	}
+	if !got[1].ChecksumMismatch || !got[1].Dirty {
+		t.Errorf("status[1] = %+v, want dirty checksum mismatch", got[1])
+	}
+}
+
+func TestAtlasHashesComputed(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{"1_a.sql": "SELECT 1;"})
+	files, err := migfiles.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes, err := migfiles.AtlasHashes(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes["1_a.sql"] == "" {
+		t.Errorf("expected computed hash, got %v", hashes)
+	}
+}
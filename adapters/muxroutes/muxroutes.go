@@ -0,0 +1,98 @@
+// Package muxroutes provides an http.ServeMux wrapper that records routes
+// as they are registered, so it can serve as a types.RouteProvider.
+package muxroutes
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/next-trace/scg-boost/internal/funcname"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Middleware wraps a handler.
+type Middleware = func(http.Handler) http.Handler
+
+// Mux is an http.ServeMux that remembers its routes. Register routes with
+// its Handle and HandleFunc; routes registered on the underlying ServeMux
+// directly are not listed.
+type Mux struct {
+	*http.ServeMux
+
+	mu          sync.Mutex
+	middlewares []Middleware
+	routes      []types.RouteInfo
+}
+
+// New wraps mux, or a new ServeMux when mux is nil.
+func New(mux *http.ServeMux) *Mux {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	return &Mux{ServeMux: mux}
+}
+
+// Use adds middlewares applied to routes registered afterwards, outermost
+// first.
+func (m *Mux) Use(middlewares ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middlewares = append(m.middlewares, middlewares...)
+}
+
+// Handle registers h for pattern, wrapped in the Use middlewares and then
+// middlewares. Patterns use ServeMux syntax, such as "GET /orders/{id}".
+func (m *Mux) Handle(pattern string, h http.Handler, middlewares ...Middleware) {
+	m.handle(pattern, h, funcname.Of(h), middlewares)
+}
+
+// HandleFunc registers f for pattern like Handle.
+func (m *Mux) HandleFunc(pattern string, f func(http.ResponseWriter, *http.Request), middlewares ...Middleware) {
+	m.handle(pattern, http.HandlerFunc(f), funcname.Of(f), middlewares)
+}
+
+func (m *Mux) handle(pattern string, h http.Handler, name string, middlewares []Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chain := append(slices.Clone(m.middlewares), middlewares...)
+	wrapped := h
+	for i := len(chain) - 1; i >= 0; i-- {
+		wrapped = chain[i](wrapped)
+	}
+	// ServeMux panics on invalid or conflicting patterns; record the route
+	// only once it is registered.
+	m.ServeMux.Handle(pattern, wrapped)
+
+	method, path := "*", strings.TrimSpace(pattern)
+	if before, after, ok := strings.Cut(path, " "); ok {
+		method, path = before, strings.TrimSpace(after)
+	}
+	m.routes = append(m.routes, types.RouteInfo{
+		Method:      method,
+		Path:        path,
+		Handler:     name,
+		Middlewares: funcname.All(chain...),
+	})
+}
+
+// List implements types.RouteProvider. Patterns without a method are
+// listed with method "*".
+func (m *Mux) List(context.Context) ([]types.RouteInfo, error) {
+	m.mu.Lock()
+	routes := slices.Clone(m.routes)
+	m.mu.Unlock()
+	if routes == nil {
+		routes = []types.RouteInfo{}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
@@ -0,0 +1,58 @@
+package muxroutes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+const pkg = "github.com/next-trace/scg-boost/adapters/muxroutes."
+
+type orders struct{}
+
+func (orders) get(w http.ResponseWriter, r *http.Request) { w.Header().Add("X-Handler", "get") }
+
+func tag(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Chain", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func logging(next http.Handler) http.Handler { return tag("logging")(next) }
+func auth(next http.Handler) http.Handler    { return tag("auth")(next) }
+
+func TestMux(t *testing.T) {
+	var o orders
+	m := New(nil)
+	m.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	m.Use(logging)
+	m.HandleFunc("GET /orders/{id}", o.get, auth)
+	m.Handle("/static/", http.FileServer(http.Dir(".")))
+
+	routes, err := m.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.RouteInfo{
+		{Method: "*", Path: "/healthz", Handler: pkg + "TestMux.func1"},
+		{Method: "GET", Path: "/orders/{id}", Handler: pkg + "orders.get", Middlewares: []string{pkg + "logging", pkg + "auth"}},
+		{Method: "*", Path: "/static/", Handler: "*http.fileHandler", Middlewares: []string{pkg + "logging"}},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes =\n%+v\nwant\n%+v", routes, want)
+	}
+
+	// Middlewares run outermost first.
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	if got := rec.Header().Values("X-Chain"); !reflect.DeepEqual(got, []string{"logging", "auth"}) || rec.Header().Get("X-Handler") != "get" {
+		t.Errorf("chain = %v, handler = %q", got, rec.Header().Get("X-Handler"))
+	}
+}
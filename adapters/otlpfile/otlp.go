@@ -0,0 +1,206 @@
+package otlpfile
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// OTLP/JSON shapes (opentelemetry-proto, as written by the collector's file
+// exporter). Only the fields the trace tools use are decoded.
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+	// Legacy name used before OTLP 0.15.
+	InstrumentationLibrarySpans []scopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	Kind              json.RawMessage `json:"kind"`
+	StartTimeUnixNano json.Number     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   json.Number     `json:"endTimeUnixNano"`
+	Attributes        []keyValue      `json:"attributes"`
+	Events            []otlpEvent     `json:"events"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano json.Number `json:"timeUnixNano"`
+	Name         string      `json:"name"`
+	Attributes   []keyValue  `json:"attributes"`
+}
+
+type otlpStatus struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string         `json:"stringValue"`
+	BoolValue   *bool           `json:"boolValue"`
+	IntValue    json.RawMessage `json:"intValue"`
+	DoubleValue *float64        `json:"doubleValue"`
+	BytesValue  *string         `json:"bytesValue"`
+	ArrayValue  *struct {
+		Values []anyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []keyValue `json:"values"`
+	} `json:"kvlistValue"`
+}
+
+func (v anyValue) value() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case len(v.IntValue) > 0:
+		// int64 values are JSON strings in OTLP/JSON; accept numbers too.
+		s := strings.Trim(string(v.IntValue), `"`)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		return s
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		out := make([]any, len(v.ArrayValue.Values))
+		for i, e := range v.ArrayValue.Values {
+			out[i] = e.value()
+		}
+		return out
+	case v.KvlistValue != nil:
+		return attributes(v.KvlistValue.Values)
+	}
+	return nil
+}
+
+func attributes(kvs []keyValue) map[string]any {
+	if len(kvs) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		out[kv.Key] = kv.Value.value()
+	}
+	return out
+}
+
+const (
+	traceIDBytes = 16
+	spanIDBytes  = 8
+)
+
+var (
+	spanKinds   = []string{"unspecified", "internal", "server", "client", "producer", "consumer"}
+	statusCodes = []string{types.SpanStatusUnset, types.SpanStatusOK, types.SpanStatusError}
+)
+
+// enumName decodes an OTLP enum written as a number or as its proto name
+// (e.g. "SPAN_KIND_SERVER") into a lower-case name without prefix.
+func enumName(raw json.RawMessage, prefix string, names []string) string {
+	s := strings.Trim(string(raw), `"`)
+	if s == "" {
+		return ""
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n >= 0 && n < len(names) {
+			return names[n]
+		}
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(s, prefix))
+}
+
+// normalizeID returns lower-case hex. OTLP/JSON mandates hex, but some
+// protojson encoders emit base64.
+func normalizeID(id string, size int) string {
+	if id == "" {
+		return ""
+	}
+	if len(id) == size*2 {
+		if _, err := hex.DecodeString(id); err == nil {
+			return strings.ToLower(id)
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil && len(b) == size {
+		return hex.EncodeToString(b)
+	}
+	return strings.ToLower(id)
+}
+
+func unixNano(n json.Number) time.Time {
+	v, err := strconv.ParseInt(n.String(), 10, 64)
+	if err != nil || v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v).UTC()
+}
+
+// spans converts a decoded export request into typed spans.
+func (r exportRequest) spans() []types.Span {
+	var out []types.Span
+	for _, rs := range r.ResourceSpans {
+		service := ""
+		if v, ok := attributes(rs.Resource.Attributes)["service.name"].(string); ok {
+			service = v
+		}
+		for _, ss := range append(rs.ScopeSpans, rs.InstrumentationLibrarySpans...) {
+			for _, sp := range ss.Spans {
+				start, end := unixNano(sp.StartTimeUnixNano), unixNano(sp.EndTimeUnixNano)
+				span := types.Span{
+					TraceID:       normalizeID(sp.TraceID, traceIDBytes),
+					SpanID:        normalizeID(sp.SpanID, spanIDBytes),
+					ParentSpanID:  normalizeID(sp.ParentSpanID, spanIDBytes),
+					Service:       service,
+					Name:          sp.Name,
+					Kind:          enumName(sp.Kind, "SPAN_KIND_", spanKinds),
+					Start:         start,
+					Duration:      max(end.Sub(start), 0),
+					Status:        enumName(sp.Status.Code, "STATUS_CODE_", statusCodes),
+					StatusMessage: sp.Status.Message,
+					Attributes:    attributes(sp.Attributes),
+				}
+				if span.Status == "" {
+					span.Status = types.SpanStatusUnset
+				}
+				for _, e := range sp.Events {
+					span.Events = append(span.Events, types.SpanEvent{Name: e.Name, Time: unixNano(e.TimeUnixNano), Attributes: attributes(e.Attributes)})
+				}
+				if span.TraceID != "" && span.SpanID != "" {
+					out = append(out, span)
+				}
+			}
+		}
+	}
+	return out
+}
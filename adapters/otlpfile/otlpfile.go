@@ -0,0 +1,306 @@
+// Package otlpfile provides a built-in types.TraceReader and
+// types.TraceQuerier over OTLP/JSON trace files, as written by the
+// OpenTelemetry Collector file exporter (one ExportTraceServiceRequest per
+// line). Files in a directory are ingested incrementally into an in-memory
+// index bounded by a memory budget, so local services can hand traces to
+// agents without a tracing backend.
+package otlpfile
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultMemoryBudget bounds the estimated size of indexed spans.
+const DefaultMemoryBudget = 64 << 20
+
+// DefaultQuiescence is how long a file must go unmodified before a final
+// line without a trailing newline is ingested.
+const DefaultQuiescence = 2 * time.Second
+
+// Option configures a Reader.
+type Option func(*Reader)
+
+// WithMemoryBudget sets the approximate number of bytes of spans kept in
+// memory. The least recently updated traces are evicted first.
+func WithMemoryBudget(bytes int64) Option { return func(r *Reader) { r.budget = bytes } }
+
+// WithQuiescence sets how long a file must go unmodified before its final
+// line is ingested without a trailing newline; until then a writer may still
+// be appending to it.
+func WithQuiescence(d time.Duration) Option { return func(r *Reader) { r.quiescence = d } }
+
+// Reader indexes OTLP/JSON files (*.json, *.jsonl) in a directory.
+type Reader struct {
+	dir        string
+	budget     int64
+	quiescence time.Duration
+
+	mu      sync.Mutex
+	offsets map[string]int64 // bytes of each file already ingested
+	traces  map[string]*entry
+	lru     *list.List // of *entry, least recently updated first
+	size    int64
+}
+
+type entry struct {
+	trace types.Trace
+	size  int64
+	elem  *list.Element
+}
+
+// New returns a Reader for the OTLP/JSON files in dir.
+func New(dir string, opts ...Option) *Reader {
+	r := &Reader{
+		dir:        dir,
+		budget:     DefaultMemoryBudget,
+		quiescence: DefaultQuiescence,
+		offsets:    make(map[string]int64),
+		traces:     make(map[string]*entry),
+		lru:        list.New(),
+	}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(r)
+		}
+	}
+	return r
+}
+
+// Lookup implements types.TraceReader, returning summaries of the lastN most
+// recent traces.
+func (r *Reader) Lookup(ctx context.Context, lastN int) ([]map[string]any, error) {
+	sums, err := r.SearchTraces(ctx, types.TraceQuery{Limit: lastN})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, len(sums))
+	for i, s := range sums {
+		out[i] = map[string]any{
+			"trace_id":     s.TraceID,
+			"root_service": s.RootService,
+			"root_name":    s.RootName,
+			"start":        s.Start.Format(time.RFC3339Nano),
+			"duration_ms":  float64(s.Duration) / float64(time.Millisecond),
+			"span_count":   s.SpanCount,
+			"error_count":  s.ErrorCount,
+		}
+	}
+	return out, nil
+}
+
+// GetTrace implements types.TraceQuerier.
+func (r *Reader) GetTrace(ctx context.Context, traceID string) (*types.Trace, error) {
+	if err := r.refresh(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.traces[strings.ToLower(traceID)]
+	if !ok {
+		return nil, nil
+	}
+	t := types.Trace{TraceID: e.trace.TraceID, Spans: append([]types.Span(nil), e.trace.Spans...)}
+	return &t, nil
+}
+
+// SearchTraces implements types.TraceQuerier. Traces are ordered by start
+// time, most recent first.
+func (r *Reader) SearchTraces(ctx context.Context, q types.TraceQuery) ([]types.TraceSummary, error) {
+	if err := r.refresh(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	var out []types.TraceSummary
+	for _, e := range r.traces {
+		if q.Match(e.trace) {
+			out = append(out, e.trace.Summary())
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.After(out[j].Start) })
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+// refresh ingests data appended to the directory's files since the last call.
+// Files that shrank (truncated or replaced) are read again from the start.
+func (r *Reader) refresh(ctx context.Context) error {
+	var files []string
+	for _, pattern := range []string{"*.json", "*.jsonl"} {
+		m, err := filepath.Glob(filepath.Join(r.dir, pattern))
+		if err != nil {
+			return err
+		}
+		files = append(files, m...)
+	}
+	sort.Strings(files)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.ingestFile(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ingestFile streams the unread part of path line by line. Lines larger
+// than the memory budget are skipped without being buffered.
+func (r *Reader) ingestFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	off := r.offsets[path]
+	if st.Size() < off {
+		off = 0
+	}
+	if st.Size() == off {
+		return nil
+	}
+	defer func() { r.offsets[path] = off }()
+
+	br := bufio.NewReader(io.NewSectionReader(f, off, st.Size()-off))
+	for {
+		line, n, err := readLine(br, r.budget)
+		if errors.Is(err, io.EOF) {
+			// A final line without a newline may be mid-write; take it only
+			// once the file has stopped changing.
+			if n > 0 && time.Since(st.ModTime()) >= r.quiescence {
+				r.ingestLine(line)
+				off += n
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.ingestLine(line)
+		off += n
+	}
+}
+
+// readLine reads up to and including the next newline and reports the bytes
+// consumed. A line longer than max is consumed but returned as nil. At EOF the
+// unterminated remainder is returned with io.EOF.
+func readLine(br *bufio.Reader, max int64) ([]byte, int64, error) {
+	var line []byte
+	var n int64
+	for {
+		chunk, err := br.ReadSlice('\n')
+		n += int64(len(chunk))
+		if n <= max {
+			line = append(line, chunk...)
+		} else {
+			line = nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, n, err
+		}
+	}
+}
+
+// ingestLine indexes the spans of one export request. Malformed lines are
+// skipped.
+func (r *Reader) ingestLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var req exportRequest
+	if err := dec.Decode(&req); err != nil {
+		return
+	}
+	for _, sp := range req.spans() {
+		r.add(sp)
+	}
+}
+
+// add indexes sp, replacing an earlier copy of the same span, and evicts
+// traces beyond the memory budget.
+func (r *Reader) add(sp types.Span) {
+	e, ok := r.traces[sp.TraceID]
+	if !ok {
+		e = &entry{trace: types.Trace{TraceID: sp.TraceID}}
+		e.elem = r.lru.PushBack(e)
+		r.traces[sp.TraceID] = e
+	} else {
+		r.lru.MoveToBack(e.elem)
+	}
+
+	size := spanSize(sp)
+	replaced := false
+	for i := range e.trace.Spans {
+		if e.trace.Spans[i].SpanID == sp.SpanID {
+			size -= spanSize(e.trace.Spans[i])
+			e.trace.Spans[i] = sp
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		e.trace.Spans = append(e.trace.Spans, sp)
+	}
+	e.size += size
+	r.size += size
+
+	for r.size > r.budget && r.lru.Len() > 1 {
+		oldest := r.lru.Remove(r.lru.Front()).(*entry)
+		delete(r.traces, oldest.trace.TraceID)
+		r.size -= oldest.size
+	}
+}
+
+// spanSize estimates the memory held by sp.
+func spanSize(sp types.Span) int64 {
+	n := int64(256 + len(sp.TraceID) + len(sp.SpanID) + len(sp.ParentSpanID) + len(sp.Service) + len(sp.Name) + len(sp.StatusMessage))
+	n += attrSize(sp.Attributes)
+	for _, e := range sp.Events {
+		n += int64(64+len(e.Name)) + attrSize(e.Attributes)
+	}
+	return n
+}
+
+func attrSize(attrs map[string]any) int64 {
+	var n int64
+	for k, v := range attrs {
+		n += int64(32 + len(k))
+		switch x := v.(type) {
+		case string:
+			n += int64(len(x))
+		case map[string]any:
+			n += attrSize(x)
+		case []any:
+			n += int64(16 * len(x))
+		}
+	}
+	return n
+}
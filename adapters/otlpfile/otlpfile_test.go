@@ -0,0 +1,160 @@
+package otlpfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+const t0 = int64(1714557600000000000) // 2024-05-01T10:00:00Z
+
+// exportLine renders one collector file-exporter line for a service.
+func exportLine(service string, spans ...string) string {
+	return fmt.Sprintf(`{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":%q}}]},"scopeSpans":[{"scope":{"name":"test"},"spans":[%s]}]}]}`,
+		service, strings.Join(spans, ","))
+}
+
+func span(traceID, spanID, parent, name string, startMs, durMs int64, extra string) string {
+	start := t0 + startMs*int64(time.Millisecond)
+	end := start + durMs*int64(time.Millisecond)
+	s := fmt.Sprintf(`{"traceId":%q,"spanId":%q,"parentSpanId":%q,"name":%q,"kind":2,"startTimeUnixNano":"%d","endTimeUnixNano":"%d"`, traceID, spanID, parent, name, start, end)
+	if extra != "" {
+		s += "," + extra
+	}
+	return s + "}"
+}
+
+const (
+	traceA = "5b8efff798038103d269b633813fc60c"
+	traceB = "0af7651916cd43dd8448eb211c80319c"
+)
+
+func TestReader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	lines := []string{
+		exportLine("api",
+			span(traceA, "eee19b7ec3c1b174", "", "GET /orders", 0, 120, `"status":{"code":1}`),
+		),
+		exportLine("orders",
+			span(traceA, "eee19b7ec3c1b175", "eee19b7ec3c1b174", "SELECT orders", 10, 100,
+				`"kind":"SPAN_KIND_CLIENT","status":{"code":"STATUS_CODE_ERROR","message":"timeout"},`+
+					`"attributes":[{"key":"db.rows","value":{"intValue":"42"}},{"key":"db.system","value":{"stringValue":"postgresql"}}],`+
+					`"events":[{"timeUnixNano":"1714557600110000000","name":"exception","attributes":[{"key":"exception.type","value":{"stringValue":"pq.Error"}}]}]`),
+		),
+		`not json`,
+		exportLine("api", span(traceB, "aaa19b7ec3c1b174", "", "GET /health", 1000, 2, "")),
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(dir)
+	ctx := context.Background()
+
+	tr, err := r.GetTrace(ctx, strings.ToUpper(traceA))
+	if err != nil || tr == nil || len(tr.Spans) != 2 {
+		t.Fatalf("GetTrace() = %+v, %v", tr, err)
+	}
+	child := tr.Spans[1]
+	if child.Service != "orders" || child.Kind != "client" || child.Status != types.SpanStatusError || child.StatusMessage != "timeout" ||
+		child.Duration != 100*time.Millisecond || child.Attributes["db.rows"] != int64(42) || child.Events[0].Attributes["exception.type"] != "pq.Error" {
+		t.Errorf("child span = %+v", child)
+	}
+	if tr.Spans[0].Kind != "server" || tr.Spans[0].Status != types.SpanStatusOK {
+		t.Errorf("root span = %+v", tr.Spans[0])
+	}
+
+	sums, err := r.SearchTraces(ctx, types.TraceQuery{})
+	if err != nil || len(sums) != 2 || sums[0].TraceID != traceB {
+		t.Fatalf("SearchTraces() = %+v, %v, want most recent first", sums, err)
+	}
+	if sums, _ := r.SearchTraces(ctx, types.TraceQuery{ErrorsOnly: true}); len(sums) != 1 || sums[0].TraceID != traceA {
+		t.Errorf("errors only = %+v", sums)
+	}
+
+	// Appended data, including a partial line, is picked up incrementally.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	late := exportLine("orders", span(traceB, "aaa19b7ec3c1b175", "aaa19b7ec3c1b174", "ping", 1000, 1, ""))
+	if _, err := f.WriteString(late[:20]); err != nil {
+		t.Fatal(err)
+	}
+	if tr, _ := r.GetTrace(ctx, traceB); len(tr.Spans) != 1 {
+		t.Errorf("partial line ingested: %d spans", len(tr.Spans))
+	}
+	if _, err := f.WriteString(late[20:] + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	if tr, _ := r.GetTrace(ctx, traceB); len(tr.Spans) != 2 {
+		t.Errorf("appended span missing: %d spans", len(tr.Spans))
+	}
+
+	got, err := r.Lookup(ctx, 1)
+	if err != nil || len(got) != 1 || got[0]["trace_id"] != traceB {
+		t.Errorf("Lookup(1) = %v, %v", got, err)
+	}
+}
+
+func TestReader_MemoryBudget(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("%032x", i+1)
+		lines = append(lines, exportLine("api", span(id, fmt.Sprintf("%016x", i+1), "", "op", int64(i), 1, "")))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "t.json"), []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(dir, WithMemoryBudget(10*400))
+	sums, err := r.SearchTraces(context.Background(), types.TraceQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) == 0 || len(sums) >= 50 || r.size > r.budget {
+		t.Fatalf("kept %d traces (%d bytes), want eviction under the %d byte budget", len(sums), r.size, r.budget)
+	}
+	if want := fmt.Sprintf("%032x", 50); sums[0].TraceID != want {
+		t.Errorf("newest trace %s evicted", want)
+	}
+}
+
+func TestReader_FinalLineAndOversizedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	huge := exportLine("api", span(traceA, "eee19b7ec3c1b174", "", "GET /orders", 0, 1, `"attributes":[{"key":"body","value":{"stringValue":"`+strings.Repeat("x", 8000)+`"}}]`))
+	last := exportLine("api", span(traceB, "aaa19b7ec3c1b174", "", "GET /health", 1000, 2, ""))
+	if err := os.WriteFile(path, []byte(huge+"\n"+last), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(dir, WithMemoryBudget(4000))
+	ctx := context.Background()
+	if tr, _ := r.GetTrace(ctx, traceB); tr != nil {
+		t.Errorf("final line ingested while the file is still being written")
+	}
+
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if tr, _ := r.GetTrace(ctx, traceB); tr == nil || len(tr.Spans) != 1 {
+		t.Errorf("final line without newline not ingested once quiescent: %+v", tr)
+	}
+	if tr, _ := r.GetTrace(ctx, traceA); tr != nil {
+		t.Errorf("line over the memory budget ingested")
+	}
+	if st, _ := os.Stat(path); r.offsets[path] != st.Size() {
+		t.Errorf("offset = %d, want %d", r.offsets[path], st.Size())
+	}
+}
@@ -0,0 +1,21 @@
+// Package postgres provides the built-in read-only Postgres types.DBConn. It
+// implements the optional IndexLister, ForeignKeyLister and
+// SessionVarsApplier extensions, so schema snapshots, ER diagrams and
+// row-level security session variables work out of the box.
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/next-trace/scg-boost/internal/runtime"
+)
+
+// DB is a read-only Postgres connection.
+type DB = runtime.ReadOnlyDB
+
+// New wraps an existing sqlx connection pool opened with the "postgres" driver.
+func New(db *sqlx.DB) *DB { return &runtime.ReadOnlyDB{DB: db} }
+
+// Open connects to dsn and verifies the connection.
+func Open(ctx context.Context, dsn string) (*DB, error) { return runtime.OpenPostgres(ctx, dsn) }
@@ -0,0 +1,198 @@
+package promtext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// familySuffixes are the sample name suffixes that belong to a family.
+var familySuffixes = []string{"_bucket", "_sum", "_count", "_total", "_created", "_gcount", "_gsum", "_info"}
+
+// Parse reads Prometheus text (0.0.4) or OpenMetrics exposition and returns
+// its metric families in order of appearance. Samples without a # TYPE line
+// become untyped families of their own.
+func Parse(r io.Reader) ([]types.MetricFamily, error) {
+	var (
+		families []types.MetricFamily
+		index    = map[string]int{}
+	)
+	family := func(name string) *types.MetricFamily {
+		if i, ok := index[name]; ok {
+			return &families[i]
+		}
+		index[name] = len(families)
+		families = append(families, types.MetricFamily{Name: name, Type: types.MetricUntyped})
+		return &families[len(families)-1]
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(strings.TrimSpace(line[1:]), " ", 3)
+			switch {
+			case fields[0] == "EOF":
+				return families, nil
+			case len(fields) < 3:
+				continue
+			case fields[0] == "HELP":
+				family(fields[1]).Help = unescape(fields[2], false)
+			case fields[0] == "TYPE":
+				family(fields[1]).Type = strings.ToLower(strings.TrimSpace(fields[2]))
+			}
+			continue
+		}
+
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		f := family(familyName(s.Name, index))
+		f.Samples = append(f.Samples, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return families, nil
+}
+
+// familyName returns the declared family a sample belongs to, or the
+// sample name itself.
+func familyName(sample string, index map[string]int) string {
+	if _, ok := index[sample]; ok {
+		return sample
+	}
+	for _, suffix := range familySuffixes {
+		if base, ok := strings.CutSuffix(sample, suffix); ok {
+			if _, ok := index[base]; ok {
+				return base
+			}
+		}
+	}
+	return sample
+}
+
+// parseSample parses `name{label="v",...} value [timestamp] [# exemplar]`.
+func parseSample(line string) (types.MetricSample, error) {
+	var s types.MetricSample
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return s, fmt.Errorf("malformed sample %q", line)
+	}
+	s.Name, line = line[:end], line[end:]
+	if !validName(s.Name) {
+		return s, fmt.Errorf("invalid metric name %q", s.Name)
+	}
+
+	if strings.HasPrefix(line, "{") {
+		labels, rest, err := parseLabels(line[1:])
+		if err != nil {
+			return s, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		s.Labels, line = labels, rest
+	}
+
+	if i := strings.Index(line, " # "); i >= 0 {
+		line = line[:i] // OpenMetrics exemplar
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 || len(fields) > 2 {
+		return s, fmt.Errorf("%s: expected a value and optional timestamp", s.Name)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, fmt.Errorf("%s: invalid value %q", s.Name, fields[0])
+	}
+	s.Value = v
+	return s, nil
+}
+
+// parseLabels parses label pairs after the opening brace and returns the
+// text after the closing brace.
+func parseLabels(in string) (map[string]string, string, error) {
+	labels := map[string]string{}
+	for {
+		in = strings.TrimLeft(in, " \t")
+		if strings.HasPrefix(in, "}") {
+			return labels, in[1:], nil
+		}
+		eq := strings.IndexByte(in, '=')
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("malformed labels")
+		}
+		key := strings.TrimSpace(in[:eq])
+		in = strings.TrimLeft(in[eq+1:], " \t")
+		if !strings.HasPrefix(in, `"`) {
+			return nil, "", fmt.Errorf("label %s: value must be quoted", key)
+		}
+		val, rest, ok := quoted(in[1:])
+		if !ok {
+			return nil, "", fmt.Errorf("label %s: unterminated value", key)
+		}
+		labels[key] = val
+		in = strings.TrimLeft(rest, " \t")
+		if strings.HasPrefix(in, ",") {
+			in = in[1:]
+		} else if !strings.HasPrefix(in, "}") {
+			return nil, "", fmt.Errorf("label %s: expected , or }", key)
+		}
+	}
+}
+
+// quoted reads an escaped label value up to its closing quote.
+func quoted(in string) (string, string, bool) {
+	for i := 0; i < len(in); i++ {
+		switch in[i] {
+		case '\\':
+			i++
+		case '"':
+			return unescape(in[:i], true), in[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// unescape resolves \\, \n and, in label values, \".
+func unescape(s string, quotes bool) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch c := s[i+1]; {
+			case c == 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case c == '\\', c == '"' && quotes:
+				b.WriteByte(c)
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || r == ':' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
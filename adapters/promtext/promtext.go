@@ -0,0 +1,149 @@
+// Package promtext provides a built-in types.MetricsReader and
+// types.MetricsScraper that parse Prometheus text or OpenMetrics exposition
+// from a /metrics URL or a file.
+package promtext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultTimeout bounds a scrape over HTTP.
+const DefaultTimeout = 5 * time.Second
+
+// maxBody bounds the size of one exposition.
+const maxBody = 32 << 20
+
+const acceptHeader = "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// Option configures a Reader.
+type Option func(*Reader)
+
+// WithTimeout bounds each HTTP scrape.
+func WithTimeout(d time.Duration) Option { return func(r *Reader) { r.timeout = d } }
+
+// WithHTTPClient sets the client used for HTTP scrapes.
+func WithHTTPClient(c *http.Client) Option { return func(r *Reader) { r.client = c } }
+
+// Reader scrapes metrics from a URL (http:// or https://) or a file path.
+type Reader struct {
+	source  string
+	client  *http.Client
+	timeout time.Duration
+}
+
+// New returns a Reader for source, such as http://localhost:8080/metrics.
+func New(source string, opts ...Option) *Reader {
+	r := &Reader{source: source, client: http.DefaultClient, timeout: DefaultTimeout}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(r)
+		}
+	}
+	return r
+}
+
+// Scrape implements types.MetricsScraper.
+func (r *Reader) Scrape(ctx context.Context) (*types.MetricsScrape, error) {
+	body, err := r.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	families, err := Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", r.source, err)
+	}
+	return &types.MetricsScrape{At: time.Now(), Families: families}, nil
+}
+
+func (r *Reader) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(r.source, "http://") && !strings.HasPrefix(r.source, "https://") {
+		return os.ReadFile(r.source)
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("metrics request: %w", err)
+	}
+	req.Header.Set("Accept", acceptHeader)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", r.source, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: status %d", r.source, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", r.source, err)
+	}
+	if len(body) > maxBody {
+		return nil, fmt.Errorf("GET %s: exposition larger than %d bytes", r.source, maxBody)
+	}
+	return body, nil
+}
+
+// Summary implements types.MetricsReader. Each family reports its type,
+// help, series count and, for counters and gauges, the total across
+// series; histograms and summaries report their total count and sum.
+func (r *Reader) Summary(ctx context.Context) (map[string]any, error) {
+	scrape, err := r.Scrape(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make(map[string]any, len(scrape.Families))
+	for _, f := range scrape.Families {
+		m := map[string]any{"type": f.Type}
+		if f.Help != "" {
+			m["help"] = f.Help
+		}
+		switch f.Type {
+		case types.MetricHistogram, types.MetricSummary:
+			var count, sum float64
+			series := 0
+			for _, s := range f.Samples {
+				switch s.Name {
+				case f.Name + "_count":
+					count += s.Value
+					series++
+				case f.Name + "_sum":
+					if !math.IsNaN(s.Value) && !math.IsInf(s.Value, 0) {
+						sum += s.Value
+					}
+				}
+			}
+			m["series"], m["count"], m["sum"] = series, count, sum
+		default:
+			var total float64
+			series := 0
+			for _, s := range f.Samples {
+				if !strings.HasSuffix(s.Name, "_created") && !math.IsNaN(s.Value) && !math.IsInf(s.Value, 0) {
+					total += s.Value
+					series++
+				}
+			}
+			m["series"], m["value"] = series, total
+		}
+		metrics[f.Name] = m
+	}
+	return map[string]any{
+		"source":     r.source,
+		"scraped_at": scrape.At.Format(time.RFC3339),
+		"families":   len(scrape.Families),
+		"metrics":    metrics,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package promtext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+const exposition = `# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/orders"} 1027
+http_requests_total{method="POST",route="/orders",note="a \"quoted\" \\ value"} 3 1700000000000
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/orders",le="0.1"} 80
+http_request_duration_seconds_bucket{route="/orders",le="0.5"} 95
+http_request_duration_seconds_bucket{route="/orders",le="+Inf"} 100
+http_request_duration_seconds_sum{route="/orders"} 12.5
+http_request_duration_seconds_count{route="/orders"} 100
+# TYPE queue_depth gauge
+queue_depth 7
+process_start_time_seconds 1.7e+09
+`
+
+func TestParse(t *testing.T) {
+	families, err := Parse(strings.NewReader(exposition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 4 {
+		t.Fatalf("families = %d, want 4: %+v", len(families), families)
+	}
+	reqs := families[0]
+	if reqs.Name != "http_requests_total" || reqs.Type != types.MetricCounter || reqs.Help != "Total HTTP requests." || len(reqs.Samples) != 2 {
+		t.Errorf("requests family = %+v", reqs)
+	}
+	if got := reqs.Samples[1].Labels["note"]; got != `a "quoted" \ value` {
+		t.Errorf("escaped label = %q", got)
+	}
+	hist := families[1]
+	if hist.Type != types.MetricHistogram || len(hist.Samples) != 5 || hist.Samples[2].Labels["le"] != "+Inf" {
+		t.Errorf("histogram family = %+v", hist)
+	}
+	if untyped := families[3]; untyped.Name != "process_start_time_seconds" || untyped.Type != types.MetricUntyped {
+		t.Errorf("untyped family = %+v", untyped)
+	}
+}
+
+func TestParse_OpenMetrics(t *testing.T) {
+	in := `# TYPE jobs counter
+# UNIT jobs
+jobs_total{queue="mail"} 5 # {trace_id="abc"} 1.0
+jobs_created{queue="mail"} 1.7e+09
+# EOF
+ignored 1
+`
+	families, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || len(families[0].Samples) != 2 || families[0].Samples[0].Value != 5 {
+		t.Errorf("families = %+v", families)
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	for _, in := range []string{"<html>", `m{a="b} 1`, "m{a=b} 1", "m abc"} {
+		if _, err := Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q) succeeded", in)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(exposition))
+	}))
+	defer srv.Close()
+
+	sum, err := New(srv.URL + "/metrics").Summary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := sum["metrics"].(map[string]any)
+	if m := metrics["http_requests_total"].(map[string]any); m["value"] != 1030.0 || m["series"] != 2 {
+		t.Errorf("requests = %v", m)
+	}
+	if m := metrics["http_request_duration_seconds"].(map[string]any); m["count"] != 100.0 || m["sum"] != 12.5 {
+		t.Errorf("latency = %v", m)
+	}
+
+	if _, err := New(srv.URL + "/nope").Scrape(context.Background()); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Scrape(404) = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+	if err := os.WriteFile(path, []byte(exposition), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	scrape, err := New(path).Scrape(context.Background())
+	if err != nil || len(scrape.Families) != 4 {
+		t.Errorf("file scrape = %v, %v", scrape, err)
+	}
+}
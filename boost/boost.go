@@ -4,15 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 
+	"github.com/next-trace/scg-boost/internal/dbsession"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	internal_runtime "github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/internal/schema"
 	"github.com/next-trace/scg-boost/internal/tools/appinfo"
 	"github.com/next-trace/scg-boost/internal/tools/cache"
 	"github.com/next-trace/scg-boost/internal/tools/config"
+	"github.com/next-trace/scg-boost/internal/tools/correlate"
+	"github.com/next-trace/scg-boost/internal/tools/dbprofile"
 	"github.com/next-trace/scg-boost/internal/tools/dbquery"
 	"github.com/next-trace/scg-boost/internal/tools/dbschema"
+	"github.com/next-trace/scg-boost/internal/tools/diagnose"
 	"github.com/next-trace/scg-boost/internal/tools/docs"
 	"github.com/next-trace/scg-boost/internal/tools/env"
 	"github.com/next-trace/scg-boost/internal/tools/events"
@@ -20,6 +28,7 @@ import (
 	"github.com/next-trace/scg-boost/internal/tools/logs"
 	"github.com/next-trace/scg-boost/internal/tools/metrics"
 	"github.com/next-trace/scg-boost/internal/tools/migrations"
+	"github.com/next-trace/scg-boost/internal/tools/queues"
 	"github.com/next-trace/scg-boost/internal/tools/routes"
 	"github.com/next-trace/scg-boost/internal/tools/service"
 	"github.com/next-trace/scg-boost/internal/tools/trace"
@@ -38,6 +47,8 @@ func New(opts ...Option) (Server, error) {
 		Version:        "0.1.0",
 		MaxRows:        500,
 		DBQueryTimeout: 3 * time.Second,
+
+		SLOSampleInterval: 30 * time.Second,
 	}
 	for _, fn := range opts {
 		if fn != nil {
@@ -77,12 +88,20 @@ type Server interface {
 }
 
 type server struct {
-	o   Options
-	mcp *internal_mcp.AuthorizedServer
+	o       Options
+	mcp     *internal_mcp.AuthorizedServer
+	history *health.History
+	slo     *metrics.SLOTracker
 }
 
 // Start implements the Server interface.
 func (s *server) Start(ctx context.Context) (func() error, error) {
+	if s.history != nil {
+		go s.history.Run(ctx)
+	}
+	if s.slo != nil && s.o.SLOSampleInterval > 0 {
+		go s.slo.Run(ctx)
+	}
 	go func() {
 		if err := s.mcp.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			s.o.Logger.Error("mcp server run failed", map[string]any{"error": err.Error()})
@@ -112,33 +131,64 @@ func (s *server) registerTools() error {
 	}
 
 	// DB
-	if s.o.DB != nil {
-		s.registerTool("dbschema.list", dbschema.Register(s.mcp, s.o.DB, s.o.AllowSchemas))
-		s.registerTool("dbquery.run", dbquery.Register(s.mcp, s.o.DB, s.o.MaxRows, s.o.DBQueryTimeout))
+	snapshotPath := s.schemaSnapshotPath()
+	migrationsDir := s.projectPath(s.o.MigrationsDir)
+	db, dbTools := s.dbToolAdder()
+	if db != nil || fileExists(snapshotPath) {
+		s.registerTool("dbschema.list", dbschema.Register(dbTools, db, s.o.AllowSchemas, snapshotPath))
+		s.registerTool("dbschema.erd", dbschema.RegisterERD(dbTools, db, s.o.AllowSchemas, snapshotPath))
+	}
+	if db != nil {
+		mask := internal_runtime.NewMasker(s.o.MaskColumns)
+		s.registerTool("dbquery.run", dbquery.Register(dbTools, db, s.o.MaxRows, s.o.DBQueryTimeout, mask))
+		s.registerTool("db.profile", dbprofile.Register(dbTools, db, s.o.AllowSchemas, s.o.MaxRows, s.o.DBQueryTimeout, mask))
+		if snapshotPath != "" || migrationsDir != "" {
+			s.registerTool("dbschema.drift", dbschema.RegisterDrift(dbTools, db, s.o.AllowSchemas, snapshotPath, migrationsDir))
+		}
 	}
 
 	// Logs
 	if s.o.LogStore != nil {
 		// Adapt LogStore to LogReader
 		logReader := &logStoreAdapter{store: s.o.LogStore}
-		s.registerTool("logs.lastError", logs.Register(s.mcp, logReader))
+		s.registerTool("logs.lastError", logs.Register(s.mcp, logReader, s.o.LogRedactFields))
+		if q, ok := s.o.LogStore.(types.LogQuerier); ok {
+			s.registerTool("logs.search", logs.RegisterSearch(s.mcp, q, s.o.LogRedactFields))
+			s.registerTool("logs.clusters", logs.RegisterClusters(s.mcp, q, s.o.LogRedactFields))
+		}
 	}
 
 	// Health
-	if s.o.HealthProbe != nil {
-		s.registerTool("health.status", health.Register(s.mcp, s.o.HealthProbe))
+	if s.o.HealthProbe != nil || s.o.HealthChecker != nil {
+		s.registerTool("health.status", health.Register(s.mcp, s.o.HealthProbe, s.o.HealthChecker))
+		if s.o.HealthHistoryInterval > 0 {
+			s.history = health.NewHistory(s.o.HealthProbe, s.healthChecker(), s.o.HealthHistoryInterval, s.o.HealthHistorySize)
+			s.registerTool("health.history", health.RegisterHistory(s.mcp, s.history))
+		}
 	}
 
 	// Events
 	if s.o.OutboxReader != nil {
 		s.registerTool("events.outbox.peek", events.Register(s.mcp, s.o.OutboxReader))
 	}
+	if dir := s.eventSchemasDir(); fileExists(dir) {
+		s.registerTool("events.validate", events.RegisterValidate(s.mcp, s.o.OutboxReader, dir))
+	}
 
 	// Trace
 	if s.o.TraceReader != nil {
 		s.registerTool("trace.lookup", trace.Register(s.mcp, s.o.TraceReader))
+		if q, ok := s.o.TraceReader.(types.TraceQuerier); ok {
+			s.registerTool("trace.get", trace.RegisterQuery(s.mcp, q))
+		}
 	}
 
+	// Correlation across logs, traces and the outbox
+	s.registerTool("correlate", correlate.Register(s.mcp, s.correlateSources()))
+
+	// Incident snapshot over all providers
+	s.registerTool("diagnose.snapshot", diagnose.Register(s.mcp, s.diagnoseProviders()))
+
 	// Service Topology
 	if s.o.TopologyProvider != nil {
 		s.registerTool("service.topology", service.Register(s.mcp, s.o.TopologyProvider))
@@ -153,12 +203,20 @@ func (s *server) registerTools() error {
 	if s.o.MigrationReader != nil {
 		s.registerTool("migrations.status", migrations.Register(s.mcp, s.o.MigrationReader))
 	}
+	if migrationsDir != "" {
+		s.registerTool("migrations.lint", migrations.RegisterLint(s.mcp, migrationsDir))
+	}
 
 	// Cache
 	if s.o.CacheInspector != nil {
 		s.registerTool("cache.stats", cache.Register(s.mcp, s.o.CacheInspector))
 	}
 
+	// Queues
+	if s.o.QueueInspector != nil {
+		s.registerTool("queues.status", queues.Register(s.mcp, s.o.QueueInspector))
+	}
+
 	// Docs
 	if s.o.DocsSearcher != nil {
 		s.registerTool("docs.search", docs.Register(s.mcp, s.o.DocsSearcher))
@@ -167,6 +225,16 @@ func (s *server) registerTools() error {
 	// Metrics
 	if s.o.MetricsReader != nil {
 		s.registerTool("metrics.summary", metrics.Register(s.mcp, s.o.MetricsReader))
+		if ms, ok := s.o.MetricsReader.(types.MetricsScraper); ok {
+			s.registerTool("metrics.query", metrics.RegisterQuery(s.mcp, ms))
+			if path := s.sloFile(); fileExists(path) {
+				s.slo = metrics.NewSLOTracker(ms, path, s.o.SLOSampleInterval)
+				s.registerTool("slo.status", metrics.RegisterSLO(s.mcp, s.slo))
+			}
+		}
+		if dir := s.metricsBaselineDir(); dir != "" {
+			s.registerTool("metrics.baseline.save", metrics.RegisterBaseline(s.mcp, s.o.MetricsReader, dir))
+		}
 	}
 
 	// Env
@@ -197,6 +265,127 @@ func (s *server) registerTools() error {
 	return nil
 }
 
+// dbToolAdder returns the DB and the ToolAdder DB tools register with. With
+// session variables configured, tools are wrapped to apply them, and the DB is
+// dropped (fail closed) when it cannot apply them.
+func (s *server) dbToolAdder() (types.DBConn, internal_mcp.ToolAdder) {
+	resolver := dbsession.NewResolver(s.o.SessionVars, s.o.SessionVarsProvider)
+	if resolver == nil {
+		return s.o.DB, s.mcp
+	}
+	db := s.o.DB
+	if applier, ok := db.(types.SessionVarsApplier); db != nil && (!ok || !applier.AppliesSessionVars()) {
+		s.o.Logger.Error("DB does not apply session variables; DB tools disabled", map[string]any{"db": fmt.Sprintf("%T", db)})
+		db = nil
+	}
+	return db, resolver.Wrap(s.mcp)
+}
+
+// correlateSources collects the configured providers for the correlate tool.
+func (s *server) correlateSources() correlate.Sources {
+	src := correlate.Sources{
+		TraceReader:  s.o.TraceReader,
+		OutboxReader: s.o.OutboxReader,
+		RedactFields: s.o.LogRedactFields,
+		Authorizer:   s.o.Authorizer,
+	}
+	if s.o.LogStore != nil {
+		src.LogReader = &logStoreAdapter{store: s.o.LogStore}
+		src.LogQuerier, _ = s.o.LogStore.(types.LogQuerier)
+	}
+	src.TraceQuerier, _ = s.o.TraceReader.(types.TraceQuerier)
+	return src
+}
+
+// diagnoseProviders collects the configured providers for diagnose.snapshot.
+func (s *server) diagnoseProviders() diagnose.Providers {
+	src := s.correlateSources()
+	return diagnose.Providers{
+		HealthProbe:     s.o.HealthProbe,
+		HealthChecker:   s.healthChecker(),
+		LogReader:       src.LogReader,
+		LogQuerier:      src.LogQuerier,
+		MetricsReader:   s.o.MetricsReader,
+		MigrationReader: s.o.MigrationReader,
+		CacheInspector:  s.o.CacheInspector,
+		EnvChecker:      s.o.EnvChecker,
+		TraceReader:     s.o.TraceReader,
+		TraceQuerier:    src.TraceQuerier,
+		RedactFields:    s.o.LogRedactFields,
+		Authorizer:      s.o.Authorizer,
+	}
+}
+
+// healthChecker returns the configured HealthChecker, falling back to the
+// HealthProbe when it implements one.
+func (s *server) healthChecker() types.HealthChecker {
+	if s.o.HealthChecker != nil {
+		return s.o.HealthChecker
+	}
+	hc, _ := s.o.HealthProbe.(types.HealthChecker)
+	return hc
+}
+
+// schemaSnapshotPath resolves the offline schema snapshot location.
+func (s *server) schemaSnapshotPath() string {
+	if s.o.SchemaSnapshotPath != "" {
+		return s.projectPath(s.o.SchemaSnapshotPath)
+	}
+	if s.o.ProjectRoot != "" {
+		return filepath.Join(s.o.ProjectRoot, schema.DefaultSnapshotPath)
+	}
+	return ""
+}
+
+// metricsBaselineDir resolves where metrics baselines are stored.
+func (s *server) metricsBaselineDir() string {
+	if s.o.MetricsBaselineDir != "" {
+		return s.projectPath(s.o.MetricsBaselineDir)
+	}
+	if s.o.ProjectRoot != "" {
+		return filepath.Join(s.o.ProjectRoot, metrics.DefaultBaselineDir)
+	}
+	return ""
+}
+
+// sloFile resolves the SLO definition file.
+func (s *server) sloFile() string {
+	if s.o.SLOFile != "" {
+		return s.projectPath(s.o.SLOFile)
+	}
+	if s.o.ProjectRoot != "" {
+		return filepath.Join(s.o.ProjectRoot, metrics.DefaultSLOFile)
+	}
+	return ""
+}
+
+// eventSchemasDir resolves the event contract schema directory.
+func (s *server) eventSchemasDir() string {
+	if s.o.EventSchemasDir != "" {
+		return s.projectPath(s.o.EventSchemasDir)
+	}
+	if s.o.ProjectRoot != "" {
+		return filepath.Join(s.o.ProjectRoot, events.DefaultSchemasDir)
+	}
+	return ""
+}
+
+// projectPath resolves p against ProjectRoot when it is relative.
+func (s *server) projectPath(p string) string {
+	if p == "" || filepath.IsAbs(p) || s.o.ProjectRoot == "" {
+		return p
+	}
+	return filepath.Join(s.o.ProjectRoot, p)
+}
+
+func fileExists(p string) bool {
+	if p == "" {
+		return false
+	}
+	_, err := os.Stat(p)
+	return err == nil
+}
+
 func (s *server) registerTool(name string, toolErr error) {
 	if toolErr != nil {
 		s.o.Logger.Error(fmt.Sprintf("failed to register tool %s", name), map[string]any{"error": toolErr.Error()})
@@ -228,7 +417,7 @@ func (a *logStoreAdapter) LastError(ctx context.Context) (*types.LogEntry, error
 		return nil, nil
 	}
 	// Parse timestamp, assuming it's RFC3339
-	timestamp, err := time.Parse(time.RFC3339, ts)
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
 	if err != nil {
 		return nil, err
 	}
@@ -236,9 +425,20 @@ func (a *logStoreAdapter) LastError(ctx context.Context) (*types.LogEntry, error
 	if lvl, ok := fields["level"].(string); ok {
 		level = lvl
 	}
+	var rest map[string]any
+	for k, v := range fields {
+		if k == "level" {
+			continue
+		}
+		if rest == nil {
+			rest = make(map[string]any, len(fields))
+		}
+		rest[k] = v
+	}
 	return &types.LogEntry{
 		Timestamp: timestamp,
 		Level:     level,
 		Message:   msg,
+		Fields:    rest,
 	}, nil
 }
@@ -2,8 +2,11 @@ package boost
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/next-trace/scg-boost/types"
 )
 
 // mockLogger implements types.Logger for testing.
@@ -226,6 +229,9 @@ func TestLogStoreAdapter(t *testing.T) {
 	if entry.Level != "error" {
 		t.Errorf("Level = %q, want %q", entry.Level, "error")
 	}
+	if entry.Fields["trace_id"] != "abc123" {
+		t.Errorf("Fields = %v, want trace_id kept", entry.Fields)
+	}
 }
 
 func TestLogStoreAdapter_EmptyEntry(t *testing.T) {
@@ -273,3 +279,34 @@ type mockLogStore struct {
 func (m *mockLogStore) LastError(ctx context.Context) (ts, msg string, fields map[string]any, err error) {
 	return m.ts, m.msg, m.fields, m.err
 }
+
+// plainDB implements types.DBConn without applying session variables.
+type plainDB struct{}
+
+func (plainDB) QueryJSON(context.Context, string, map[string]any) ([]map[string]any, error) {
+	return nil, nil
+}
+func (plainDB) Schemas(context.Context) ([]string, error)                         { return nil, nil }
+func (plainDB) Tables(context.Context, string) ([]string, error)                  { return nil, nil }
+func (plainDB) Columns(context.Context, string, string) ([]map[string]any, error) { return nil, nil }
+
+func TestNew_SessionVarsRequireApplier(t *testing.T) {
+	logger := &mockLogger{}
+	_, err := New(
+		WithLogger(logger),
+		WithDB(plainDB{}),
+		WithSessionVars(types.SessionVar{Name: "app.tenant_id", Required: true}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	found := false
+	for _, c := range logger.errorCalls {
+		if strings.Contains(c.msg, "session variables") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected DB tools to be disabled, logs = %+v", logger.errorCalls)
+	}
+}
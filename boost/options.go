@@ -17,6 +17,7 @@ type Options struct {
 	Authorizer       types.Authorizer
 	LogStore         types.LogStore
 	HealthProbe      types.HealthProbe
+	HealthChecker    types.HealthChecker
 	OutboxReader     types.OutboxReader
 	TraceReader      types.TraceReader
 	TopologyProvider types.TopologyProvider
@@ -24,6 +25,46 @@ type Options struct {
 	MaxRows          int
 	DBQueryTimeout   time.Duration
 
+	// MaskColumns lists column name globs (case-insensitive, e.g. "password",
+	// "*_token") whose values are hidden by dbquery.run and db.profile.
+	MaskColumns []string
+
+	// LogRedactFields lists log field name globs hidden by logs tools, in
+	// addition to the built-in defaults.
+	LogRedactFields []string
+
+	// HealthHistoryInterval enables background health polling for
+	// health.history when positive; HealthHistorySize bounds the samples kept.
+	HealthHistoryInterval time.Duration
+	HealthHistorySize     int
+
+	// SessionVars are Postgres settings applied with SET LOCAL before each DB
+	// tool call, for row-level security; values come from
+	// SessionVarsProvider or tool arguments.
+	SessionVars         []types.SessionVar
+	SessionVarsProvider types.SessionVarsProvider
+
+	// SchemaSnapshotPath points at an offline schema snapshot used when the
+	// database is unreachable and as a drift baseline. Relative paths resolve
+	// against ProjectRoot; defaults to .scg/schema.snapshot.json there.
+	SchemaSnapshotPath string
+	// MigrationsDir is the directory holding SQL migration files. Relative
+	// paths resolve against ProjectRoot.
+	MigrationsDir string
+	// MetricsBaselineDir stores metrics.baseline.save snapshots. Relative
+	// paths resolve against ProjectRoot; defaults to .scg/metrics there.
+	MetricsBaselineDir string
+	// SLOFile holds SLO definitions for slo.status. Relative paths resolve
+	// against ProjectRoot; defaults to .scg/slo.yaml there.
+	SLOFile string
+	// SLOSampleInterval is how often SLO event counts are sampled in the
+	// background for burn rates; 0 samples only when slo.status is called.
+	SLOSampleInterval time.Duration
+	// EventSchemasDir holds the contract JSON schemas for events.validate.
+	// Relative paths resolve against ProjectRoot; defaults to contracts/events
+	// there.
+	EventSchemasDir string
+
 	// ProjectRoot, when set, enables project-scoped MCP resources (summary, tree,...).
 	ProjectRoot            string
 	ProjectSummaryMarkdown string
@@ -32,6 +73,7 @@ type Options struct {
 	RouteProvider   types.RouteProvider
 	MigrationReader types.MigrationReader
 	CacheInspector  types.CacheInspector
+	QueueInspector  types.QueueInspector
 	DocsSearcher    types.DocsSearcher
 	MetricsReader   types.MetricsReader
 	EnvChecker      types.EnvChecker
@@ -66,22 +108,94 @@ func WithMaxRows(n int) Option { return func(o *Options) { o.MaxRows = n } }
 // WithDBQueryTimeout sets the timeout for DB queries.
 func WithDBQueryTimeout(d time.Duration) Option { return func(o *Options) { o.DBQueryTimeout = d } }
 
+// WithMaskColumns hides values of matching columns in DB tool results.
+// Patterns are case-insensitive globs such as "password" or "*_token".
+func WithMaskColumns(patterns ...string) Option {
+	return func(o *Options) { o.MaskColumns = append([]string{}, patterns...) }
+}
+
+// WithSessionVars applies Postgres session variables (e.g. app.tenant_id) with
+// SET LOCAL before every DB tool call so row-level security policies apply.
+// The DB must implement types.SessionVarsApplier or DB tools are disabled.
+func WithSessionVars(vars ...types.SessionVar) Option {
+	return func(o *Options) { o.SessionVars = append([]types.SessionVar{}, vars...) }
+}
+
+// WithSessionVarsProvider resolves session variables for the calling
+// principal. Its values take precedence over tool arguments.
+func WithSessionVarsProvider(p types.SessionVarsProvider) Option {
+	return func(o *Options) { o.SessionVarsProvider = p }
+}
+
+// WithSchemaSnapshot sets the offline schema snapshot file used as a fallback
+// for dbschema tools and as a drift baseline.
+func WithSchemaSnapshot(path string) Option { return func(o *Options) { o.SchemaSnapshotPath = path } }
+
+// WithMigrationsDir sets the directory holding SQL migration files.
+func WithMigrationsDir(dir string) Option { return func(o *Options) { o.MigrationsDir = dir } }
+
+// WithMetricsBaselineDir sets where metrics baselines are stored.
+func WithMetricsBaselineDir(dir string) Option {
+	return func(o *Options) { o.MetricsBaselineDir = dir }
+}
+
+// WithSLOFile sets the SLO definition file.
+func WithSLOFile(path string) Option { return func(o *Options) { o.SLOFile = path } }
+
+// WithEventSchemasDir sets the directory of event contract JSON schemas.
+func WithEventSchemasDir(dir string) Option { return func(o *Options) { o.EventSchemasDir = dir } }
+
+// WithSLOSampleInterval sets the background SLO sampling interval.
+func WithSLOSampleInterval(d time.Duration) Option {
+	return func(o *Options) { o.SLOSampleInterval = d }
+}
+
 // WithAuthorizer supplies an optional authorizer for tool access control.
 func WithAuthorizer(a types.Authorizer) Option { return func(o *Options) { o.Authorizer = a } }
 
 // WithLogStore supplies an optional log store for retrieving last errors.
+// When ls also implements types.LogQuerier, logs.search, logs.tail and
+// logs.clusters are registered too.
 func WithLogStore(ls types.LogStore) Option { return func(o *Options) { o.LogStore = ls } }
 
+// WithLogRedactFields hides log field values whose names match the given
+// case-insensitive globs, in addition to logs.DefaultRedactFields (passwords,
+// secrets, tokens, API keys, credentials, cookies and authorization headers).
+func WithLogRedactFields(patterns ...string) Option {
+	return func(o *Options) { o.LogRedactFields = append(o.LogRedactFields, patterns...) }
+}
+
 // WithHealthProbe supplies an optional health probe for liveness and readiness checks.
+// When h also implements types.HealthChecker, its component checks are
+// reported too.
 func WithHealthProbe(h types.HealthProbe) Option { return func(o *Options) { o.HealthProbe = h } }
 
+// WithHealthChecker supplies optional named component checks (database,
+// cache, broker, downstream APIs) for health.status and diagnose.snapshot.
+func WithHealthChecker(hc types.HealthChecker) Option {
+	return func(o *Options) { o.HealthChecker = hc }
+}
+
+// WithHealthHistory samples the health probe and checker every interval
+// while the server runs, keeping the last size samples (720 when size is 0)
+// for the health.history tool.
+func WithHealthHistory(interval time.Duration, size int) Option {
+	return func(o *Options) {
+		o.HealthHistoryInterval = interval
+		o.HealthHistorySize = size
+	}
+}
+
 // WithOutboxReader supplies an optional outbox reader for event peeking.
 func WithOutboxReader(or types.OutboxReader) Option { return func(o *Options) { o.OutboxReader = or } }
 
 // WithTraceReader supplies an optional trace reader for looking up recent traces.
+// When tr also implements types.TraceQuerier, trace.get and trace.search are
+// registered too.
 func WithTraceReader(tr types.TraceReader) Option { return func(o *Options) { o.TraceReader = tr } }
 
 // WithTopologyProvider supplies an optional topology provider for service topology snapshots.
+// Providers that also implement types.TopologyGrapher return a typed graph.
 func WithTopologyProvider(tp types.TopologyProvider) Option {
 	return func(o *Options) { o.TopologyProvider = tp }
 }
@@ -120,6 +234,12 @@ func WithCacheInspector(ci types.CacheInspector) Option {
 	return func(o *Options) { o.CacheInspector = ci }
 }
 
+// WithQueueInspector supplies an optional broker inspector for queue and
+// consumer group status.
+func WithQueueInspector(qi types.QueueInspector) Option {
+	return func(o *Options) { o.QueueInspector = qi }
+}
+
 // WithDocsSearcher supplies an optional docs searcher for documentation search.
 func WithDocsSearcher(ds types.DocsSearcher) Option {
 	return func(o *Options) { o.DocsSearcher = ds }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/internal/argparse"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/internal/schema"
+	"github.com/next-trace/scg-boost/internal/tools/dbschema"
+)
+
+func cmdDBSnapshot(args []string) int {
+	fs := flag.NewFlagSet("db:snapshot", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	root := fs.String("root", ".", "repo root")
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "postgres DSN (defaults to $DATABASE_URL)")
+	schemas := fs.String("schemas", "", "comma-separated schema allowlist (default: all)")
+	out := fs.String("out", schema.DefaultSnapshotPath, "snapshot path, relative to --root")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*dsn) == "" {
+		fmt.Fprintln(os.Stderr, "error: --dsn or DATABASE_URL is required")
+		return 2
+	}
+
+	abs, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	db, err := runtime.OpenPostgres(ctx, *dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+
+	snap, err := schema.Introspect(ctx, db, argparse.List(*schemas))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	path := *out
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(abs, path)
+	}
+	if err := schema.Save(path, snap); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	fmt.Printf("Wrote schema snapshot (%d tables) to %s\n", len(snap.Tables), path)
+	return 0
+}
+
+func cmdDBDrift(args []string) int {
+	fs := flag.NewFlagSet("db:drift", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	root := fs.String("root", ".", "repo root")
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "postgres DSN (defaults to $DATABASE_URL)")
+	schemas := fs.String("schemas", "", "comma-separated schema allowlist (default: all)")
+	against := fs.String("against", "snapshot", "baseline: snapshot|migrations")
+	snapshotPath := fs.String("snapshot", schema.DefaultSnapshotPath, "snapshot path, relative to --root")
+	migrationsDir := fs.String("migrations-dir", "migrations", "migrations directory, relative to --root")
+	jsonOut := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*dsn) == "" {
+		fmt.Fprintln(os.Stderr, "error: --dsn or DATABASE_URL is required")
+		return 2
+	}
+
+	abs, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	db, err := runtime.OpenPostgres(ctx, *dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+
+	drift, err := dbschema.Compare(ctx, db, argparse.List(*schemas), *against, resolvePath(abs, *snapshotPath), resolvePath(abs, *migrationsDir))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(drift); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	} else if !drift.HasChanges() {
+		fmt.Printf("No schema drift against %s\n", *against)
+	} else {
+		fmt.Printf("Schema drift against %s:\n", *against)
+		for _, line := range drift.Lines() {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	if drift.HasChanges() {
+		return 1
+	}
+	return 0
+}
+
+func cmdDBERD(args []string) int {
+	fs := flag.NewFlagSet("db:erd", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	root := fs.String("root", ".", "repo root")
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "postgres DSN (defaults to $DATABASE_URL; the snapshot is used when empty)")
+	snapshotPath := fs.String("snapshot", schema.DefaultSnapshotPath, "snapshot path, relative to --root")
+	format := fs.String("format", schema.FormatMermaid, "diagram format: mermaid|dot")
+	schemas := fs.String("schemas", "", "comma-separated schemas to include (default: all)")
+	tables := fs.String("tables", "", "comma-separated seed tables; only these and their neighbours are drawn")
+	hops := fs.Int("hops", 1, "relationship hops to follow from seed tables")
+	out := fs.String("out", "", "write the diagram to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	abs, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var snap *schema.Snapshot
+	if strings.TrimSpace(*dsn) != "" {
+		db, err := runtime.OpenPostgres(ctx, *dsn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		defer func() { _ = db.Close() }()
+		snap, err = schema.Introspect(ctx, db, argparse.List(*schemas))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	} else {
+		snap, err = schema.Load(resolvePath(abs, *snapshotPath))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	}
+
+	selected := schema.Neighborhood(snap.Filter(argparse.List(*schemas)), argparse.List(*tables), *hops)
+	diagram, err := schema.RenderERD(selected, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	if *out == "" {
+		fmt.Print(diagram)
+		return 0
+	}
+	path := resolvePath(abs, *out)
+	if err := os.WriteFile(path, []byte(diagram), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	fmt.Printf("Wrote ER diagram (%d tables) to %s\n", len(selected), path)
+	return 0
+}
+
+func resolvePath(root, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(root, p)
+}
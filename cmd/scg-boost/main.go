@@ -17,9 +17,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/next-trace/scg-boost/adapters/httphealth"
+	"github.com/next-trace/scg-boost/adapters/logfile"
+	"github.com/next-trace/scg-boost/adapters/otlpfile"
+	"github.com/next-trace/scg-boost/adapters/promtext"
 	"github.com/next-trace/scg-boost/boost"
 	"github.com/next-trace/scg-boost/internal/bootstrap"
 	"github.com/next-trace/scg-boost/internal/project"
+	"github.com/next-trace/scg-boost/internal/security"
 	"github.com/next-trace/scg-boost/internal/skills"
 	"github.com/next-trace/scg-boost/resources"
 )
@@ -59,6 +64,14 @@ func run(args []string) int {
 		return cmdSkillsSync(args[1:])
 	case "skills:override":
 		return cmdSkillsOverride(args[1:])
+	case "db:snapshot":
+		return cmdDBSnapshot(args[1:])
+	case "db:drift":
+		return cmdDBDrift(args[1:])
+	case "db:erd":
+		return cmdDBERD(args[1:])
+	case "migrations:lint":
+		return cmdMigrationsLint(args[1:])
 	case "help", "-h", "--help":
 		usage()
 		return 0
@@ -79,14 +92,18 @@ Usage:
   scg-boost update [--root .] [--name <server>] [--check-mcp-up=true]
   scg-boost config --client claude|codex|gemini|cursor|junie [--root .] [--name <server>]
   scg-boost scan [--root .]
-  scg-boost mcp [--root .] [--name <app>] [--version <v>]
+  scg-boost mcp [--root .] [--name <app>] [--version <v>] [--log-file <path>] [--otlp-dir <dir>] [--health-url <url>] [--metrics-url <url|file>] [--event-schemas <dir>]
   scg-boost tools [--json]
   scg-boost version
   scg-boost validate [--root .]
   scg-boost skills:list [--format json|table]
   scg-boost skills:install --skill <name> [--root .] [--force]
   scg-boost skills:sync [--root .]
-  scg-boost skills:override --skill <name> [--root .] [--path <path>] [--force]`)
+  scg-boost skills:override --skill <name> [--root .] [--path <path>] [--force]
+  scg-boost db:snapshot [--root .] [--dsn <dsn>] [--schemas a,b] [--out .scg/schema.snapshot.json]
+  scg-boost db:drift [--root .] [--dsn <dsn>] [--against snapshot|migrations] [--migrations-dir migrations] [--json]
+  scg-boost db:erd [--root .] [--dsn <dsn>] [--format mermaid|dot] [--schemas a,b] [--tables t1,t2] [--hops 1] [--out <file>]
+  scg-boost migrations:lint [--root .] [--dir migrations] [--min-severity info|warning|error] [--json]`)
 }
 
 func cmdInstall(args []string) int {
@@ -450,6 +467,13 @@ func cmdMCP(args []string) int {
 	root := fs.String("root", ".", "repo root")
 	name := fs.String("name", "", "server name (defaults to folder name)")
 	version := fs.String("version", "0.1.0", "server version")
+	logFile := fs.String("log-file", "", "JSON-lines or logfmt log file for the logs tools (rotations such as app.log.1 and app.log.2.gz are included)")
+	otlpDir := fs.String("otlp-dir", "", "directory of OTLP/JSON trace files (collector file exporter) for the trace tools")
+	healthURL := fs.String("health-url", "", "service base URL (probes /healthz and /readyz) or a single health endpoint for health.status")
+	readyURL := fs.String("ready-url", "", "readiness endpoint, when it differs from what --health-url implies")
+	metricsURL := fs.String("metrics-url", "", "Prometheus/OpenMetrics /metrics URL or exposition file for metrics.summary and metrics.query")
+	eventSchemas := fs.String("event-schemas", "", "directory of event contract JSON schemas for events.validate")
+	healthInterval := fs.Duration("health-interval", 15*time.Second, "health polling interval for health.history (0 disables)")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
@@ -470,11 +494,58 @@ func cmdMCP(args []string) int {
 		return 1
 	}
 
-	srv, err := boost.New(
+	opts := []boost.Option{
 		boost.WithName(serverName),
 		boost.WithVersion(*version),
 		boost.WithProjectResources(abs, sum.Markdown()),
-	)
+	}
+	// The operator chose these sources, so their tools are allowed; everything
+	// else stays denied by default.
+	granted := scopeAuthorizer{}
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(abs, p)
+	}
+	if *logFile != "" {
+		opts = append(opts, boost.WithLogStore(logfile.New(resolve(*logFile))))
+		granted.grant(security.ScopeLogsLastError, security.ScopeLogsSearch, security.ScopeLogsTail, security.ScopeLogsClusters)
+	}
+	if *otlpDir != "" {
+		opts = append(opts, boost.WithTraceReader(otlpfile.New(resolve(*otlpDir))))
+		granted.grant(security.ScopeTraceLookup, security.ScopeTraceGet, security.ScopeTraceSearch)
+	}
+	if *healthURL != "" {
+		var probeOpts []httphealth.Option
+		if *readyURL != "" {
+			probeOpts = append(probeOpts, httphealth.WithReadinessURL(*readyURL))
+		}
+		opts = append(opts, boost.WithHealthProbe(httphealth.New(*healthURL, probeOpts...)))
+		granted.grant(security.ScopeHealthStatus)
+		if *healthInterval > 0 {
+			opts = append(opts, boost.WithHealthHistory(*healthInterval, 0))
+			granted.grant(security.ScopeHealthHistory)
+		}
+	}
+	if *metricsURL != "" {
+		source := *metricsURL
+		if !strings.Contains(source, "://") {
+			source = resolve(source)
+		}
+		opts = append(opts, boost.WithMetricsReader(promtext.New(source)))
+		granted.grant(security.ScopeMetricsSummary, security.ScopeMetricsQuery, security.ScopeMetricsBaseline, security.ScopeMetricsCompare, security.ScopeSLOStatus)
+	}
+	if *eventSchemas != "" {
+		opts = append(opts, boost.WithEventSchemasDir(resolve(*eventSchemas)))
+		granted.grant(security.ScopeEventsValidate)
+	}
+	if len(granted) > 0 {
+		granted.grant(security.ScopeCorrelate, security.ScopeDiagnoseSnapshot)
+		opts = append(opts, boost.WithAuthorizer(granted))
+	}
+
+	srv, err := boost.New(opts...)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		return 1
@@ -490,6 +561,17 @@ func cmdMCP(args []string) int {
 	return 0
 }
 
+// scopeAuthorizer grants a fixed set of scopes.
+type scopeAuthorizer map[string]bool
+
+func (a scopeAuthorizer) HasScope(_ context.Context, scope string) bool { return a[scope] }
+
+func (a scopeAuthorizer) grant(scopes ...string) {
+	for _, sc := range scopes {
+		a[sc] = true
+	}
+}
+
 func cmdTools(args []string) int {
 	fs := flag.NewFlagSet("tools", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
@@ -504,16 +586,35 @@ func cmdTools(args []string) int {
 		{"name": "config.list", "description": "List configuration keys with prefix"},
 		{"name": "dbquery.run", "description": "Execute a read-only SQL query"},
 		{"name": "dbschema.list", "description": "List database schema, tables, and columns"},
+		{"name": "dbschema.drift", "description": "Diff live schema against snapshot or migrations"},
+		{"name": "dbschema.erd", "description": "Render the schema as a Mermaid or DOT ER diagram"},
+		{"name": "db.profile", "description": "Profile a table's data from a sample and pg_stats"},
 		{"name": "logs.lastError", "description": "Get the last error log entry"},
-		{"name": "health.status", "description": "Get liveness and readiness status"},
-		{"name": "events.outbox.peek", "description": "Peek into the event outbox"},
+		{"name": "logs.search", "description": "Search logs by level, time, message and fields"},
+		{"name": "logs.tail", "description": "Get the most recent log entries"},
+		{"name": "logs.clusters", "description": "Group error logs into message templates"},
+		{"name": "health.status", "description": "Get liveness, readiness and component health checks"},
+		{"name": "health.history", "description": "Health transitions, uptime and streaks from background polling"},
+		{"name": "events.outbox.peek", "description": "Peek into the event outbox with filters and stats"},
+		{"name": "events.deadletter.peek", "description": "List failed and dead-lettered outbox events"},
+		{"name": "events.validate", "description": "Validate event payloads against contract JSON schemas"},
 		{"name": "trace.lookup", "description": "Lookup recent traces"},
-		{"name": "service.topology", "description": "Get service topology snapshot"},
+		{"name": "trace.get", "description": "Get a trace as a span tree with critical path"},
+		{"name": "trace.search", "description": "Search traces by service, operation, duration or errors"},
+		{"name": "correlate", "description": "Timeline of logs, spans and outbox events for a trace, request or aggregate ID"},
+		{"name": "diagnose.snapshot", "description": "Incident snapshot across all configured providers"},
+		{"name": "service.topology", "description": "Service topology with upstream and downstream dependencies"},
 		{"name": "routes.list", "description": "List registered HTTP/gRPC routes"},
 		{"name": "migrations.status", "description": "Get database migration status"},
+		{"name": "migrations.lint", "description": "Lint migration files for unsafe operations"},
 		{"name": "cache.stats", "description": "Get cache statistics"},
+		{"name": "queues.status", "description": "Queue depth, consumer lag and dead letters per queue or topic"},
 		{"name": "docs.search", "description": "Search project documentation"},
 		{"name": "metrics.summary", "description": "Get metrics summary"},
+		{"name": "metrics.query", "description": "Query Prometheus metrics: selectors, rates and histogram quantiles"},
+		{"name": "metrics.baseline.save", "description": "Save a named metrics baseline under .scg/metrics"},
+		{"name": "metrics.compare", "description": "Compare current metrics with a saved baseline"},
+		{"name": "slo.status", "description": "SLIs, error budgets and burn rates for .scg/slo.yaml"},
 		{"name": "env.check", "description": "Validate environment configuration"},
 	}
 
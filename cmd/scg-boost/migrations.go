@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/next-trace/scg-boost/internal/miglint"
+)
+
+func cmdMigrationsLint(args []string) int {
+	fs := flag.NewFlagSet("migrations:lint", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	root := fs.String("root", ".", "repo root")
+	dir := fs.String("dir", "migrations", "migrations directory, relative to --root")
+	minSeverity := fs.String("min-severity", miglint.SeverityInfo, "only report findings at or above: info|warning|error")
+	jsonOut := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	abs, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	findings, err := miglint.LintDir(resolvePath(abs, *dir))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	findings = miglint.AtLeast(findings, *minSeverity)
+	miglint.Sort(findings)
+	summary := miglint.Summary(findings)
+
+	if *jsonOut {
+		if findings == nil {
+			findings = []miglint.Finding{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"summary": summary, "findings": findings}); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	} else if len(findings) == 0 {
+		fmt.Println("No unsafe migration operations found")
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s:%d: [%s] %s: %s\n", filepath.Join(*dir, f.File), f.Line, f.Severity, f.Rule, f.Message)
+		}
+		fmt.Printf("\n%d error(s), %d warning(s)\n", summary[miglint.SeverityError], summary[miglint.SeverityWarning])
+	}
+
+	if summary[miglint.SeverityError] > 0 {
+		return 1
+	}
+	return 0
+}
@@ -3,12 +3,15 @@ module github.com/next-trace/scg-boost
 go 1.26.1
 
 require (
-	github.com/invopop/jsonschema v0.13.0 // indirect; direct
+	github.com/invopop/jsonschema v0.13.0
 	github.com/lib/pq v1.11.2
 	github.com/mark3labs/mcp-go v0.45.0
 )
 
-require github.com/jmoiron/sqlx v1.4.0
+require (
+	github.com/jmoiron/sqlx v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
@@ -18,5 +21,4 @@ require (
 	github.com/spf13/cast v1.10.0 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,39 @@
+// Package argparse parses the argument formats shared by tools and CLI
+// commands.
+package argparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time parses an RFC 3339 timestamp or a duration before now, such as
+// "15m". The sign of the duration is ignored. An empty s returns the zero
+// time.
+func Time(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC 3339 time or duration, got %q", s)
+	}
+	return now.Add(-d.Abs()), nil
+}
+
+// List splits a comma-separated list, trimming spaces and dropping empty
+// items. It returns nil when s has no items.
+func List(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
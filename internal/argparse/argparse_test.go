@@ -0,0 +1,43 @@
+package argparse
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-05-01T10:00:00Z", now.Add(-2 * time.Hour), false},
+		{"2024-05-01T10:00:00.5+02:00", time.Date(2024, 5, 1, 8, 0, 0, 5e8, time.UTC), false},
+		{" 15m ", now.Add(-15 * time.Minute), false},
+		{"-1h", now.Add(-time.Hour), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Time(tt.in, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("Time(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := map[string][]string{
+		"":             nil,
+		" , ":          nil,
+		"a":            {"a"},
+		" a, b ,,c , ": {"a", "b", "c"},
+	}
+	for in, want := range tests {
+		if got := List(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("List(%q) = %#v, want %#v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package dbsession resolves Postgres session variables for DB tool calls and
+// hands them to the DBConn through the context, so row-level security policies
+// see the calling principal's tenant, role or request id.
+package dbsession
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Resolver combines configured session variables with an optional provider.
+type Resolver struct {
+	vars     []types.SessionVar
+	provider types.SessionVarsProvider
+}
+
+// NewResolver returns a Resolver, or nil when neither variables nor a
+// provider are configured.
+func NewResolver(vars []types.SessionVar, provider types.SessionVarsProvider) *Resolver {
+	if len(vars) == 0 && provider == nil {
+		return nil
+	}
+	return &Resolver{vars: vars, provider: provider}
+}
+
+// Resolve returns the session variables for a call. Provider values win; a
+// tool argument may only fill a variable the provider left unset, and
+// conflicting with a provider value is an error rather than an override.
+func (r *Resolver) Resolve(ctx context.Context, args map[string]any) (map[string]string, error) {
+	out := make(map[string]string)
+	if r.provider != nil {
+		vars, err := r.provider.SessionVars(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve session variables: %w", err)
+		}
+		for k, v := range vars {
+			out[k] = v
+		}
+	}
+
+	var missing []string
+	for _, sv := range r.vars {
+		argVal := ""
+		if sv.Arg != "" {
+			if v, ok := args[sv.Arg]; ok && v != nil {
+				argVal = strings.TrimSpace(fmt.Sprint(v))
+			}
+		}
+		cur, fromProvider := out[sv.Name]
+		switch {
+		case fromProvider && argVal != "" && argVal != cur:
+			return nil, fmt.Errorf("argument %s conflicts with the caller's %s", sv.Arg, sv.Name)
+		case !fromProvider && argVal != "":
+			out[sv.Name] = argVal
+		}
+		if sv.Required && out[sv.Name] == "" {
+			missing = append(missing, sv.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required session variables: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// Wrap returns a ToolAdder that resolves session variables before every tool
+// and resource handler and passes them on via types.ContextWithSessionVars.
+// Tool arguments named by SessionVar.Arg are added to each tool's schema.
+func (r *Resolver) Wrap(s internal_mcp.ToolAdder) internal_mcp.ToolAdder {
+	return &adder{next: s, r: r}
+}
+
+type adder struct {
+	next internal_mcp.ToolAdder
+	r    *Resolver
+}
+
+func (a *adder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	if err := a.r.addArgs(&tool); err != nil {
+		return err
+	}
+	return a.next.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		vars, err := a.r.Resolve(ctx, req.GetArguments())
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeUnauthorized, err.Error(), nil), nil
+		}
+		return handler(types.ContextWithSessionVars(ctx, vars), req)
+	})
+}
+
+func (a *adder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return a.next.AddResource(resource, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		vars, err := a.r.Resolve(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return handler(types.ContextWithSessionVars(ctx, vars), req)
+	})
+}
+
+// addArgs declares the session variable arguments on tool.
+func (r *Resolver) addArgs(tool *mcp.Tool) error {
+	props := make(map[string]any)
+	for _, sv := range r.vars {
+		if sv.Arg != "" {
+			props[sv.Arg] = map[string]any{
+				"type":        "string",
+				"description": "Sets session variable " + sv.Name + " for this call (must match the caller's value when one is set)",
+			}
+		}
+	}
+	if len(props) == 0 {
+		return nil
+	}
+
+	if tool.RawInputSchema == nil {
+		if tool.InputSchema.Properties == nil {
+			tool.InputSchema.Properties = make(map[string]any)
+		}
+		for k, v := range props {
+			tool.InputSchema.Properties[k] = v
+		}
+		return nil
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+		return fmt.Errorf("decode input schema of %s: %w", tool.Name, err)
+	}
+	existing, _ := schema["properties"].(map[string]any)
+	if existing == nil {
+		existing = make(map[string]any)
+	}
+	for k, v := range props {
+		existing[k] = v
+	}
+	schema["properties"] = existing
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("encode input schema of %s: %w", tool.Name, err)
+	}
+	tool.RawInputSchema = raw
+	return nil
+}
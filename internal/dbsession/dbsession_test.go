@@ -0,0 +1,115 @@
+package dbsession
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type providerFunc func(ctx context.Context) (map[string]string, error)
+
+func (f providerFunc) SessionVars(ctx context.Context) (map[string]string, error) { return f(ctx) }
+
+type mockToolAdder struct {
+	tool    mcp.Tool
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.tool = tool
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func TestResolve(t *testing.T) {
+	vars := []types.SessionVar{
+		{Name: "app.tenant_id", Arg: "tenant_id", Required: true},
+		{Name: "app.request_id", Arg: "request_id"},
+	}
+	principal := providerFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"app.tenant_id": "t1", "app.role": "viewer"}, nil
+	})
+
+	r := NewResolver(vars, principal)
+	got, err := r.Resolve(context.Background(), map[string]any{"request_id": "req-9", "tenant_id": "t1"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got["app.tenant_id"] != "t1" || got["app.role"] != "viewer" || got["app.request_id"] != "req-9" {
+		t.Errorf("Resolve() = %v", got)
+	}
+
+	if _, err := r.Resolve(context.Background(), map[string]any{"tenant_id": "t2"}); err == nil {
+		t.Error("argument overriding the principal's tenant should fail")
+	}
+
+	argsOnly := NewResolver(vars, nil)
+	if _, err := argsOnly.Resolve(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "app.tenant_id") {
+		t.Errorf("missing required var error = %v", err)
+	}
+	got, err = argsOnly.Resolve(context.Background(), map[string]any{"tenant_id": "t3"})
+	if err != nil || got["app.tenant_id"] != "t3" {
+		t.Errorf("Resolve(args) = %v, %v", got, err)
+	}
+
+	failing := NewResolver(nil, providerFunc(func(ctx context.Context) (map[string]string, error) {
+		return nil, errors.New("no principal")
+	}))
+	if _, err := failing.Resolve(context.Background(), nil); err == nil {
+		t.Error("provider error should propagate")
+	}
+
+	if NewResolver(nil, nil) != nil {
+		t.Error("NewResolver() without configuration should be nil")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	r := NewResolver([]types.SessionVar{{Name: "app.tenant_id", Arg: "tenant_id", Required: true}}, nil)
+	next := &mockToolAdder{}
+	s := r.Wrap(next)
+
+	type input struct {
+		Query string `json:"query"`
+	}
+	tool := mcp.NewTool("dbquery.run", mcp.WithInputSchema[input]())
+	var seen map[string]string
+	err := s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		seen = types.SessionVarsFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(next.tool.RawInputSchema, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+	props := schema["properties"].(map[string]any)
+	if props["query"] == nil || props["tenant_id"] == nil {
+		t.Errorf("schema properties = %v, want query and tenant_id", props)
+	}
+
+	req := mcp.CallToolRequest{}
+	result, _ := next.handler(context.Background(), req)
+	if !result.IsError || seen != nil {
+		t.Errorf("call without tenant should be refused before the handler runs")
+	}
+
+	req.Params.Arguments = map[string]any{"tenant_id": "t1"}
+	result, _ = next.handler(context.Background(), req)
+	if result.IsError || seen["app.tenant_id"] != "t1" {
+		t.Errorf("handler saw %v, result %+v", seen, result)
+	}
+}
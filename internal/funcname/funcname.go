@@ -0,0 +1,43 @@
+// Package funcname names handlers and middlewares for route listings.
+package funcname
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Of returns the symbol of a function value, such as
+// "github.com/acme/orders/api.(*Server).Create", or the dynamic type of any
+// other value, such as "*api.OrderHandler". Method values lose the -fm
+// suffix the runtime adds. It returns "" for nil.
+func Of(v any) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", v)
+	}
+	if rv.IsNil() {
+		return ""
+	}
+	fn := runtime.FuncForPC(rv.Pointer())
+	if fn == nil {
+		return fmt.Sprintf("%T", v)
+	}
+	return strings.TrimSuffix(fn.Name(), "-fm")
+}
+
+// All returns the names of vs, in order.
+func All[T any](vs ...T) []string {
+	if len(vs) == 0 {
+		return nil
+	}
+	out := make([]string, len(vs))
+	for i, v := range vs {
+		out[i] = Of(v)
+	}
+	return out
+}
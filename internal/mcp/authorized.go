@@ -53,9 +53,30 @@ func (s *AuthorizedServer) AddTool(tool mcp.Tool, handler ToolHandler) error {
 	return s.server.AddTool(tool, authorizedHandler)
 }
 
-// AddResource adds a resource to the underlying server.
+// AddResource adds a resource with authorization enforcement. Resources
+// without entries in security.ResourceScopes are not checked.
 func (s *AuthorizedServer) AddResource(resource mcp.Resource, handler ResourceHandler) error {
-	return s.server.AddResource(resource, handler)
+	return s.server.AddResource(resource, s.authorizeResource(resource.URI, handler))
+}
+
+// authorizeResource wraps handler with the scope checks for uri.
+func (s *AuthorizedServer) authorizeResource(uri string, handler ResourceHandler) ResourceHandler {
+	scopes := security.GetResourceScopes(uri)
+	if len(scopes) == 0 {
+		return handler
+	}
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		for _, scope := range scopes {
+			if !s.authorizer.HasScope(ctx, scope) {
+				s.logger.Debug("authorization denied", map[string]any{
+					"resource": uri,
+					"scope":    scope,
+				})
+				return nil, fmt.Errorf("insufficient scope for resource %s: requires %s", uri, scope)
+			}
+		}
+		return handler(ctx, req)
+	}
 }
 
 // Start starts the underlying server.
@@ -254,3 +254,37 @@ func TestToolError(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizedServer_AuthorizeResource(t *testing.T) {
+	logger := &mockLogger{}
+	called := false
+	handler := func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		called = true
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, Text: "erDiagram"}}, nil
+	}
+	read := func(s *AuthorizedServer, uri string) error {
+		_, err := s.authorizeResource(uri, handler)(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+		return err
+	}
+
+	denied := NewAuthorizedServer(nil, &mockAuthorizer{allowedScopes: map[string]bool{"dbschema.erd": true}}, logger)
+	if err := read(denied, "scg://db/erd"); err == nil || called {
+		t.Errorf("read without db.read: err = %v, handler called = %v", err, called)
+	}
+	if len(logger.debugCalls) != 1 || logger.debugCalls[0]["scope"] != "db.read" {
+		t.Errorf("debug calls = %v", logger.debugCalls)
+	}
+
+	allowed := NewAuthorizedServer(nil, &mockAuthorizer{allowedScopes: map[string]bool{"dbschema.erd": true, "db.read": true}}, logger)
+	if err := read(allowed, "scg://db/erd.dot"); err != nil || !called {
+		t.Errorf("read with scopes: err = %v, handler called = %v", err, called)
+	}
+
+	called = false
+	if err := read(denied, "scg://service/topology.mmd"); err == nil || called {
+		t.Errorf("topology without service.topology: err = %v, handler called = %v", err, called)
+	}
+	if err := read(denied, "scg://project/summary"); err != nil || !called {
+		t.Errorf("unscoped resource: err = %v, handler called = %v", err, called)
+	}
+}
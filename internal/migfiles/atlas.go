@@ -0,0 +1,52 @@
+package migfiles
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AtlasHashes returns the per-file hashes Atlas records in atlas.sum and in
+// atlas_schema_revisions.hash, keyed by file name. The values from atlas.sum
+// are used when present; otherwise they are computed the way Atlas does, as a
+// running SHA-256 over each file name and content.
+func AtlasHashes(dir string, files []File) (map[string]string, error) {
+	// #nosec G304 -- dir is the configured migrations directory.
+	raw, err := os.ReadFile(filepath.Join(dir, "atlas.sum"))
+	switch {
+	case err == nil:
+		return parseAtlasSum(raw), nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("read atlas.sum: %w", err)
+	}
+
+	out := make(map[string]string, len(files))
+	h := sha256.New()
+	for _, f := range files {
+		_, _ = h.Write([]byte(f.Path))
+		_, _ = h.Write(f.Raw)
+		out[f.Path] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return out, nil
+}
+
+// parseAtlasSum parses "<file> h1:<hash>" lines, skipping the leading total.
+func parseAtlasSum(raw []byte) map[string]string {
+	out := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "h1:") {
+			continue
+		}
+		out[fields[0]] = strings.TrimPrefix(fields[1], "h1:")
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+// Package migfiles reads SQL migration directories written for golang-migrate,
+// goose and Atlas, and splits migration bodies into individual statements.
+package migfiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Format identifies the migration tool a file was written for.
+type Format string
+
+const (
+	FormatGolangMigrate Format = "golang-migrate"
+	FormatGoose         Format = "goose"
+	FormatAtlas         Format = "atlas"
+	FormatPlain         Format = "plain"
+)
+
+// File is a single logical migration. For golang-migrate the up and down
+// files are merged into one File.
+type File struct {
+	Version  string `json:"version"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	DownPath string `json:"down_path,omitempty"`
+	Format   Format `json:"format"`
+
+	// Up and Down hold the SQL of each direction. UpLine and DownLine are the
+	// 1-based line numbers where each section starts in its source file.
+	Up       string `json:"-"`
+	Down     string `json:"-"`
+	UpLine   int    `json:"-"`
+	DownLine int    `json:"-"`
+	HasDown  bool   `json:"has_down"`
+
+	// Checksum is the hex SHA-256 of the raw up file contents.
+	Checksum string `json:"checksum"`
+	// Raw is the unmodified content of the up file.
+	Raw []byte `json:"-"`
+}
+
+var (
+	versionRe   = regexp.MustCompile(`^(\d+)_?(.*)$`)
+	gooseUpRe   = regexp.MustCompile(`(?im)^\s*--\s*\+goose\s+up\b.*$`)
+	gooseDownRe = regexp.MustCompile(`(?im)^\s*--\s*\+goose\s+down\b.*$`)
+)
+
+// Load reads every *.sql file in dir (non-recursive) and returns the
+// migrations sorted by version. Files without a numeric version prefix are
+// ignored.
+func Load(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir %s: %w", dir, err)
+	}
+
+	atlas := false
+	for _, e := range entries {
+		if e.Name() == "atlas.sum" {
+			atlas = true
+		}
+	}
+
+	byVersion := make(map[string]*File)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		direction := ""
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			direction, base = "up", strings.TrimSuffix(base, ".up")
+		case strings.HasSuffix(base, ".down"):
+			direction, base = "down", strings.TrimSuffix(base, ".down")
+		}
+		m := versionRe.FindStringSubmatch(base)
+		if m == nil {
+			continue
+		}
+
+		// #nosec G304 -- path is built from a directory listing of dir.
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+
+		f := byVersion[m[1]]
+		if f == nil {
+			f = &File{Version: m[1], Name: m[2]}
+			byVersion[m[1]] = f
+		}
+
+		switch direction {
+		case "up":
+			f.Format = FormatGolangMigrate
+			f.Path = e.Name()
+			f.Up, f.UpLine = string(raw), 1
+			f.Raw = raw
+		case "down":
+			f.Format = FormatGolangMigrate
+			f.DownPath = e.Name()
+			f.Down, f.DownLine = string(raw), 1
+			f.HasDown = true
+		default:
+			f.Path = e.Name()
+			f.Raw = raw
+			splitSingleFile(f, string(raw), atlas)
+		}
+	}
+
+	files := make([]File, 0, len(byVersion))
+	for _, f := range byVersion {
+		if f.Path == "" {
+			// A down file without its up counterpart is not a migration.
+			continue
+		}
+		sum := sha256.Sum256(f.Raw)
+		f.Checksum = hex.EncodeToString(sum[:])
+		files = append(files, *f)
+	}
+	sort.Slice(files, func(i, j int) bool { return lessVersion(files[i].Version, files[j].Version) })
+	return files, nil
+}
+
+// splitSingleFile fills the up/down sections of a single-file migration.
+func splitSingleFile(f *File, content string, atlas bool) {
+	up := gooseUpRe.FindStringIndex(content)
+	if up == nil {
+		f.Format = FormatPlain
+		if atlas {
+			f.Format = FormatAtlas
+		}
+		f.Up, f.UpLine = content, 1
+		return
+	}
+
+	f.Format = FormatGoose
+	rest := content[up[1]:]
+	f.UpLine = lineAt(content, up[1])
+	if down := gooseDownRe.FindStringIndex(rest); down != nil {
+		f.Up = rest[:down[0]]
+		f.Down = rest[down[1]:]
+		f.DownLine = lineAt(content, up[1]+down[1])
+		f.HasDown = strings.TrimSpace(StripComments(f.Down)) != ""
+		return
+	}
+	f.Up = rest
+}
+
+// lineAt returns the 1-based line number of byte offset off.
+func lineAt(s string, off int) int {
+	return strings.Count(s[:off], "\n") + 1
+}
+
+// lessVersion compares numeric version strings without overflowing on long
+// timestamps.
+func lessVersion(a, b string) bool {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// CompareVersions orders two numeric migration versions. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	switch {
+	case lessVersion(a, b):
+		return -1
+	case lessVersion(b, a):
+		return 1
+	default:
+		return 0
+	}
+}
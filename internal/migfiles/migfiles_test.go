@@ -0,0 +1,69 @@
+package migfiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	sql := `-- header comment
+CREATE TABLE a (id int); /* block
+comment */ INSERT INTO a VALUES (';');
+
+CREATE FUNCTION f() RETURNS void AS $body$
+BEGIN
+  PERFORM 1;
+END;
+$body$ LANGUAGE plpgsql;
+`
+	got := Split(sql, 1)
+	if len(got) != 3 {
+		t.Fatalf("len(Split) = %d, want 3: %#v", len(got), got)
+	}
+	wantLines := []int{2, 3, 5}
+	for i, st := range got {
+		if st.Line != wantLines[i] {
+			t.Errorf("statement %d line = %d, want %d (%q)", i, st.Line, wantLines[i], st.SQL)
+		}
+	}
+	if got[1].SQL != "INSERT INTO a VALUES (';')" {
+		t.Errorf("statement 1 = %q", got[1].SQL)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20240101000000_init.up.sql":   "CREATE TABLE a (id int);",
+		"20240101000000_init.down.sql": "DROP TABLE a;",
+		"20240102000000_goose.sql":     "-- +goose Up\nCREATE TABLE b (id int);\n-- +goose Down\nDROP TABLE b;\n",
+		"20240103000000_plain.sql":     "CREATE TABLE c (id int);",
+		"README.md":                    "ignored",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("len(Load) = %d, want 3", len(got))
+	}
+	if got[0].Format != FormatGolangMigrate || !got[0].HasDown || got[0].Name != "init" {
+		t.Errorf("file 0 = %+v", got[0])
+	}
+	if got[1].Format != FormatGoose || !got[1].HasDown || got[1].UpLine != 1 || got[1].DownLine != 3 {
+		t.Errorf("file 1 = %+v", got[1])
+	}
+	if got[2].Format != FormatPlain || got[2].HasDown {
+		t.Errorf("file 2 = %+v", got[2])
+	}
+	if got[0].Checksum == "" {
+		t.Error("expected checksum")
+	}
+}
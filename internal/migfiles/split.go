@@ -0,0 +1,144 @@
+package migfiles
+
+import (
+	"strings"
+)
+
+// Statement is a single SQL statement with comments removed.
+type Statement struct {
+	SQL  string `json:"sql"`
+	Line int    `json:"line"`
+}
+
+// Split breaks sql into statements on top-level semicolons. Quoted strings,
+// quoted identifiers, dollar-quoted bodies and comments are honoured. Line
+// numbers are offset by startLine, which is the line sql begins at in its file.
+func Split(sql string, startLine int) []Statement {
+	if startLine < 1 {
+		startLine = 1
+	}
+
+	var (
+		out       []Statement
+		cur       strings.Builder
+		line      = startLine
+		stmtLine  = 0
+		dollarTag string
+	)
+
+	flush := func() {
+		text := strings.TrimSpace(cur.String())
+		if text != "" {
+			out = append(out, Statement{SQL: text, Line: stmtLine})
+		}
+		cur.Reset()
+		stmtLine = 0
+	}
+	mark := func() {
+		if stmtLine == 0 {
+			stmtLine = line
+		}
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		if dollarTag != "" {
+			if strings.HasPrefix(sql[i:], dollarTag) {
+				cur.WriteString(dollarTag)
+				i += len(dollarTag) - 1
+				dollarTag = ""
+				continue
+			}
+			if c == '\n' {
+				line++
+			}
+			cur.WriteByte(c)
+			continue
+		}
+
+		switch {
+		case c == '\n':
+			line++
+			cur.WriteByte(c)
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			if i < len(sql) {
+				line++
+				cur.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+			line += strings.Count(sql[i:i+2+end], "\n")
+			i += end + 3
+			cur.WriteByte(' ')
+		case c == '\'' || c == '"':
+			mark()
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(sql) {
+				j = len(sql) - 1
+			}
+			chunk := sql[i : j+1]
+			line += strings.Count(chunk, "\n")
+			cur.WriteString(chunk)
+			i = j
+		case c == '$':
+			mark()
+			if tag := dollarQuoteTag(sql[i:]); tag != "" {
+				dollarTag = tag
+				cur.WriteString(tag)
+				i += len(tag) - 1
+				continue
+			}
+			cur.WriteByte(c)
+		case c == ';':
+			flush()
+		default:
+			if c != ' ' && c != '\t' && c != '\r' {
+				mark()
+			}
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return out
+}
+
+// dollarQuoteTag returns the opening tag ($$ or $name$) at the start of s.
+func dollarQuoteTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		if c == '$' {
+			return s[:j+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+// StripComments removes SQL line and block comments from sql.
+func StripComments(sql string) string {
+	var b strings.Builder
+	for _, st := range Split(sql, 1) {
+		b.WriteString(st.SQL)
+		b.WriteString(";\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,280 @@
+// Package miglint flags dangerous operations in SQL migration files, such as
+// blocking index builds, table rewrites and breaking column changes.
+package miglint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/next-trace/scg-boost/internal/migfiles"
+)
+
+// Severity levels, matching the strings used by types.EnvIssue.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Rule identifiers.
+const (
+	RuleIndexNotConcurrent    = "index-not-concurrent"
+	RuleNotNullWithoutDefault = "not-null-without-default"
+	RuleDropColumn            = "drop-column"
+	RuleDropTable             = "drop-table"
+	RuleRenameColumn          = "rename-column"
+	RuleRenameTable           = "rename-table"
+	RuleTypeChange            = "type-change"
+	RuleSetNotNull            = "set-not-null"
+	RuleConstraintNotValid    = "constraint-not-valid"
+	RuleKeyWithoutIndex       = "key-without-index"
+	RuleMissingDown           = "missing-down"
+)
+
+// Finding is a single lint result.
+type Finding struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Statement string `json:"statement,omitempty"`
+}
+
+// maxStatementLen bounds the statement excerpt attached to findings.
+const maxStatementLen = 200
+
+var (
+	createTableRe  = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:TEMP(?:ORARY)?\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)`)
+	createIndexRe  = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?.*?\bON\s+(?:ONLY\s+)?([\w."]+)`)
+	dropIndexRe    = regexp.MustCompile(`(?is)^DROP\s+INDEX\s+(CONCURRENTLY\s+)?`)
+	dropTableRe    = regexp.MustCompile(`(?is)^DROP\s+TABLE\b`)
+	alterTableRe   = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."]+)\s+(.*)$`)
+	addColumnRe    = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?([\w"]+)\s+(.*)$`)
+	dropColumnRe   = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?([\w"]+)`)
+	alterTypeRe    = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?([\w"]+)\s+(?:SET\s+DATA\s+)?TYPE\s+(.+)$`)
+	setNotNullRe   = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?([\w"]+)\s+SET\s+NOT\s+NULL`)
+	renameColumnRe = regexp.MustCompile(`(?is)^RENAME\s+(?:COLUMN\s+)?([\w"]+)\s+TO\s+([\w"]+)`)
+	renameTableRe  = regexp.MustCompile(`(?is)^RENAME\s+TO\s+([\w."]+)`)
+	addConstraint  = regexp.MustCompile(`(?is)^ADD\s+(?:CONSTRAINT\s+[\w"]+\s+)?(FOREIGN\s+KEY|CHECK|PRIMARY\s+KEY|UNIQUE)\b`)
+	notValidRe     = regexp.MustCompile(`(?i)\bNOT\s+VALID\b`)
+	usingIndexRe   = regexp.MustCompile(`(?i)\bUSING\s+INDEX\b`)
+	notNullRe      = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultRe      = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	generatedRe    = regexp.MustCompile(`(?i)\bGENERATED\b`)
+)
+
+// LintDir lints every migration in dir.
+func LintDir(dir string) ([]Finding, error) {
+	files, err := migfiles.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, f := range files {
+		out = append(out, LintFile(f)...)
+	}
+	return out, nil
+}
+
+// LintFile lints the up section of a single migration.
+func LintFile(f migfiles.File) []Finding {
+	var out []Finding
+	add := func(line int, rule, severity, msg, stmt string) {
+		out = append(out, Finding{File: f.Path, Line: line, Rule: rule, Severity: severity, Message: msg, Statement: excerpt(stmt)})
+	}
+
+	// Atlas and plain SQL migrations have no down convention.
+	if !f.HasDown && (f.Format == migfiles.FormatGolangMigrate || f.Format == migfiles.FormatGoose) {
+		add(1, RuleMissingDown, SeverityWarning, "migration has no down migration; it cannot be rolled back", "")
+	}
+
+	// Tables created in this migration are empty, so rewrites and locks on
+	// them are harmless.
+	created := make(map[string]bool)
+
+	for _, st := range migfiles.Split(f.Up, f.UpLine) {
+		sql := st.SQL
+		switch {
+		case createTableRe.MatchString(sql):
+			created[tableKey(createTableRe.FindStringSubmatch(sql)[1])] = true
+
+		case createIndexRe.MatchString(sql):
+			m := createIndexRe.FindStringSubmatch(sql)
+			if m[1] == "" && !created[tableKey(m[2])] {
+				add(st.Line, RuleIndexNotConcurrent, SeverityError,
+					"CREATE INDEX without CONCURRENTLY blocks writes to "+m[2]+" for the whole build; use CREATE INDEX CONCURRENTLY outside a transaction", sql)
+			}
+
+		case dropIndexRe.MatchString(sql):
+			if dropIndexRe.FindStringSubmatch(sql)[1] == "" {
+				add(st.Line, RuleIndexNotConcurrent, SeverityWarning,
+					"DROP INDEX without CONCURRENTLY takes an ACCESS EXCLUSIVE lock on the table", sql)
+			}
+
+		case dropTableRe.MatchString(sql):
+			add(st.Line, RuleDropTable, SeverityError,
+				"DROP TABLE is irreversible and breaks running code that still reads the table", sql)
+
+		case alterTableRe.MatchString(sql):
+			m := alterTableRe.FindStringSubmatch(sql)
+			table := m[1]
+			isNew := created[tableKey(table)]
+			for _, action := range splitActions(m[2]) {
+				for _, fd := range lintAlterAction(table, action, isNew) {
+					add(st.Line, fd.Rule, fd.Severity, fd.Message, sql)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func lintAlterAction(table, action string, isNew bool) []Finding {
+	var out []Finding
+	add := func(rule, severity, msg string) {
+		out = append(out, Finding{Rule: rule, Severity: severity, Message: msg})
+	}
+
+	switch {
+	case addConstraint.MatchString(action):
+		if isNew {
+			return nil
+		}
+		kind := strings.ToUpper(strings.Join(strings.Fields(addConstraint.FindStringSubmatch(action)[1]), " "))
+		switch kind {
+		case "FOREIGN KEY", "CHECK":
+			if !notValidRe.MatchString(action) {
+				add(RuleConstraintNotValid, SeverityWarning,
+					"ADD "+kind+" on "+table+" scans the whole table under lock; add it NOT VALID and VALIDATE CONSTRAINT separately")
+			}
+		default:
+			if !usingIndexRe.MatchString(action) {
+				add(RuleKeyWithoutIndex, SeverityWarning,
+					"ADD "+kind+" on "+table+" builds an index while blocking writes; build it CONCURRENTLY first and attach it with USING INDEX")
+			}
+		}
+
+	case addColumnRe.MatchString(action):
+		m := addColumnRe.FindStringSubmatch(action)
+		def := m[2]
+		if !isNew && notNullRe.MatchString(def) && !defaultRe.MatchString(def) && !generatedRe.MatchString(def) {
+			add(RuleNotNullWithoutDefault, SeverityError,
+				"adding NOT NULL column "+m[1]+" to "+table+" without a DEFAULT fails on non-empty tables")
+		}
+
+	case dropColumnRe.MatchString(action) && !strings.HasPrefix(strings.ToUpper(action), "DROP CONSTRAINT") &&
+		!strings.HasPrefix(strings.ToUpper(action), "DROP DEFAULT") && !strings.HasPrefix(strings.ToUpper(action), "DROP NOT NULL"):
+		m := dropColumnRe.FindStringSubmatch(action)
+		add(RuleDropColumn, SeverityError,
+			"dropping column "+m[1]+" from "+table+" breaks running code that still reads it; stop using it in a prior release")
+
+	case renameTableRe.MatchString(action):
+		add(RuleRenameTable, SeverityError,
+			"renaming table "+table+" breaks running code; create a view or migrate readers first")
+
+	case renameColumnRe.MatchString(action):
+		m := renameColumnRe.FindStringSubmatch(action)
+		add(RuleRenameColumn, SeverityError,
+			"renaming column "+m[1]+" to "+m[2]+" on "+table+" breaks running code; add the new column and backfill instead")
+
+	case setNotNullRe.MatchString(action):
+		if !isNew {
+			m := setNotNullRe.FindStringSubmatch(action)
+			add(RuleSetNotNull, SeverityWarning,
+				"SET NOT NULL on "+table+"."+m[1]+" scans the whole table under an ACCESS EXCLUSIVE lock; add a NOT VALID CHECK constraint and validate it first")
+		}
+
+	case alterTypeRe.MatchString(action):
+		if !isNew {
+			m := alterTypeRe.FindStringSubmatch(action)
+			add(RuleTypeChange, SeverityWarning,
+				"changing the type of "+table+"."+m[1]+" to "+strings.TrimSpace(m[2])+" may rewrite the table under an ACCESS EXCLUSIVE lock")
+		}
+	}
+	return out
+}
+
+// Summary counts findings by severity.
+func Summary(findings []Finding) map[string]int {
+	out := map[string]int{SeverityError: 0, SeverityWarning: 0, SeverityInfo: 0}
+	for _, f := range findings {
+		out[f.Severity]++
+	}
+	return out
+}
+
+// AtLeast filters findings to those at or above minSeverity.
+func AtLeast(findings []Finding, minSeverity string) []Finding {
+	rank := map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityError: 2}
+	min, ok := rank[minSeverity]
+	if !ok {
+		return findings
+	}
+	var out []Finding
+	for _, f := range findings {
+		if rank[f.Severity] >= min {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Sort orders findings by file and line.
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// splitActions splits the action list of an ALTER TABLE on top-level commas.
+func splitActions(s string) []string {
+	var (
+		out   []string
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			out = append(out, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		out = append(out, last)
+	}
+	return out
+}
+
+func tableKey(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, `"`, ""))
+	if !strings.Contains(name, ".") {
+		name = "public." + name
+	}
+	return name
+}
+
+func excerpt(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxStatementLen {
+		return sql[:maxStatementLen] + "…"
+	}
+	return sql
+}
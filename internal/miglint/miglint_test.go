@@ -0,0 +1,112 @@
+package miglint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/next-trace/scg-boost/internal/migfiles"
+)
+
+func lint(t *testing.T, up string) []Finding {
+	t.Helper()
+	return LintFile(migfiles.File{Path: "m.sql", Format: migfiles.FormatGoose, HasDown: true, Up: up, UpLine: 1})
+}
+
+func rules(findings []Finding) map[string]int {
+	out := make(map[string]int)
+	for _, f := range findings {
+		out[f.Rule]++
+	}
+	return out
+}
+
+func TestLintFile_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"blocking index", "CREATE INDEX idx_a ON users (email);", RuleIndexNotConcurrent},
+		{"drop index", "DROP INDEX idx_a;", RuleIndexNotConcurrent},
+		{"not null without default", "ALTER TABLE users ADD COLUMN age int NOT NULL;", RuleNotNullWithoutDefault},
+		{"drop column", "ALTER TABLE users DROP COLUMN age;", RuleDropColumn},
+		{"drop table", "DROP TABLE users;", RuleDropTable},
+		{"rename column", "ALTER TABLE users RENAME COLUMN a TO b;", RuleRenameColumn},
+		{"rename table", "ALTER TABLE users RENAME TO people;", RuleRenameTable},
+		{"type change", "ALTER TABLE users ALTER COLUMN id TYPE bigint;", RuleTypeChange},
+		{"set not null", "ALTER TABLE users ALTER COLUMN email SET NOT NULL;", RuleSetNotNull},
+		{"foreign key", "ALTER TABLE orders ADD CONSTRAINT fk FOREIGN KEY (user_id) REFERENCES users(id);", RuleConstraintNotValid},
+		{"unique", "ALTER TABLE users ADD CONSTRAINT u UNIQUE (email);", RuleKeyWithoutIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lint(t, tt.sql)
+			if len(got) != 1 || got[0].Rule != tt.want {
+				t.Fatalf("LintFile(%q) = %+v, want one %s finding", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintFile_Safe(t *testing.T) {
+	safe := []string{
+		"CREATE INDEX CONCURRENTLY idx_a ON users (email);",
+		"DROP INDEX CONCURRENTLY idx_a;",
+		"ALTER TABLE users ADD COLUMN age int NOT NULL DEFAULT 0;",
+		"ALTER TABLE users ADD COLUMN nickname text;",
+		"ALTER TABLE users ALTER COLUMN email DROP NOT NULL;",
+		"ALTER TABLE users DROP CONSTRAINT users_email_key;",
+		"ALTER TABLE orders ADD CONSTRAINT fk FOREIGN KEY (user_id) REFERENCES users(id) NOT VALID;",
+		"ALTER TABLE users ADD CONSTRAINT u UNIQUE USING INDEX users_email_idx;",
+		// Everything is fine on a table created in the same migration.
+		"CREATE TABLE t (id int); CREATE INDEX t_id ON t (id); ALTER TABLE t ADD COLUMN x int NOT NULL, ALTER COLUMN id TYPE bigint;",
+	}
+	for _, sql := range safe {
+		if got := lint(t, sql); len(got) != 0 {
+			t.Errorf("LintFile(%q) = %+v, want no findings", sql, got)
+		}
+	}
+}
+
+func TestLintFile_MultipleActionsAndLines(t *testing.T) {
+	got := lint(t, "SELECT 1;\n\nALTER TABLE users DROP COLUMN a, ADD COLUMN b int NOT NULL;")
+	r := rules(got)
+	if r[RuleDropColumn] != 1 || r[RuleNotNullWithoutDefault] != 1 {
+		t.Fatalf("rules = %v", r)
+	}
+	for _, f := range got {
+		if f.Line != 3 {
+			t.Errorf("finding line = %d, want 3", f.Line)
+		}
+	}
+}
+
+func TestLintDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"1_init.up.sql":   "CREATE TABLE users (id int);",
+		"1_init.down.sql": "DROP TABLE users;",
+		"2_idx.up.sql":    "CREATE INDEX idx ON users (id);",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := LintDir(dir)
+	if err != nil {
+		t.Fatalf("LintDir() error = %v", err)
+	}
+	r := rules(got)
+	if len(got) != 2 || r[RuleIndexNotConcurrent] != 1 || r[RuleMissingDown] != 1 {
+		t.Fatalf("LintDir() = %+v", got)
+	}
+	if s := Summary(got); s[SeverityError] != 1 || s[SeverityWarning] != 1 {
+		t.Errorf("Summary() = %v", s)
+	}
+	if len(AtLeast(got, SeverityError)) != 1 {
+		t.Errorf("AtLeast(error) = %+v", AtLeast(got, SeverityError))
+	}
+}
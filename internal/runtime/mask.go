@@ -0,0 +1,78 @@
+package runtime
+
+import (
+	"path"
+	"strings"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// MaskedValue replaces the value of masked columns in query results.
+const MaskedValue = "***"
+
+// Masker hides values of sensitive columns. Patterns are case-insensitive
+// path.Match globs against column names, e.g. "password", "*_token", "ssn".
+// A nil Masker masks nothing.
+type Masker struct {
+	patterns []string
+}
+
+// NewMasker returns a Masker for the given patterns, or nil when there are
+// none.
+func NewMasker(patterns []string) *Masker {
+	var ps []string
+	for _, p := range patterns {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	if len(ps) == 0 {
+		return nil
+	}
+	return &Masker{patterns: ps}
+}
+
+// Masked reports whether column matches a mask pattern.
+func (m *Masker) Masked(column string) bool {
+	if m == nil {
+		return false
+	}
+	column = strings.ToLower(column)
+	for _, p := range m.patterns {
+		if ok, _ := path.Match(p, column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskRows replaces non-null values of masked columns in place.
+func (m *Masker) MaskRows(rows []map[string]any) {
+	if m == nil {
+		return
+	}
+	for _, row := range rows {
+		for k, v := range row {
+			if v != nil && m.Masked(k) {
+				row[k] = MaskedValue
+			}
+		}
+	}
+}
+
+// MaskResult replaces non-null values of masked columns in place.
+func (m *Masker) MaskResult(res *types.QueryResult) {
+	if m == nil || res == nil {
+		return
+	}
+	for i, c := range res.Columns {
+		if !m.Masked(c.Name) {
+			continue
+		}
+		for _, row := range res.Rows {
+			if i < len(row) && row[i] != nil {
+				row[i] = MaskedValue
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package runtime
+
+import "testing"
+
+func TestMasker(t *testing.T) {
+	m := NewMasker([]string{"password", "*_TOKEN", " "})
+	if !m.Masked("Password") || !m.Masked("api_token") || m.Masked("email") {
+		t.Errorf("unexpected Masked() results")
+	}
+
+	rows := []map[string]any{{"email": "a@b.c", "password": "secret", "api_token": nil}}
+	m.MaskRows(rows)
+	if rows[0]["password"] != MaskedValue || rows[0]["email"] != "a@b.c" || rows[0]["api_token"] != nil {
+		t.Errorf("MaskRows() = %v", rows[0])
+	}
+
+	if NewMasker(nil) != nil {
+		t.Error("NewMasker(nil) should return nil")
+	}
+	var none *Masker
+	none.MaskRows(rows)
+	if none.Masked("password") {
+		t.Error("nil Masker should mask nothing")
+	}
+}
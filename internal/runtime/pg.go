@@ -2,23 +2,91 @@ package runtime
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/next-trace/scg-boost/types"
 )
 
 type ReadOnlyDB struct{ DB *sqlx.DB }
 
+// OpenPostgres connects to a Postgres database and verifies the connection.
+func OpenPostgres(ctx context.Context, dsn string) (*ReadOnlyDB, error) {
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+	return &ReadOnlyDB{DB: db}, nil
+}
+
+// Close releases the underlying connection pool.
+func (r *ReadOnlyDB) Close() error {
+	return r.DB.Close()
+}
+
+// AppliesSessionVars implements types.SessionVarsApplier.
+func (r *ReadOnlyDB) AppliesSessionVars() bool { return true }
+
+// conn returns the queryer for a call. When ctx carries session variables it
+// opens a read-only transaction and applies them with set_config(name, value,
+// true), the bindable form of SET LOCAL; done rolls the transaction back.
+func (r *ReadOnlyDB) conn(ctx context.Context) (sqlx.QueryerContext, func(), error) {
+	vars := types.SessionVarsFromContext(ctx)
+	if len(vars) == 0 {
+		return r.DB, func() {}, nil
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin session transaction: %w", err)
+	}
+	done := func() { _ = tx.Rollback() }
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", name, vars[name]); err != nil {
+			done()
+			return nil, nil, fmt.Errorf("set session variable %s: %w", name, err)
+		}
+	}
+	return tx, done, nil
+}
+
+// bind converts a query with :name parameters into a Postgres $n query.
+func bind(query string, params map[string]any) (string, []any, error) {
+	namedQuery, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return "", nil, fmt.Errorf("bind params: %w", err)
+	}
+	return sqlx.Rebind(sqlx.DOLLAR, namedQuery), args, nil
+}
+
 func (r *ReadOnlyDB) QueryJSON(ctx context.Context, query string, params map[string]any) (_ []map[string]any, retErr error) {
 	// Use sqlx.Named to bind params, then Rebind for PostgreSQL
-	namedQuery, args, err := sqlx.Named(query, params)
+	reboundQuery, args, err := bind(query, params)
 	if err != nil {
-		return nil, fmt.Errorf("bind params: %w", err)
+		return nil, err
+	}
+
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
 	}
-	reboundQuery := sqlx.Rebind(sqlx.DOLLAR, namedQuery)
+	defer done()
 
-	rows, err := r.DB.QueryxContext(ctx, reboundQuery, args...)
+	rows, err := qr.QueryxContext(ctx, reboundQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,12 +107,63 @@ func (r *ReadOnlyDB) QueryJSON(ctx context.Context, query string, params map[str
 	return out, rows.Err()
 }
 
+// QueryTyped implements types.TypedQuerier.
+func (r *ReadOnlyDB) QueryTyped(ctx context.Context, query string, params map[string]any) (_ *types.QueryResult, retErr error) {
+	reboundQuery, args, err := bind(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryxContext(ctx, reboundQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && retErr == nil {
+			retErr = cerr
+		}
+	}()
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("column types: %w", err)
+	}
+	res := &types.QueryResult{Columns: make([]types.ColumnInfo, len(colTypes)), Rows: [][]any{}}
+	for i, ct := range colTypes {
+		res.Columns[i] = types.ColumnInfo{Name: ct.Name(), Type: ct.DatabaseTypeName()}
+	}
+
+	for rows.Next() {
+		row, err := rows.SliceScan()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range row {
+			row[i] = NormalizeValue(res.Columns[i].Type, v)
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
+
 // Schemas returns a list of all non-system schemas.
 func (r *ReadOnlyDB) Schemas(ctx context.Context) (_ []string, retErr error) {
 	q := `SELECT schema_name FROM information_schema.schemata 
           WHERE schema_name NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
           ORDER BY schema_name;`
-	rows, err := r.DB.QueryContext(ctx, q)
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryContext(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("query schemas: %w", err)
 	}
@@ -73,7 +192,13 @@ func (r *ReadOnlyDB) Tables(ctx context.Context, schema string) (_ []string, ret
 	q := `SELECT table_name FROM information_schema.tables 
           WHERE table_schema = $1 
           ORDER BY table_name;`
-	rows, err := r.DB.QueryContext(ctx, q, schema)
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryContext(ctx, q, schema)
 	if err != nil {
 		return nil, fmt.Errorf("query tables for schema %q: %w", schema, err)
 	}
@@ -103,7 +228,13 @@ func (r *ReadOnlyDB) Columns(ctx context.Context, schema, table string) (_ []map
           FROM information_schema.columns
           WHERE table_schema = $1 AND table_name = $2
           ORDER BY ordinal_position;`
-	rows, err := r.DB.QueryContext(ctx, q, schema, table)
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryContext(ctx, q, schema, table)
 	if err != nil {
 		return nil, fmt.Errorf("query columns for %q.%q: %w", schema, table, err)
 	}
@@ -131,3 +262,123 @@ func (r *ReadOnlyDB) Columns(ctx context.Context, schema, table string) (_ []map
 	}
 	return cols, nil
 }
+
+// Indexes returns the indexes defined on a given table.
+func (r *ReadOnlyDB) Indexes(ctx context.Context, schema, table string) (_ []types.IndexInfo, retErr error) {
+	q := `SELECT indexname, indexdef FROM pg_indexes
+          WHERE schemaname = $1 AND tablename = $2
+          ORDER BY indexname;`
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryContext(ctx, q, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("query indexes for %q.%q: %w", schema, table, err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && retErr == nil {
+			retErr = cerr
+		}
+	}()
+
+	var idx []types.IndexInfo
+	for rows.Next() {
+		var name, def string
+		if err := rows.Scan(&name, &def); err != nil {
+			return nil, fmt.Errorf("scan index: %w", err)
+		}
+		idx = append(idx, types.IndexInfo{
+			Name:       name,
+			Columns:    indexColumns(def),
+			Unique:     strings.HasPrefix(strings.ToUpper(def), "CREATE UNIQUE INDEX"),
+			Definition: def,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate indexes: %w", err)
+	}
+	return idx, nil
+}
+
+// indexColumns extracts the key columns from a pg_get_indexdef definition,
+// e.g. "CREATE INDEX i ON public.t USING btree (a, lower(b))" -> [a lower(b)].
+func indexColumns(def string) []string {
+	open := strings.Index(def, " (")
+	if open < 0 {
+		return nil
+	}
+	depth := 0
+	start := open + 2
+	var cols []string
+	for i := start; i < len(def); i++ {
+		switch def[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				if c := strings.TrimSpace(def[start:i]); c != "" {
+					cols = append(cols, c)
+				}
+				return cols
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				cols = append(cols, strings.TrimSpace(def[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return cols
+}
+
+// ForeignKeys returns the foreign keys defined on a given table.
+func (r *ReadOnlyDB) ForeignKeys(ctx context.Context, schema, table string) (_ []types.ForeignKeyInfo, retErr error) {
+	q := `SELECT c.conname, a.attname, rn.nspname, rc.relname, ra.attname
+          FROM pg_constraint c
+          JOIN pg_class cl ON cl.oid = c.conrelid
+          JOIN pg_namespace n ON n.oid = cl.relnamespace
+          JOIN pg_class rc ON rc.oid = c.confrelid
+          JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+          CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refnum, ord)
+          JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+          JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refnum
+          WHERE c.contype = 'f' AND n.nspname = $1 AND cl.relname = $2
+          ORDER BY c.conname, k.ord;`
+	qr, done, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := qr.QueryContext(ctx, q, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("query foreign keys for %q.%q: %w", schema, table, err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && retErr == nil {
+			retErr = cerr
+		}
+	}()
+
+	var fks []types.ForeignKeyInfo
+	for rows.Next() {
+		var name, col, refSchema, refTable, refCol string
+		if err := rows.Scan(&name, &col, &refSchema, &refTable, &refCol); err != nil {
+			return nil, fmt.Errorf("scan foreign key: %w", err)
+		}
+		if n := len(fks); n == 0 || fks[n-1].Name != name {
+			fks = append(fks, types.ForeignKeyInfo{Name: name, RefSchema: refSchema, RefTable: refTable})
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, col)
+		fk.RefColumns = append(fk.RefColumns, refCol)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate foreign keys: %w", err)
+	}
+	return fks, nil
+}
@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// NormalizeValue converts a value scanned by lib/pq into a JSON-friendly form
+// based on its Postgres type name (as reported by DatabaseTypeName):
+// timestamps become RFC 3339 strings, dates "2006-01-02", numerics
+// json.Number, JSON documents decoded values, bytea "\x"-prefixed hex, and
+// other byte slices strings. NaN and ±Infinity, which JSON numbers cannot
+// hold, become the strings "NaN", "Infinity" and "-Infinity".
+func NormalizeValue(dbType string, v any) any {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		switch strings.ToUpper(dbType) {
+		case "DATE":
+			return x.Format(time.DateOnly)
+		case "TIMESTAMP":
+			// No time zone in the column; keep the wall clock as stored.
+			return x.Format("2006-01-02T15:04:05.999999999")
+		default:
+			return x.Format(time.RFC3339Nano)
+		}
+	case []byte:
+		return normalizeBytes(strings.ToUpper(dbType), x)
+	case float64:
+		return normalizeFloat(x)
+	case float32:
+		if f := float64(x); math.IsNaN(f) || math.IsInf(f, 0) {
+			return normalizeFloat(f)
+		}
+		return x
+	default:
+		return v
+	}
+}
+
+// normalizeFloat returns non-finite floats, as lib/pq decodes FLOAT4 and
+// FLOAT8 'NaN' and 'Infinity', as strings.
+func normalizeFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return f
+	}
+}
+
+func normalizeBytes(dbType string, b []byte) any {
+	switch dbType {
+	case "BYTEA":
+		return `\x` + hex.EncodeToString(b)
+	case "NUMERIC", "DECIMAL":
+		// NaN and ±Infinity are valid numerics but not JSON numbers.
+		switch s := string(b); s {
+		case "NaN", "Infinity", "-Infinity":
+			return s
+		default:
+			return json.Number(s)
+		}
+	case "JSON", "JSONB":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var out any
+		if err := dec.Decode(&out); err == nil {
+			return out
+		}
+		return string(b)
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return `\x` + hex.EncodeToString(b)
+}
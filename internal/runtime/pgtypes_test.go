@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		dbType string
+		in     any
+		want   any
+	}{
+		{"TIMESTAMPTZ", ts, "2024-03-01T12:30:00Z"},
+		{"TIMESTAMP", ts, "2024-03-01T12:30:00"},
+		{"DATE", ts, "2024-03-01"},
+		{"NUMERIC", []byte("12.50"), json.Number("12.50")},
+		{"NUMERIC", []byte("NaN"), "NaN"},
+		{"NUMERIC", []byte("-Infinity"), "-Infinity"},
+		{"UUID", []byte("3f1c2d4e-0000-4000-8000-000000000001"), "3f1c2d4e-0000-4000-8000-000000000001"},
+		{"TEXT", []byte("héllo"), "héllo"},
+		{"BYTEA", []byte{0xde, 0xad}, `\xdead`},
+		{"JSONB", []byte(`{"a":[1,"x"]}`), map[string]any{"a": []any{json.Number("1"), "x"}}},
+		{"INT8", int64(7), int64(7)},
+		{"FLOAT8", 1.5, 1.5},
+		{"FLOAT8", math.NaN(), "NaN"},
+		{"FLOAT8", math.Inf(1), "Infinity"},
+		{"FLOAT8", math.Inf(-1), "-Infinity"},
+		{"FLOAT4", float32(0.5), float32(0.5)},
+		{"FLOAT4", float32(math.Inf(-1)), "-Infinity"},
+		{"TEXT", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := NormalizeValue(tt.dbType, tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NormalizeValue(%s, %v) = %#v, want %#v", tt.dbType, tt.in, got, tt.want)
+		}
+		if _, err := json.Marshal(NormalizeValue(tt.dbType, tt.in)); err != nil {
+			t.Errorf("NormalizeValue(%s, %v) is not JSON-encodable: %v", tt.dbType, tt.in, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Drift lists the differences between an expected schema (snapshot or
+// migrations) and the live schema. "Added" means present live but not
+// expected; "Removed" means expected but missing live.
+type Drift struct {
+	Base          string       `json:"base"`
+	AddedTables   []string     `json:"added_tables,omitempty"`
+	RemovedTables []string     `json:"removed_tables,omitempty"`
+	ChangedTables []TableDrift `json:"changed_tables,omitempty"`
+}
+
+// TableDrift lists the column and index differences of a single table.
+type TableDrift struct {
+	Table          string         `json:"table"`
+	AddedColumns   []Column       `json:"added_columns,omitempty"`
+	RemovedColumns []Column       `json:"removed_columns,omitempty"`
+	ChangedColumns []ColumnChange `json:"changed_columns,omitempty"`
+	AddedIndexes   []string       `json:"added_indexes,omitempty"`
+	RemovedIndexes []string       `json:"removed_indexes,omitempty"`
+	ChangedIndexes []string       `json:"changed_indexes,omitempty"`
+}
+
+// ColumnChange describes a column whose type or nullability differs.
+type ColumnChange struct {
+	Name     string `json:"name"`
+	Expected Column `json:"expected"`
+	Actual   Column `json:"actual"`
+}
+
+// HasChanges reports whether any drift was found.
+func (d Drift) HasChanges() bool {
+	return len(d.AddedTables) > 0 || len(d.RemovedTables) > 0 || len(d.ChangedTables) > 0
+}
+
+// Lines renders the drift as a human readable list, one change per line.
+func (d Drift) Lines() []string {
+	var out []string
+	for _, t := range d.AddedTables {
+		out = append(out, "+ table "+t)
+	}
+	for _, t := range d.RemovedTables {
+		out = append(out, "- table "+t)
+	}
+	for _, t := range d.ChangedTables {
+		for _, c := range t.AddedColumns {
+			out = append(out, fmt.Sprintf("+ column %s.%s %s", t.Table, c.Name, c.Type))
+		}
+		for _, c := range t.RemovedColumns {
+			out = append(out, fmt.Sprintf("- column %s.%s %s", t.Table, c.Name, c.Type))
+		}
+		for _, c := range t.ChangedColumns {
+			out = append(out, fmt.Sprintf("~ column %s.%s %s -> %s", t.Table, c.Name, describeColumn(c.Expected), describeColumn(c.Actual)))
+		}
+		for _, i := range t.AddedIndexes {
+			out = append(out, fmt.Sprintf("+ index %s on %s", i, t.Table))
+		}
+		for _, i := range t.RemovedIndexes {
+			out = append(out, fmt.Sprintf("- index %s on %s", i, t.Table))
+		}
+		for _, i := range t.ChangedIndexes {
+			out = append(out, fmt.Sprintf("~ index %s on %s", i, t.Table))
+		}
+	}
+	return out
+}
+
+func describeColumn(c Column) string {
+	if c.Nullable {
+		return c.Type + " null"
+	}
+	return c.Type + " not null"
+}
+
+// Diff compares expected against actual.
+func Diff(expected, actual *Snapshot) Drift {
+	d := Drift{Base: expected.Source}
+	exp := tableMap(expected.Tables)
+	act := tableMap(actual.Tables)
+
+	for name, at := range act {
+		et, ok := exp[name]
+		if !ok {
+			d.AddedTables = append(d.AddedTables, name)
+			continue
+		}
+		if td, changed := diffTable(name, et, at); changed {
+			d.ChangedTables = append(d.ChangedTables, td)
+		}
+	}
+	for name := range exp {
+		if _, ok := act[name]; !ok {
+			d.RemovedTables = append(d.RemovedTables, name)
+		}
+	}
+
+	sort.Strings(d.AddedTables)
+	sort.Strings(d.RemovedTables)
+	sort.Slice(d.ChangedTables, func(i, j int) bool { return d.ChangedTables[i].Table < d.ChangedTables[j].Table })
+	return d
+}
+
+func tableMap(tables []Table) map[string]Table {
+	m := make(map[string]Table, len(tables))
+	for _, t := range tables {
+		m[strings.ToLower(t.QualifiedName())] = t
+	}
+	return m
+}
+
+func diffTable(name string, expected, actual Table) (TableDrift, bool) {
+	td := TableDrift{Table: name}
+
+	expCols := make(map[string]Column, len(expected.Columns))
+	for _, c := range expected.Columns {
+		expCols[strings.ToLower(c.Name)] = c
+	}
+	seen := make(map[string]bool, len(actual.Columns))
+	for _, c := range actual.Columns {
+		key := strings.ToLower(c.Name)
+		seen[key] = true
+		ec, ok := expCols[key]
+		if !ok {
+			td.AddedColumns = append(td.AddedColumns, c)
+			continue
+		}
+		if NormalizeType(ec.Type) != NormalizeType(c.Type) || ec.Nullable != c.Nullable {
+			td.ChangedColumns = append(td.ChangedColumns, ColumnChange{Name: c.Name, Expected: ec, Actual: c})
+		}
+	}
+	for _, c := range expected.Columns {
+		if !seen[strings.ToLower(c.Name)] {
+			td.RemovedColumns = append(td.RemovedColumns, c)
+		}
+	}
+
+	// Indexes are only compared when both sides carry index information.
+	if len(expected.Indexes) > 0 || len(actual.Indexes) > 0 {
+		expIdx := indexMap(expected.Indexes)
+		actIdx := indexMap(actual.Indexes)
+		for n, ai := range actIdx {
+			ei, ok := expIdx[n]
+			switch {
+			case !ok:
+				td.AddedIndexes = append(td.AddedIndexes, ai.Name)
+			case ei.Unique != ai.Unique || !sameColumns(ei.Columns, ai.Columns):
+				td.ChangedIndexes = append(td.ChangedIndexes, ai.Name)
+			}
+		}
+		for n, ei := range expIdx {
+			if _, ok := actIdx[n]; !ok {
+				td.RemovedIndexes = append(td.RemovedIndexes, ei.Name)
+			}
+		}
+		sort.Strings(td.AddedIndexes)
+		sort.Strings(td.RemovedIndexes)
+		sort.Strings(td.ChangedIndexes)
+	}
+
+	changed := len(td.AddedColumns)+len(td.RemovedColumns)+len(td.ChangedColumns)+
+		len(td.AddedIndexes)+len(td.RemovedIndexes)+len(td.ChangedIndexes) > 0
+	return td, changed
+}
+
+func indexMap(idx []types.IndexInfo) map[string]types.IndexInfo {
+	m := make(map[string]types.IndexInfo, len(idx))
+	for _, i := range idx {
+		m[strings.ToLower(i.Name)] = i
+	}
+	return m
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if normalizeIdent(a[i]) != normalizeIdent(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeIdent(s string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(s), `"`))
+}
+
+var (
+	typeModsRe = regexp.MustCompile(`\s*\(.*\)`)
+	spacesRe   = regexp.MustCompile(`\s+`)
+)
+
+// typeAliases maps Postgres type spellings to the names reported by
+// information_schema.columns.data_type.
+var typeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"int8":        "bigint",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"int2":        "smallint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"bool":        "boolean",
+	"float8":      "double precision",
+	"float":       "double precision",
+	"float4":      "real",
+	"decimal":     "numeric",
+	"timestamptz": "timestamp with time zone",
+	"timestamp":   "timestamp without time zone",
+	"timetz":      "time with time zone",
+	"time":        "time without time zone",
+}
+
+// NormalizeType maps equivalent type spellings to a single canonical name so
+// that "VARCHAR(255)" and "character varying" compare equal.
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if strings.HasSuffix(t, "[]") || t == "array" {
+		return "array"
+	}
+	t = typeModsRe.ReplaceAllString(t, "")
+	t = spacesRe.ReplaceAllString(t, " ")
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
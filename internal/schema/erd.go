@@ -0,0 +1,276 @@
+package schema
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Diagram formats supported by RenderERD.
+const (
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+)
+
+// Relationship is a foreign key edge between two tables.
+type Relationship struct {
+	Name       string   `json:"name"`
+	From       string   `json:"from"`
+	Columns    []string `json:"columns"`
+	To         string   `json:"to"`
+	RefColumns []string `json:"ref_columns"`
+	Optional   bool     `json:"optional"`
+	OneToOne   bool     `json:"one_to_one"`
+}
+
+// Relationships returns the foreign keys between the given tables. Keys that
+// point outside the set are dropped so diagrams never reference undeclared
+// entities.
+func Relationships(tables []Table) []Relationship {
+	present := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		present[t.QualifiedName()] = true
+	}
+
+	var out []Relationship
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			to := fk.RefSchema + "." + fk.RefTable
+			if !present[to] {
+				continue
+			}
+			out = append(out, Relationship{
+				Name:       fk.Name,
+				From:       t.QualifiedName(),
+				Columns:    fk.Columns,
+				To:         to,
+				RefColumns: fk.RefColumns,
+				Optional:   anyNullable(t, fk.Columns),
+				OneToOne:   uniqueOn(t, fk.Columns),
+			})
+		}
+	}
+	return out
+}
+
+// Neighborhood returns the seed tables plus every table reachable within hops
+// foreign key edges, in either direction. Seeds match "schema.table" or a bare
+// table name in any schema. No seeds returns tables unchanged.
+func Neighborhood(tables []Table, seeds []string, hops int) []Table {
+	if len(seeds) == 0 {
+		return tables
+	}
+
+	adjacent := make(map[string][]string)
+	for _, r := range Relationships(tables) {
+		adjacent[r.From] = append(adjacent[r.From], r.To)
+		adjacent[r.To] = append(adjacent[r.To], r.From)
+	}
+
+	wanted := make(map[string]bool, len(seeds))
+	for _, s := range seeds {
+		wanted[strings.ToLower(s)] = true
+	}
+	keep := make(map[string]bool)
+	var frontier []string
+	for _, t := range tables {
+		q := t.QualifiedName()
+		if wanted[strings.ToLower(q)] || wanted[strings.ToLower(t.Name)] {
+			keep[q] = true
+			frontier = append(frontier, q)
+		}
+	}
+	for i := 0; i < hops && len(frontier) > 0; i++ {
+		var next []string
+		for _, q := range frontier {
+			for _, n := range adjacent[q] {
+				if !keep[n] {
+					keep[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var out []Table
+	for _, t := range tables {
+		if keep[t.QualifiedName()] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// RenderERD renders tables and their relationships in the given format.
+func RenderERD(tables []Table, format string) (string, error) {
+	switch format {
+	case FormatMermaid, "":
+		return Mermaid(tables), nil
+	case FormatDOT:
+		return DOT(tables), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format %q (want %s or %s)", format, FormatMermaid, FormatDOT)
+	}
+}
+
+var nonWordRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Mermaid renders a Mermaid erDiagram. Entities drop the schema prefix when
+// every table shares one schema.
+func Mermaid(tables []Table) string {
+	tables = sorted(tables)
+	names := entityNames(tables)
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, t := range tables {
+		fmt.Fprintf(&b, "    %s {\n", names[t.QualifiedName()])
+		keys := columnKeys(t)
+		for _, c := range t.Columns {
+			typ := strings.Trim(nonWordRe.ReplaceAllString(c.Type, "_"), "_")
+			if typ == "" {
+				typ = "unknown"
+			}
+			fmt.Fprintf(&b, "        %s %s", typ, mermaidIdent(c.Name))
+			if k := keys[c.Name]; len(k) > 0 {
+				b.WriteString(" " + strings.Join(k, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, r := range Relationships(tables) {
+		parent := "||"
+		if r.Optional {
+			parent = "|o"
+		}
+		child := "o{"
+		if r.OneToOne {
+			child = "o|"
+		}
+		fmt.Fprintf(&b, "    %s %s--%s %s : %q\n", names[r.To], parent, child, names[r.From], strings.Join(r.Columns, ", "))
+	}
+	return b.String()
+}
+
+// DOT renders a Graphviz digraph with one HTML-label node per table and an
+// edge from each referencing table to the referenced one.
+func DOT(tables []Table) string {
+	tables = sorted(tables)
+
+	var b strings.Builder
+	b.WriteString("digraph erd {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, t := range tables {
+		keys := columnKeys(t)
+		fmt.Fprintf(&b, "  %q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", t.QualifiedName())
+		fmt.Fprintf(&b, "<tr><td colspan=\"2\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>", html.EscapeString(t.QualifiedName()))
+		for _, c := range t.Columns {
+			name := html.EscapeString(c.Name)
+			if k := keys[c.Name]; len(k) > 0 {
+				name += " (" + strings.Join(k, ",") + ")"
+			}
+			fmt.Fprintf(&b, "<tr><td align=\"left\" port=%q>%s</td><td align=\"left\">%s</td></tr>", c.Name, name, html.EscapeString(c.Type))
+		}
+		b.WriteString("</table>>];\n")
+	}
+	for _, r := range Relationships(tables) {
+		style := "solid"
+		if r.Optional {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q, style=%s];\n", r.From, r.To, strings.Join(r.Columns, ", "), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// columnKeys marks primary key (PK), foreign key (FK) and single-column
+// unique (UK) columns. Primary keys are recognized by Postgres' default
+// "<table>_pkey" index name.
+func columnKeys(t Table) map[string][]string {
+	out := make(map[string][]string)
+	mark := func(col, key string) {
+		for _, k := range out[col] {
+			if k == key {
+				return
+			}
+		}
+		out[col] = append(out[col], key)
+	}
+	for _, idx := range t.Indexes {
+		switch {
+		case strings.HasSuffix(idx.Name, "_pkey"):
+			for _, c := range idx.Columns {
+				mark(c, "PK")
+			}
+		case idx.Unique && len(idx.Columns) == 1:
+			mark(idx.Columns[0], "UK")
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		for _, c := range fk.Columns {
+			mark(c, "FK")
+		}
+	}
+	return out
+}
+
+func anyNullable(t Table, cols []string) bool {
+	for _, name := range cols {
+		for _, c := range t.Columns {
+			if c.Name == name && c.Nullable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func uniqueOn(t Table, cols []string) bool {
+	want := strings.Join(cols, ",")
+	for _, idx := range t.Indexes {
+		if idx.Unique && strings.Join(idx.Columns, ",") == want {
+			return true
+		}
+	}
+	return false
+}
+
+func entityNames(tables []Table) map[string]string {
+	single := true
+	for _, t := range tables {
+		if t.Schema != tables[0].Schema {
+			single = false
+			break
+		}
+	}
+	out := make(map[string]string, len(tables))
+	for _, t := range tables {
+		name := t.Schema + "__" + t.Name
+		if single {
+			name = t.Name
+		}
+		out[t.QualifiedName()] = mermaidIdent(name)
+	}
+	return out
+}
+
+func mermaidIdent(s string) string {
+	s = nonWordRe.ReplaceAllString(s, "_")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+func sorted(tables []Table) []Table {
+	out := append([]Table(nil), tables...)
+	sort.Slice(out, func(i, j int) bool { return out[i].QualifiedName() < out[j].QualifiedName() })
+	return out
+}
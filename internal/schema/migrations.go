@@ -0,0 +1,415 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/internal/migfiles"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// defaultSchema is assumed for unqualified table names in migrations.
+const defaultSchema = "public"
+
+// FromMigrations replays the up migrations in dir and returns the schema they
+// produce. Only common DDL is understood (CREATE/ALTER/DROP TABLE and
+// CREATE/DROP INDEX); other statements are ignored.
+func FromMigrations(dir string) (*Snapshot, error) {
+	files, err := migfiles.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	b := newBuilder()
+	for _, f := range files {
+		for _, st := range migfiles.Split(f.Up, f.UpLine) {
+			b.apply(st.SQL)
+		}
+	}
+	snap := &Snapshot{Version: snapshotVersion, TakenAt: time.Now().UTC(), Source: SourceMigrations}
+	seen := make(map[string]bool, len(b.order))
+	for _, key := range b.order {
+		if t, ok := b.tables[key]; ok && !seen[key] {
+			seen[key] = true
+			snap.Tables = append(snap.Tables, *t)
+		}
+	}
+	snap.sort()
+	return snap, nil
+}
+
+type builder struct {
+	tables map[string]*Table
+	order  []string
+}
+
+func newBuilder() *builder {
+	return &builder{tables: make(map[string]*Table)}
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:TEMP(?:ORARY)?\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + identPattern + `)\s*\(`)
+	alterTableRe  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(` + identPattern + `)\s+(.*)$`)
+	dropTableRe   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.*?)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	createIndexRe = regexp.MustCompile(`(?is)^CREATE\s+(UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(` + identPattern + `)?\s*ON\s+(?:ONLY\s+)?(` + identPattern + `)\s*(?:USING\s+\w+\s*)?\(`)
+	dropIndexRe   = regexp.MustCompile(`(?is)^DROP\s+INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?(.*?)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	alterIndexRe  = regexp.MustCompile(`(?is)^ALTER\s+INDEX\s+(?:IF\s+EXISTS\s+)?(` + identPattern + `)\s+RENAME\s+TO\s+(` + identPattern + `)`)
+
+	addColumnRe    = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(.*)$`)
+	dropColumnRe   = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(` + identPattern + `)`)
+	alterTypeRe    = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?(` + identPattern + `)\s+(?:SET\s+DATA\s+)?TYPE\s+(.*?)(?:\s+USING\s+.*|\s+COLLATE\s+.*)?$`)
+	alterNullRe    = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?(` + identPattern + `)\s+(SET|DROP)\s+NOT\s+NULL`)
+	renameColumnRe = regexp.MustCompile(`(?is)^RENAME\s+(?:COLUMN\s+)?(` + identPattern + `)\s+TO\s+(` + identPattern + `)`)
+	renameTableRe  = regexp.MustCompile(`(?is)^RENAME\s+TO\s+(` + identPattern + `)`)
+	keyConstraint  = regexp.MustCompile(`(?is)^(?:CONSTRAINT\s+(` + identPattern + `)\s+)?(PRIMARY\s+KEY|UNIQUE)\s*\((.*?)\)`)
+	uniqueWordRe   = regexp.MustCompile(`\bUNIQUE\b`)
+)
+
+// identPattern matches a possibly schema-qualified, possibly quoted identifier.
+const identPattern = `(?:"[^"]+"|[\w$]+)(?:\.(?:"[^"]+"|[\w$]+))?`
+
+// columnStopWords end the type portion of a column definition.
+var columnStopWords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true,
+	"REFERENCES": true, "CHECK": true, "CONSTRAINT": true, "GENERATED": true,
+	"COLLATE": true,
+}
+
+func (b *builder) apply(stmt string) {
+	stmt = strings.TrimSpace(stmt)
+	switch {
+	case createTableRe.MatchString(stmt):
+		m := createTableRe.FindStringSubmatchIndex(stmt)
+		b.createTable(stmt[m[2]:m[3]], Parenthesized(stmt[m[1]-1:]))
+	case alterTableRe.MatchString(stmt):
+		m := alterTableRe.FindStringSubmatch(stmt)
+		t := b.table(m[1])
+		if t == nil {
+			return
+		}
+		for _, action := range SplitTopLevel(m[2]) {
+			b.alterTable(t, strings.TrimSpace(action))
+		}
+	case dropTableRe.MatchString(stmt):
+		m := dropTableRe.FindStringSubmatch(stmt)
+		for _, name := range SplitTopLevel(m[1]) {
+			delete(b.tables, qualify(name))
+		}
+	case createIndexRe.MatchString(stmt):
+		loc := createIndexRe.FindStringSubmatchIndex(stmt)
+		m := createIndexRe.FindStringSubmatch(stmt)
+		t := b.table(m[3])
+		if t == nil {
+			return
+		}
+		cols := SplitTopLevel(Parenthesized(stmt[loc[1]-1:]))
+		name := unquote(lastPart(m[2]))
+		if name == "" {
+			name = t.Name + "_" + strings.Join(identList(cols), "_") + "_idx"
+		}
+		b.addIndex(t, types.IndexInfo{Name: name, Columns: identList(cols), Unique: m[1] != ""})
+	case dropIndexRe.MatchString(stmt):
+		m := dropIndexRe.FindStringSubmatch(stmt)
+		for _, name := range SplitTopLevel(m[1]) {
+			b.dropIndex(unquote(lastPart(name)))
+		}
+	case alterIndexRe.MatchString(stmt):
+		m := alterIndexRe.FindStringSubmatch(stmt)
+		b.renameIndex(unquote(lastPart(m[1])), unquote(lastPart(m[2])))
+	}
+}
+
+func (b *builder) createTable(name, body string) {
+	key := qualify(name)
+	schema, table := splitQualified(name)
+	t := &Table{Schema: schema, Name: table}
+	if _, exists := b.tables[key]; !exists {
+		b.order = append(b.order, key)
+	}
+	b.tables[key] = t
+
+	for _, def := range SplitTopLevel(body) {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+		if b.addConstraint(t, def) {
+			continue
+		}
+		upper := strings.ToUpper(def)
+		if strings.HasPrefix(upper, "FOREIGN KEY") || strings.HasPrefix(upper, "CHECK") ||
+			strings.HasPrefix(upper, "EXCLUDE") || strings.HasPrefix(upper, "LIKE") ||
+			strings.HasPrefix(upper, "CONSTRAINT") {
+			continue
+		}
+		b.addColumn(t, def)
+	}
+}
+
+func (b *builder) alterTable(t *Table, action string) {
+	upper := strings.ToUpper(action)
+	switch {
+	case strings.HasPrefix(upper, "ADD CONSTRAINT") || strings.HasPrefix(upper, "ADD PRIMARY") || strings.HasPrefix(upper, "ADD UNIQUE"):
+		b.addConstraint(t, strings.TrimSpace(action[len("ADD"):]))
+	case strings.HasPrefix(upper, "ADD CHECK") || strings.HasPrefix(upper, "ADD FOREIGN") || strings.HasPrefix(upper, "ADD EXCLUDE"):
+		return
+	case strings.HasPrefix(upper, "ADD"):
+		if m := addColumnRe.FindStringSubmatch(action); m != nil {
+			b.addColumn(t, m[1])
+		}
+	case strings.HasPrefix(upper, "DROP CONSTRAINT"):
+		fields := strings.Fields(action)
+		if len(fields) >= 3 {
+			name := fields[2]
+			if strings.EqualFold(name, "IF") && len(fields) >= 5 {
+				name = fields[4]
+			}
+			b.dropIndex(unquote(name))
+		}
+	case strings.HasPrefix(upper, "DROP"):
+		if m := dropColumnRe.FindStringSubmatch(action); m != nil {
+			name := unquote(m[1])
+			cols := t.Columns[:0]
+			for _, c := range t.Columns {
+				if !strings.EqualFold(c.Name, name) {
+					cols = append(cols, c)
+				}
+			}
+			t.Columns = cols
+		}
+	case strings.HasPrefix(upper, "ALTER"):
+		if m := alterNullRe.FindStringSubmatch(action); m != nil {
+			if c := findColumn(t, unquote(m[1])); c != nil {
+				c.Nullable = strings.EqualFold(m[2], "DROP")
+			}
+		} else if m := alterTypeRe.FindStringSubmatch(action); m != nil {
+			if c := findColumn(t, unquote(m[1])); c != nil {
+				c.Type = strings.TrimSpace(m[2])
+			}
+		}
+	case strings.HasPrefix(upper, "RENAME"):
+		if m := renameTableRe.FindStringSubmatch(action); m != nil {
+			oldKey := qualify(t.QualifiedName())
+			t.Name = unquote(lastPart(m[1]))
+			newKey := qualify(t.QualifiedName())
+			delete(b.tables, oldKey)
+			b.tables[newKey] = t
+			b.order = append(b.order, newKey)
+		} else if m := renameColumnRe.FindStringSubmatch(action); m != nil {
+			if c := findColumn(t, unquote(m[1])); c != nil {
+				c.Name = unquote(m[2])
+			}
+		}
+	}
+}
+
+// addConstraint handles PRIMARY KEY and UNIQUE table constraints, which
+// Postgres backs with an index. It reports whether def was a key constraint.
+func (b *builder) addConstraint(t *Table, def string) bool {
+	m := keyConstraint.FindStringSubmatch(def)
+	if m == nil {
+		return false
+	}
+	cols := identList(SplitTopLevel(m[3]))
+	primary := strings.HasPrefix(strings.ToUpper(m[2]), "PRIMARY")
+	name := unquote(m[1])
+	if name == "" {
+		if primary {
+			name = t.Name + "_pkey"
+		} else {
+			name = t.Name + "_" + strings.Join(cols, "_") + "_key"
+		}
+	}
+	if primary {
+		for _, c := range cols {
+			if col := findColumn(t, c); col != nil {
+				col.Nullable = false
+			}
+		}
+	}
+	b.addIndex(t, types.IndexInfo{Name: name, Columns: cols, Unique: true})
+	return true
+}
+
+func (b *builder) addColumn(t *Table, def string) {
+	fields := strings.Fields(def)
+	if len(fields) < 2 {
+		return
+	}
+	name := unquote(fields[0])
+	typeParts := []string{}
+	rest := fields[1:]
+	for len(rest) > 0 && !columnStopWords[strings.ToUpper(rest[0])] {
+		typeParts = append(typeParts, rest[0])
+		rest = rest[1:]
+	}
+	col := Column{Name: name, Type: strings.Join(typeParts, " "), Nullable: true}
+
+	constraints := strings.ToUpper(strings.Join(rest, " "))
+	if strings.Contains(constraints, "NOT NULL") || strings.Contains(constraints, "PRIMARY KEY") {
+		col.Nullable = false
+	}
+	if findColumn(t, name) == nil {
+		t.Columns = append(t.Columns, col)
+	}
+	if strings.Contains(constraints, "PRIMARY KEY") {
+		b.addIndex(t, types.IndexInfo{Name: t.Name + "_pkey", Columns: []string{name}, Unique: true})
+	} else if uniqueWordRe.MatchString(constraints) {
+		b.addIndex(t, types.IndexInfo{Name: t.Name + "_" + name + "_key", Columns: []string{name}, Unique: true})
+	}
+}
+
+func (b *builder) addIndex(t *Table, idx types.IndexInfo) {
+	for i := range t.Indexes {
+		if strings.EqualFold(t.Indexes[i].Name, idx.Name) {
+			t.Indexes[i] = idx
+			return
+		}
+	}
+	t.Indexes = append(t.Indexes, idx)
+}
+
+func (b *builder) dropIndex(name string) {
+	for _, t := range b.tables {
+		for i := range t.Indexes {
+			if strings.EqualFold(t.Indexes[i].Name, name) {
+				t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (b *builder) renameIndex(from, to string) {
+	for _, t := range b.tables {
+		for i := range t.Indexes {
+			if strings.EqualFold(t.Indexes[i].Name, from) {
+				t.Indexes[i].Name = to
+				return
+			}
+		}
+	}
+}
+
+func (b *builder) table(name string) *Table {
+	return b.tables[qualify(name)]
+}
+
+func findColumn(t *Table, name string) *Column {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Parenthesized returns the contents of the balanced parenthesized group that
+// s starts with. It returns the remainder of s when the group is unterminated.
+func Parenthesized(s string) string {
+	if !strings.HasPrefix(s, "(") {
+		return ""
+	}
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return s[1:i]
+			}
+		}
+	}
+	return s[1:]
+}
+
+// SplitTopLevel splits s on commas that are not nested in parentheses or
+// quotes.
+func SplitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// identList normalizes index column expressions to bare column names where
+// possible, dropping sort order and operator classes.
+func identList(cols []string) []string {
+	out := make([]string, 0, len(cols))
+	for _, c := range cols {
+		f := strings.Fields(c)
+		if len(f) == 0 {
+			continue
+		}
+		if strings.Contains(c, "(") {
+			out = append(out, strings.TrimSpace(c))
+			continue
+		}
+		out = append(out, unquote(f[0]))
+	}
+	return out
+}
+
+func splitQualified(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return unquote(name[:i]), unquote(name[i+1:])
+	}
+	return defaultSchema, unquote(name)
+}
+
+func qualify(name string) string {
+	schema, table := splitQualified(name)
+	return strings.ToLower(fmt.Sprintf("%s.%s", schema, table))
+}
+
+func lastPart(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return strings.ToLower(s)
+}
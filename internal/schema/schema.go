@@ -0,0 +1,191 @@
+// Package schema builds typed database schema snapshots, either from a live
+// connection or from SQL migration files, and compares them for drift.
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultSnapshotPath is the snapshot location relative to the project root.
+const DefaultSnapshotPath = ".scg/schema.snapshot.json"
+
+// snapshotVersion is bumped when the on-disk snapshot format changes.
+const snapshotVersion = 1
+
+// Source values describe where a snapshot came from.
+const (
+	SourceLive       = "live"
+	SourceMigrations = "migrations"
+)
+
+// Snapshot is a point-in-time copy of the database schema.
+type Snapshot struct {
+	Version int       `json:"version"`
+	TakenAt time.Time `json:"taken_at"`
+	Source  string    `json:"source"`
+	Tables  []Table   `json:"tables"`
+}
+
+// Table describes a single table.
+type Table struct {
+	Schema      string                 `json:"schema"`
+	Name        string                 `json:"name"`
+	Columns     []Column               `json:"columns"`
+	Indexes     []types.IndexInfo      `json:"indexes,omitempty"`
+	ForeignKeys []types.ForeignKeyInfo `json:"foreign_keys,omitempty"`
+}
+
+// Column describes a single table column.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// QualifiedName returns "schema.table".
+func (t Table) QualifiedName() string {
+	return t.Schema + "." + t.Name
+}
+
+// Filter returns the tables belonging to one of the given schemas. An empty
+// allowlist returns every table.
+func (s *Snapshot) Filter(schemas []string) []Table {
+	if len(schemas) == 0 {
+		return s.Tables
+	}
+	allowed := make(map[string]bool, len(schemas))
+	for _, sc := range schemas {
+		allowed[sc] = true
+	}
+	var out []Table
+	for _, t := range s.Tables {
+		if allowed[t.Schema] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Introspect reads the schema of every allowed schema through db. When
+// allowSchemas is empty all schemas reported by db are included. Indexes and
+// foreign keys are collected when db implements types.IndexLister and
+// types.ForeignKeyLister.
+func Introspect(ctx context.Context, db types.DBConn, allowSchemas []string) (*Snapshot, error) {
+	if db == nil {
+		return nil, errors.New("schema: nil db")
+	}
+	schemas := allowSchemas
+	if len(schemas) == 0 {
+		var err error
+		schemas, err = db.Schemas(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list schemas: %w", err)
+		}
+	}
+	indexer, _ := db.(types.IndexLister)
+	fkLister, _ := db.(types.ForeignKeyLister)
+
+	snap := &Snapshot{Version: snapshotVersion, TakenAt: time.Now().UTC(), Source: SourceLive}
+	for _, sc := range schemas {
+		tables, err := db.Tables(ctx, sc)
+		if err != nil {
+			return nil, fmt.Errorf("list tables for schema %s: %w", sc, err)
+		}
+		for _, name := range tables {
+			cols, err := db.Columns(ctx, sc, name)
+			if err != nil {
+				return nil, fmt.Errorf("list columns for table %s.%s: %w", sc, name, err)
+			}
+			t := Table{Schema: sc, Name: name, Columns: columnsFromMaps(cols)}
+			if indexer != nil {
+				idx, err := indexer.Indexes(ctx, sc, name)
+				if err != nil {
+					return nil, fmt.Errorf("list indexes for table %s.%s: %w", sc, name, err)
+				}
+				t.Indexes = idx
+			}
+			if fkLister != nil {
+				fks, err := fkLister.ForeignKeys(ctx, sc, name)
+				if err != nil {
+					return nil, fmt.Errorf("list foreign keys for table %s.%s: %w", sc, name, err)
+				}
+				t.ForeignKeys = fks
+			}
+			snap.Tables = append(snap.Tables, t)
+		}
+	}
+	snap.sort()
+	return snap, nil
+}
+
+// columnsFromMaps converts DBConn.Columns rows into typed columns.
+func columnsFromMaps(rows []map[string]any) []Column {
+	cols := make([]Column, 0, len(rows))
+	for _, r := range rows {
+		c := Column{}
+		c.Name, _ = r["name"].(string)
+		c.Type, _ = r["type"].(string)
+		c.Nullable, _ = r["nullable"].(bool)
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+// ColumnMaps converts typed columns back into the map shape used by the
+// dbschema tools.
+func ColumnMaps(cols []Column) []map[string]any {
+	out := make([]map[string]any, 0, len(cols))
+	for _, c := range cols {
+		out = append(out, map[string]any{"name": c.Name, "type": c.Type, "nullable": c.Nullable})
+	}
+	return out
+}
+
+func (s *Snapshot) sort() {
+	sort.Slice(s.Tables, func(i, j int) bool {
+		return s.Tables[i].QualifiedName() < s.Tables[j].QualifiedName()
+	})
+	for i := range s.Tables {
+		idx := s.Tables[i].Indexes
+		sort.Slice(idx, func(a, b int) bool { return idx[a].Name < idx[b].Name })
+	}
+}
+
+// Load reads a snapshot file.
+func Load(path string) (*Snapshot, error) {
+	// #nosec G304 -- snapshot path is configured by the host or CLI user.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// Save writes snap to path, creating parent directories as needed.
+func Save(path string, snap *Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+	b = append(b, '\n')
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+func writeFile(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestFromMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "001_init.up.sql", `
+CREATE TABLE users (
+	id BIGSERIAL PRIMARY KEY,
+	email VARCHAR(255) NOT NULL UNIQUE,
+	name text,
+	CONSTRAINT users_name_check CHECK (name <> '')
+);
+CREATE TABLE orders (id uuid PRIMARY KEY, user_id bigint REFERENCES users(id)) PARTITION BY HASH (id);
+`)
+	writeFile(t, dir, "001_init.down.sql", `DROP TABLE orders; DROP TABLE users;`)
+	writeFile(t, dir, "002_alter.up.sql", `
+ALTER TABLE users ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(), DROP COLUMN name;
+ALTER TABLE users ALTER COLUMN email TYPE text;
+CREATE INDEX CONCURRENTLY orders_user_idx ON orders USING btree (user_id) WHERE user_id IS NOT NULL;
+`)
+
+	snap, err := FromMigrations(dir)
+	if err != nil {
+		t.Fatalf("FromMigrations() error = %v", err)
+	}
+	if len(snap.Tables) != 2 {
+		t.Fatalf("len(tables) = %d, want 2", len(snap.Tables))
+	}
+
+	users := snap.Tables[1]
+	if users.QualifiedName() != "public.users" {
+		t.Fatalf("table = %s, want public.users", users.QualifiedName())
+	}
+	want := []Column{
+		{Name: "id", Type: "BIGSERIAL", Nullable: false},
+		{Name: "email", Type: "text", Nullable: false},
+		{Name: "created_at", Type: "timestamptz", Nullable: false},
+	}
+	if len(users.Columns) != len(want) {
+		t.Fatalf("columns = %+v, want %+v", users.Columns, want)
+	}
+	for i, c := range want {
+		if users.Columns[i] != c {
+			t.Errorf("column[%d] = %+v, want %+v", i, users.Columns[i], c)
+		}
+	}
+	if len(users.Indexes) != 2 {
+		t.Errorf("users indexes = %+v, want pkey and email key", users.Indexes)
+	}
+
+	orders := snap.Tables[0]
+	if len(orders.Indexes) != 2 || orders.Indexes[1].Name != "orders_user_idx" {
+		t.Errorf("orders indexes = %+v", orders.Indexes)
+	}
+	if got := orders.Indexes[1].Columns; len(got) != 1 || got[0] != "user_id" {
+		t.Errorf("orders_user_idx columns = %v, want [user_id]", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	expected := &Snapshot{Source: SourceMigrations, Tables: []Table{
+		{Schema: "public", Name: "users", Columns: []Column{
+			{Name: "id", Type: "bigserial"},
+			{Name: "email", Type: "varchar(255)"},
+			{Name: "legacy", Type: "text", Nullable: true},
+		}},
+		{Schema: "public", Name: "gone", Columns: []Column{{Name: "id", Type: "int"}}},
+	}}
+	actual := &Snapshot{Source: SourceLive, Tables: []Table{
+		{Schema: "public", Name: "users", Columns: []Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "email", Type: "text"},
+			{Name: "nickname", Type: "text", Nullable: true},
+		}},
+		{Schema: "public", Name: "audit", Columns: []Column{{Name: "id", Type: "integer"}}},
+	}}
+
+	d := Diff(expected, actual)
+	if !d.HasChanges() {
+		t.Fatal("expected drift")
+	}
+	if len(d.AddedTables) != 1 || d.AddedTables[0] != "public.audit" {
+		t.Errorf("AddedTables = %v", d.AddedTables)
+	}
+	if len(d.RemovedTables) != 1 || d.RemovedTables[0] != "public.gone" {
+		t.Errorf("RemovedTables = %v", d.RemovedTables)
+	}
+	if len(d.ChangedTables) != 1 {
+		t.Fatalf("ChangedTables = %+v", d.ChangedTables)
+	}
+	td := d.ChangedTables[0]
+	if len(td.AddedColumns) != 1 || len(td.RemovedColumns) != 1 || len(td.ChangedColumns) != 1 {
+		t.Errorf("table drift = %+v", td)
+	}
+	if td.ChangedColumns[0].Name != "email" {
+		t.Errorf("changed column = %s, want email", td.ChangedColumns[0].Name)
+	}
+}
+
+func TestNormalizeType(t *testing.T) {
+	tests := map[string]string{
+		"VARCHAR(255)":                "character varying",
+		"character varying":           "character varying",
+		"timestamptz":                 "timestamp with time zone",
+		"TIMESTAMP(3) WITH TIME ZONE": "timestamp with time zone",
+		"serial":                      "integer",
+		"numeric(10, 2)":              "numeric",
+		"text[]":                      "array",
+		"jsonb":                       "jsonb",
+	}
+	for in, want := range tests {
+		if got := NormalizeType(in); got != want {
+			t.Errorf("NormalizeType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".scg", "schema.snapshot.json")
+	db := &fakeDB{}
+	snap, err := Introspect(context.Background(), db, nil)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	if err := Save(path, snap); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.Tables) != 1 || got.Tables[0].Columns[0].Name != "id" {
+		t.Errorf("loaded snapshot = %+v", got)
+	}
+}
+
+type fakeDB struct{}
+
+func (fakeDB) QueryJSON(context.Context, string, map[string]any) ([]map[string]any, error) {
+	return nil, nil
+}
+func (fakeDB) Schemas(context.Context) ([]string, error) { return []string{"public"}, nil }
+func (fakeDB) Tables(context.Context, string) ([]string, error) {
+	return []string{"users"}, nil
+}
+func (fakeDB) Columns(context.Context, string, string) ([]map[string]any, error) {
+	return []map[string]any{{"name": "id", "type": "bigint", "nullable": false}}, nil
+}
+
+func TestERD(t *testing.T) {
+	tables := []Table{
+		{
+			Schema:  "public",
+			Name:    "users",
+			Columns: []Column{{Name: "id", Type: "bigint"}, {Name: "email", Type: "character varying"}},
+			Indexes: []types.IndexInfo{{Name: "users_pkey", Columns: []string{"id"}, Unique: true}, {Name: "users_email_key", Columns: []string{"email"}, Unique: true}},
+		},
+		{
+			Schema:      "public",
+			Name:        "orders",
+			Columns:     []Column{{Name: "id", Type: "bigint"}, {Name: "user_id", Type: "bigint", Nullable: true}},
+			ForeignKeys: []types.ForeignKeyInfo{{Name: "orders_user_fk", Columns: []string{"user_id"}, RefSchema: "public", RefTable: "users", RefColumns: []string{"id"}}},
+		},
+		{
+			Schema:      "billing",
+			Name:        "invoices",
+			Columns:     []Column{{Name: "order_id", Type: "bigint"}},
+			ForeignKeys: []types.ForeignKeyInfo{{Name: "inv_order_fk", Columns: []string{"order_id"}, RefSchema: "public", RefTable: "orders", RefColumns: []string{"id"}}},
+		},
+	}
+
+	if got := Neighborhood(tables, []string{"users"}, 0); len(got) != 1 {
+		t.Errorf("Neighborhood(hops=0) = %d tables, want 1", len(got))
+	}
+	if got := Neighborhood(tables, []string{"public.users"}, 1); len(got) != 2 {
+		t.Errorf("Neighborhood(hops=1) = %d tables, want 2", len(got))
+	}
+	if got := Neighborhood(tables, []string{"users"}, 2); len(got) != 3 {
+		t.Errorf("Neighborhood(hops=2) = %d tables, want 3", len(got))
+	}
+
+	public := (&Snapshot{Tables: tables}).Filter([]string{"public"})
+	mermaid := Mermaid(public)
+	for _, want := range []string{
+		"erDiagram",
+		"bigint id PK",
+		"character_varying email UK",
+		"bigint user_id FK",
+		`users |o--o{ orders : "user_id"`,
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	dot := DOT(tables)
+	for _, want := range []string{
+		"digraph erd {",
+		`"public.orders" -> "public.users" [label="user_id", style=dashed];`,
+		`"billing.invoices" -> "public.orders"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() missing %q:\n%s", want, dot)
+		}
+	}
+
+	// Relationships to tables outside the set are dropped.
+	if rels := Relationships(tables[2:]); len(rels) != 0 {
+		t.Errorf("Relationships() = %+v, want none", rels)
+	}
+	if _, err := RenderERD(tables, "svg"); err == nil {
+		t.Error("RenderERD(svg) error = nil, want error")
+	}
+}
@@ -30,27 +30,74 @@ const (
 	ScopeDocsSearch       = "docs.search"
 	ScopeMetricsSummary   = "metrics.summary"
 	ScopeEnvCheck         = "env.check"
+	ScopeDBSchemaDrift    = "dbschema.drift"
+	ScopeMigrationsLint   = "migrations.lint"
+	ScopeDBSchemaERD      = "dbschema.erd"
+	ScopeDBProfile        = "db.profile"
+	ScopeLogsSearch       = "logs.search"
+	ScopeLogsTail         = "logs.tail"
+	ScopeLogsClusters     = "logs.clusters"
+	ScopeTraceGet         = "trace.get"
+	ScopeTraceSearch      = "trace.search"
+	ScopeCorrelate        = "correlate"
+	ScopeDiagnoseSnapshot = "diagnose.snapshot"
+	ScopeHealthHistory    = "health.history"
+	ScopeMetricsQuery     = "metrics.query"
+	ScopeMetricsBaseline  = "metrics.baseline.save"
+	ScopeMetricsCompare   = "metrics.compare"
+	ScopeSLOStatus        = "slo.status"
+	ScopeEventsDeadLetter = "events.deadletter.peek"
+	ScopeEventsValidate   = "events.validate"
+	ScopeQueuesStatus     = "queues.status"
 )
 
 // ToolScopes maps tool names to their required scopes.
 var ToolScopes = map[string][]string{
-	"appinfo.get":         {ScopeAppInfoGet},
-	"config.get":          {ScopeConfigGet},
-	"config.list":         {ScopeConfigList},
-	"dbschema.list":       {ScopeDBSchemaList, ScopeDBRead},
-	"dbquery.run":         {ScopeDBQueryRun, ScopeDBRead},
-	"logs.lastError":      {ScopeLogsLastError},
-	"health.status":       {ScopeHealthStatus},
-	"events.outbox.peek":  {ScopeEventsOutboxPeek},
-	"trace.lookup":        {ScopeTraceLookup},
-	"service.topology":    {ScopeServiceTopology},
-	"routes.list":         {ScopeRoutesList},
-	"migrations.status":   {ScopeMigrationsStatus},
-	"cache.stats":         {ScopeCacheStats},
-	"docs.search":         {ScopeDocsSearch},
-	"metrics.summary":     {ScopeMetricsSummary},
-	"env.check":           {ScopeEnvCheck},
-	"resource.guidelines": {ScopeResourceGuidelines},
+	"appinfo.get":            {ScopeAppInfoGet},
+	"config.get":             {ScopeConfigGet},
+	"config.list":            {ScopeConfigList},
+	"dbschema.list":          {ScopeDBSchemaList, ScopeDBRead},
+	"dbquery.run":            {ScopeDBQueryRun, ScopeDBRead},
+	"logs.lastError":         {ScopeLogsLastError},
+	"logs.search":            {ScopeLogsSearch},
+	"logs.tail":              {ScopeLogsTail},
+	"logs.clusters":          {ScopeLogsClusters},
+	"health.status":          {ScopeHealthStatus},
+	"health.history":         {ScopeHealthHistory},
+	"events.outbox.peek":     {ScopeEventsOutboxPeek},
+	"events.deadletter.peek": {ScopeEventsDeadLetter},
+	"events.validate":        {ScopeEventsValidate},
+	"trace.lookup":           {ScopeTraceLookup},
+	"trace.get":              {ScopeTraceGet},
+	"trace.search":           {ScopeTraceSearch},
+	"correlate":              {ScopeCorrelate},
+	"diagnose.snapshot":      {ScopeDiagnoseSnapshot},
+	"service.topology":       {ScopeServiceTopology},
+	"routes.list":            {ScopeRoutesList},
+	"migrations.status":      {ScopeMigrationsStatus},
+	"migrations.lint":        {ScopeMigrationsLint},
+	"cache.stats":            {ScopeCacheStats},
+	"queues.status":          {ScopeQueuesStatus},
+	"docs.search":            {ScopeDocsSearch},
+	"metrics.summary":        {ScopeMetricsSummary},
+	"metrics.query":          {ScopeMetricsQuery},
+	"metrics.baseline.save":  {ScopeMetricsBaseline},
+	"metrics.compare":        {ScopeMetricsCompare},
+	"slo.status":             {ScopeSLOStatus},
+	"env.check":              {ScopeEnvCheck},
+	"dbschema.drift":         {ScopeDBSchemaDrift, ScopeDBRead},
+	"dbschema.erd":           {ScopeDBSchemaERD, ScopeDBRead},
+	"db.profile":             {ScopeDBProfile, ScopeDBRead},
+	"resource.guidelines":    {ScopeResourceGuidelines},
+}
+
+// ResourceScopes maps resource URIs to their required scopes. Resources that
+// expose the same data as a tool require that tool's scopes.
+var ResourceScopes = map[string][]string{
+	"scg://db/erd":               ToolScopes["dbschema.erd"],
+	"scg://db/erd.dot":           ToolScopes["dbschema.erd"],
+	"scg://service/topology.mmd": ToolScopes["service.topology"],
+	"scg://service/topology.dot": ToolScopes["service.topology"],
 }
 
 // AllowAllAuthorizer is a development-only authorizer that grants all scopes.
@@ -71,3 +118,21 @@ func NewAllowAllAuthorizer() types.Authorizer {
 func GetToolScopes(toolName string) []string {
 	return ToolScopes[toolName]
 }
+
+// GetResourceScopes returns the required scopes for a resource URI.
+// Returns nil if the resource has no scope requirements.
+func GetResourceScopes(uri string) []string {
+	return ResourceScopes[uri]
+}
+
+// MissingToolScope returns the first scope required by a tool that a does
+// not grant, or "" when all are granted. Tools that aggregate other tools'
+// data use it to omit what the caller could not read directly.
+func MissingToolScope(ctx context.Context, a types.Authorizer, toolName string) string {
+	for _, scope := range ToolScopes[toolName] {
+		if !a.HasScope(ctx, scope) {
+			return scope
+		}
+	}
+	return ""
+}
@@ -0,0 +1,463 @@
+// Package correlate implements the correlate tool, which gathers logs,
+// traces and outbox events sharing a trace, request or aggregate ID into one
+// timeline.
+package correlate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/security"
+	"github.com/next-trace/scg-boost/internal/tools/logs"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// ID kinds accepted by the tool.
+const (
+	KindAuto      = "auto"
+	KindTrace     = "trace"
+	KindRequest   = "request"
+	KindAggregate = "aggregate"
+)
+
+// Timeline entry sources.
+const (
+	SourceLogs   = "logs"
+	SourceTrace  = "trace"
+	SourceOutbox = "outbox"
+)
+
+const (
+	defaultLimit = 200
+	maxLimit     = 1000
+	// outboxScan is how many recent outbox events are searched for an ID in
+	// their payload or headers.
+	outboxScan = 500
+)
+
+// fieldNames are the log field names searched for each kind of ID.
+var fieldNames = map[string][]string{
+	KindTrace:     {"trace_id", "traceId", "trace.id", "traceID", "dd.trace_id"},
+	KindRequest:   {"request_id", "requestId", "req_id", "request.id", "x_request_id", "correlation_id"},
+	KindAggregate: {"aggregate_id", "aggregateId", "entity_id", "order_id"},
+}
+
+// timeKeys are tried, in order, for an outbox event's timestamp.
+var timeKeys = []string{"occurred_at", "created_at", "published_at", "timestamp", "ts", "time", "start"}
+
+// Sources are the providers correlate fans out to. Nil ones are skipped.
+type Sources struct {
+	// LogQuerier searches log fields; without it, LogReader's last error is
+	// checked instead.
+	LogQuerier types.LogQuerier
+	LogReader  types.LogReader
+	// TraceQuerier fetches traces by ID; without it, TraceReader's recent
+	// traces are scanned.
+	TraceQuerier types.TraceQuerier
+	TraceReader  types.TraceReader
+	OutboxReader types.OutboxReader
+	// RedactFields extends logs.DefaultRedactFields for log fields and
+	// event payloads.
+	RedactFields []string
+	// Authorizer, when set, gates each source on the scopes of the tool
+	// that exposes it (logs.search, trace.get, events.outbox.peek, ...);
+	// sources the caller lacks a scope for are skipped.
+	Authorizer types.Authorizer
+}
+
+func (s Sources) empty() bool {
+	return s.LogQuerier == nil && s.LogReader == nil && s.TraceQuerier == nil && s.TraceReader == nil && s.OutboxReader == nil
+}
+
+type event struct {
+	ts      time.Time
+	source  string
+	summary string
+	data    any
+}
+
+// Register registers the correlate tool.
+func Register(s internal_mcp.ToolAdder, src Sources) error {
+	if src.empty() {
+		return nil // Tool not registered without any source
+	}
+	red := logs.NewRedactor(src.RedactFields)
+
+	tool := mcp.NewTool(
+		"correlate",
+		mcp.WithDescription("Given a trace, request or aggregate ID, gather matching log entries, trace spans and outbox events into one chronological timeline labelled by source."),
+		mcp.WithString("id", mcp.Required(), mcp.Description("Trace ID, request ID or aggregate ID")),
+		mcp.WithString("kind", mcp.Enum(KindAuto, KindTrace, KindRequest, KindAggregate), mcp.Description("Kind of ID (default auto: try all)")),
+		mcp.WithString("since", mcp.Description("Only log entries at or after this time: RFC 3339 or a duration ago such as \"6h\"")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum timeline entries (default %d, max %d)", defaultLimit, maxLimit))),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := strings.TrimSpace(request.GetString("id", ""))
+		if id == "" {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "missing id", nil), nil
+		}
+		kind := request.GetString("kind", KindAuto)
+		if _, ok := fieldNames[kind]; !ok && kind != KindAuto {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid kind", map[string]any{"kind": kind}), nil
+		}
+		since, err := argparse.Time(request.GetString("since", ""), time.Now())
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid since", map[string]any{"error": err.Error()}), nil
+		}
+		limit := int(mcp.ParseFloat64(request, "limit", defaultLimit))
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+		limit = min(limit, maxLimit)
+
+		c := &collector{src: src, red: red, id: id, kind: kind, since: since, limit: limit, status: make(map[string]any)}
+		c.logs(ctx)
+		c.traces(ctx)
+		c.outbox(ctx)
+
+		sort.SliceStable(c.events, func(i, j int) bool {
+			a, b := c.events[i].ts, c.events[j].ts
+			if a.IsZero() != b.IsZero() {
+				return !a.IsZero() // undated entries last
+			}
+			return a.Before(b)
+		})
+		truncated := len(c.events) > limit
+		if truncated {
+			c.events = c.events[:limit]
+		}
+
+		timeline := make([]map[string]any, len(c.events))
+		for i, e := range c.events {
+			entry := map[string]any{"source": e.source, "summary": e.summary, "data": e.data}
+			if !e.ts.IsZero() {
+				entry["ts"] = e.ts.Format(time.RFC3339Nano)
+			}
+			timeline[i] = entry
+		}
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"id":        id,
+			"kind":      kind,
+			"sources":   c.status,
+			"timeline":  timeline,
+			"count":     len(timeline),
+			"truncated": truncated,
+		})
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register correlate: %w", err)
+	}
+	return nil
+}
+
+// collector gathers timeline events. Each source reports a match count or
+// an error in status; unconfigured sources are left out.
+type collector struct {
+	src      Sources
+	red      *logs.Redactor
+	id, kind string
+	since    time.Time
+	limit    int
+
+	events   []event
+	traceIDs []string // trace IDs seen in correlated logs
+	status   map[string]any
+}
+
+func (c *collector) kinds() []string {
+	if c.kind != KindAuto {
+		return []string{c.kind}
+	}
+	return []string{KindTrace, KindRequest, KindAggregate}
+}
+
+// permitted reports whether the caller holds the scopes of tool, recording
+// the source as skipped when not.
+func (c *collector) permitted(ctx context.Context, source, tool string) bool {
+	if c.src.Authorizer == nil {
+		return true
+	}
+	if scope := security.MissingToolScope(ctx, c.src.Authorizer, tool); scope != "" {
+		c.status[source] = map[string]any{"skipped": "requires scope " + scope}
+		return false
+	}
+	return true
+}
+
+func (c *collector) logs(ctx context.Context) {
+	matches := 0
+	add := func(e types.LogEntry) {
+		matches++
+		c.events = append(c.events, event{ts: e.Timestamp, source: SourceLogs, summary: logSummary(e, c.red), data: c.red.Entry(e)})
+		for _, k := range fieldNames[KindTrace] {
+			if v, ok := e.Fields[k]; ok && fmt.Sprint(v) != c.id {
+				c.traceIDs = appendUnique(c.traceIDs, fmt.Sprint(v))
+			}
+		}
+	}
+
+	switch {
+	case c.src.LogQuerier != nil:
+		if !c.permitted(ctx, SourceLogs, "logs.search") {
+			return
+		}
+		anyFields := make(map[string]string)
+		for _, kind := range c.kinds() {
+			for _, field := range fieldNames[kind] {
+				anyFields[field] = c.id
+			}
+		}
+		page, err := c.src.LogQuerier.SearchLogs(ctx, types.LogQuery{Since: c.since, AnyFields: anyFields, Limit: c.limit})
+		if err != nil {
+			c.status[SourceLogs] = map[string]any{"error": err.Error()}
+			return
+		}
+		if page != nil {
+			for _, e := range page.Entries {
+				add(e)
+			}
+		}
+	case c.src.LogReader != nil:
+		if !c.permitted(ctx, SourceLogs, "logs.lastError") {
+			return
+		}
+		e, err := c.src.LogReader.LastError(ctx)
+		if err != nil {
+			c.status[SourceLogs] = map[string]any{"error": err.Error()}
+			return
+		}
+		if e != nil && (strings.Contains(e.Message, c.id) || contains(e.Fields, c.id)) {
+			add(*e)
+		}
+	default:
+		return
+	}
+	c.status[SourceLogs] = map[string]any{"matches": matches}
+}
+
+func (c *collector) traces(ctx context.Context) {
+	matches := 0
+	switch {
+	case c.src.TraceQuerier != nil:
+		if !c.permitted(ctx, SourceTrace, "trace.get") {
+			return
+		}
+		ids := c.traceIDs
+		if c.kind == KindAuto || c.kind == KindTrace {
+			ids = append([]string{c.id}, ids...)
+		}
+		for _, id := range ids {
+			t, err := c.src.TraceQuerier.GetTrace(ctx, id)
+			if err != nil {
+				c.status[SourceTrace] = map[string]any{"error": err.Error()}
+				return
+			}
+			if t == nil {
+				continue
+			}
+			for _, sp := range t.Spans {
+				matches++
+				c.events = append(c.events, event{ts: sp.Start, source: SourceTrace, summary: spanSummary(sp), data: spanData(sp)})
+			}
+		}
+	case c.src.TraceReader != nil:
+		if !c.permitted(ctx, SourceTrace, "trace.lookup") {
+			return
+		}
+		recent, err := c.src.TraceReader.Lookup(ctx, c.limit)
+		if err != nil {
+			c.status[SourceTrace] = map[string]any{"error": err.Error()}
+			return
+		}
+		for _, t := range recent {
+			if contains(t, c.id) || containsAny(t, c.traceIDs) {
+				matches++
+				c.events = append(c.events, event{ts: timeOf(t), source: SourceTrace, summary: "trace", data: c.red.Fields(t)})
+			}
+		}
+	default:
+		return
+	}
+	c.status[SourceTrace] = map[string]any{"matches": matches}
+}
+
+// outbox looks aggregate IDs up through types.OutboxQuerier when the reader
+// implements it, and otherwise scans the most recent events for the ID.
+func (c *collector) outbox(ctx context.Context) {
+	if c.src.OutboxReader == nil || !c.permitted(ctx, SourceOutbox, "events.outbox.peek") {
+		return
+	}
+	matches := 0
+	seen := make(map[string]bool)
+	q, _ := c.src.OutboxReader.(types.OutboxQuerier)
+	if q != nil && (c.kind == KindAuto || c.kind == KindAggregate) {
+		page, err := q.QueryOutbox(ctx, types.OutboxQuery{AggregateID: c.id, Since: c.since, Limit: c.limit})
+		if err != nil {
+			c.status[SourceOutbox] = map[string]any{"error": err.Error()}
+			return
+		}
+		if page != nil {
+			for _, ev := range page.Events {
+				matches++
+				seen[ev.ID] = true
+				c.events = append(c.events, event{ts: ev.CreatedAt, source: SourceOutbox, summary: ev.Type, data: c.red.Fields(outboxEventData(ev))})
+			}
+		}
+		if c.kind == KindAggregate {
+			c.status[SourceOutbox] = map[string]any{"matches": matches}
+			return
+		}
+	}
+
+	events, err := c.src.OutboxReader.Peek(ctx, outboxScan)
+	if err != nil {
+		c.status[SourceOutbox] = map[string]any{"error": err.Error()}
+		return
+	}
+	for _, ev := range events {
+		if !contains(ev, c.id) && !containsAny(ev, c.traceIDs) {
+			continue
+		}
+		if id, ok := ev["id"]; ok && seen[fmt.Sprint(id)] {
+			continue
+		}
+		matches++
+		summary := "event"
+		for _, k := range []string{"event_type", "type", "name", "topic"} {
+			if v, ok := ev[k].(string); ok && v != "" {
+				summary = v
+				break
+			}
+		}
+		c.events = append(c.events, event{ts: timeOf(ev), source: SourceOutbox, summary: summary, data: c.red.Fields(ev)})
+	}
+	c.status[SourceOutbox] = map[string]any{"matches": matches, "scanned": len(events)}
+}
+
+func outboxEventData(e types.OutboxEvent) map[string]any {
+	out := map[string]any{
+		"id":           e.ID,
+		"type":         e.Type,
+		"aggregate_id": e.AggregateID,
+		"status":       e.Status,
+		"created_at":   e.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if e.AggregateType != "" {
+		out["aggregate_type"] = e.AggregateType
+	}
+	if e.Payload != nil {
+		out["payload"] = e.Payload
+	}
+	if len(e.Headers) > 0 {
+		headers := make(map[string]any, len(e.Headers))
+		for k, v := range e.Headers {
+			headers[k] = v
+		}
+		out["headers"] = headers
+	}
+	return out
+}
+
+func logSummary(e types.LogEntry, red *logs.Redactor) string {
+	if e.Level == "" {
+		return red.Message(e.Message)
+	}
+	return "[" + e.Level + "] " + red.Message(e.Message)
+}
+
+func spanSummary(sp types.Span) string {
+	s := fmt.Sprintf("%s: %s (%s)", sp.Service, sp.Name, sp.Duration)
+	if sp.Status == types.SpanStatusError {
+		s += " ERROR"
+		if sp.StatusMessage != "" {
+			s += " " + sp.StatusMessage
+		}
+	}
+	return s
+}
+
+func spanData(sp types.Span) map[string]any {
+	out := map[string]any{
+		"trace_id":    sp.TraceID,
+		"span_id":     sp.SpanID,
+		"service":     sp.Service,
+		"name":        sp.Name,
+		"duration_ms": float64(sp.Duration) / float64(time.Millisecond),
+		"status":      sp.Status,
+	}
+	if sp.ParentSpanID != "" {
+		out["parent_span_id"] = sp.ParentSpanID
+	}
+	if sp.StatusMessage != "" {
+		out["status_message"] = sp.StatusMessage
+	}
+	return out
+}
+
+// contains reports whether any string-like value in v, at any depth, equals id.
+func contains(v any, id string) bool {
+	switch x := v.(type) {
+	case map[string]any:
+		for _, e := range x {
+			if contains(e, id) {
+				return true
+			}
+		}
+	case []any:
+		for _, e := range x {
+			if contains(e, id) {
+				return true
+			}
+		}
+	case string:
+		return x == id
+	case fmt.Stringer:
+		return x.String() == id
+	case nil, bool:
+		return false
+	default:
+		return fmt.Sprint(x) == id
+	}
+	return false
+}
+
+func containsAny(v map[string]any, ids []string) bool {
+	for _, id := range ids {
+		if contains(v, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// timeOf reads the first recognizable timestamp of an untyped record.
+func timeOf(m map[string]any) time.Time {
+	for _, k := range timeKeys {
+		switch v := m[k].(type) {
+		case time.Time:
+			return v
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
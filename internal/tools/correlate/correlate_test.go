@@ -0,0 +1,218 @@
+package correlate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+type logQuerier struct {
+	entries []types.LogEntry
+}
+
+func (l *logQuerier) SearchLogs(ctx context.Context, q types.LogQuery) (*types.LogPage, error) {
+	page := &types.LogPage{}
+	for _, e := range l.entries {
+		if q.Match(e) {
+			page.Entries = append(page.Entries, e)
+		}
+	}
+	return page, nil
+}
+
+type traceQuerier struct {
+	trace types.Trace
+}
+
+func (t *traceQuerier) GetTrace(ctx context.Context, id string) (*types.Trace, error) {
+	if id == t.trace.TraceID {
+		return &t.trace, nil
+	}
+	return nil, nil
+}
+
+func (t *traceQuerier) SearchTraces(context.Context, types.TraceQuery) ([]types.TraceSummary, error) {
+	return nil, nil
+}
+
+type outbox struct {
+	events []map[string]any
+	err    error
+}
+
+func (o *outbox) Peek(ctx context.Context, limit int) ([]map[string]any, error) {
+	return o.events, o.err
+}
+
+func TestCorrelate_Timeline(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	src := Sources{
+		LogQuerier: &logQuerier{entries: []types.LogEntry{
+			{Timestamp: t0.Add(50 * time.Millisecond), Level: "error", Message: "payment failed", Fields: map[string]any{"request_id": "req-1", "trace_id": "tr-1", "card_token": "tok"}},
+			{Timestamp: t0, Level: "info", Message: "request started", Fields: map[string]any{"requestId": "req-1"}},
+			{Timestamp: t0, Level: "info", Message: "other request", Fields: map[string]any{"request_id": "req-2"}},
+		}},
+		TraceQuerier: &traceQuerier{trace: types.Trace{TraceID: "tr-1", Spans: []types.Span{
+			{TraceID: "tr-1", SpanID: "s1", Service: "api", Name: "POST /pay", Start: t0.Add(10 * time.Millisecond), Duration: 80 * time.Millisecond, Status: types.SpanStatusError},
+		}}},
+		OutboxReader: &outbox{events: []map[string]any{
+			{"event_type": "PaymentFailed", "created_at": t0.Add(60 * time.Millisecond).Format(time.RFC3339Nano), "headers": map[string]any{"request_id": "req-1"}},
+			{"event_type": "Unrelated", "created_at": t0.Format(time.RFC3339Nano)},
+		}},
+	}
+	s := &mockToolAdder{}
+	if err := Register(s, src); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "correlate", Arguments: map[string]any{"id": "req-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := res.StructuredContent.(map[string]any)
+	timeline := out["timeline"].([]map[string]any)
+
+	want := []struct{ source, summary string }{
+		{SourceLogs, "[info] request started"},
+		{SourceTrace, "api: POST /pay (80ms) ERROR"},
+		{SourceLogs, "[error] payment failed"},
+		{SourceOutbox, "PaymentFailed"},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("timeline = %v, want %d entries", timeline, len(want))
+	}
+	for i, w := range want {
+		if timeline[i]["source"] != w.source || timeline[i]["summary"] != w.summary {
+			t.Errorf("timeline[%d] = %v, want %s %q", i, timeline[i], w.source, w.summary)
+		}
+	}
+	fields := timeline[2]["data"].(map[string]any)["fields"].(map[string]any)
+	if fields["card_token"] != runtime.MaskedValue {
+		t.Errorf("log fields not redacted: %v", fields)
+	}
+	if _, ok := out["sources"].(map[string]any)[SourceOutbox]; !ok {
+		t.Errorf("sources = %v, want outbox status", out["sources"])
+	}
+}
+
+func TestCorrelate_SkipsMissingSourcesAndReportsErrors(t *testing.T) {
+	none := &mockToolAdder{}
+	if err := Register(none, Sources{}); err != nil || none.handler != nil {
+		t.Fatalf("no sources: err = %v, registered = %v", err, none.handler != nil)
+	}
+
+	s := &mockToolAdder{}
+	if err := Register(s, Sources{OutboxReader: &outbox{err: errors.New("db down")}}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "correlate", Arguments: map[string]any{"id": "agg-1", "kind": KindAggregate}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := res.StructuredContent.(map[string]any)["sources"].(map[string]any)
+	if len(sources) != 1 || sources[SourceOutbox].(map[string]any)["error"] != "db down" {
+		t.Errorf("sources = %v, want only the outbox error", sources)
+	}
+}
+
+type scopes map[string]bool
+
+func (s scopes) HasScope(_ context.Context, scope string) bool { return s[scope] }
+
+func TestCorrelate_SkipsSourcesWithoutScope(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s := &mockToolAdder{}
+	err := Register(s, Sources{
+		LogQuerier: &logQuerier{entries: []types.LogEntry{
+			{Timestamp: t0, Level: "info", Message: "request started", Fields: map[string]any{"request_id": "req-1"}},
+		}},
+		TraceQuerier: &traceQuerier{},
+		OutboxReader: &outbox{events: []map[string]any{{"event_type": "PaymentFailed", "request_id": "req-1", "card_token": "tok"}}},
+		Authorizer:   scopes{"logs.search": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "correlate", Arguments: map[string]any{"id": "req-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := res.StructuredContent.(map[string]any)
+	sources := out["sources"].(map[string]any)
+	for source, scope := range map[string]string{SourceTrace: "trace.get", SourceOutbox: "events.outbox.peek"} {
+		if st, _ := sources[source].(map[string]any); st["skipped"] != "requires scope "+scope {
+			t.Errorf("%s status = %v, want skipped for %s", source, sources[source], scope)
+		}
+	}
+	timeline := out["timeline"].([]map[string]any)
+	if len(timeline) != 1 || timeline[0]["source"] != SourceLogs {
+		t.Errorf("timeline = %v, want only the log entry", timeline)
+	}
+}
+
+type outboxQuerier struct {
+	outbox
+	typed []types.OutboxEvent
+	query types.OutboxQuery
+}
+
+func (o *outboxQuerier) QueryOutbox(ctx context.Context, q types.OutboxQuery) (*types.OutboxPage, error) {
+	o.query = q
+	page := &types.OutboxPage{}
+	for _, e := range o.typed {
+		if q.Match(e) {
+			page.Events = append(page.Events, e)
+		}
+	}
+	return page, nil
+}
+
+func (o *outboxQuerier) OutboxStats(context.Context, time.Time) (*types.OutboxStats, error) {
+	return &types.OutboxStats{}, nil
+}
+
+func TestCorrelate_OutboxQuerierByAggregate(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ob := &outboxQuerier{
+		// Peek only sees recent events; the aggregate's events are older.
+		outbox: outbox{events: []map[string]any{{"id": "9", "event_type": "Unrelated"}}},
+		typed: []types.OutboxEvent{
+			{ID: "1", AggregateID: "order-7", Type: "OrderPlaced", CreatedAt: t0},
+			{ID: "2", AggregateID: "order-8", Type: "OrderPlaced", CreatedAt: t0},
+		},
+	}
+	s := &mockToolAdder{}
+	if err := Register(s, Sources{OutboxReader: ob}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "correlate", Arguments: map[string]any{"id": "order-7", "kind": KindAggregate}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ob.query.AggregateID != "order-7" {
+		t.Errorf("query = %+v, want AggregateID order-7", ob.query)
+	}
+	timeline := res.StructuredContent.(map[string]any)["timeline"].([]map[string]any)
+	if len(timeline) != 1 || timeline[0]["summary"] != "OrderPlaced" {
+		t.Errorf("timeline = %v, want the order-7 event", timeline)
+	}
+}
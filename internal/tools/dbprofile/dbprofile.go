@@ -0,0 +1,608 @@
+// Package dbprofile implements the db.profile tool, which summarizes the shape
+// of a table's data from a bounded random sample and the planner statistics in
+// pg_stats.
+package dbprofile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const (
+	defaultSampleRows = 20
+	maxTopValues      = 10
+)
+
+// Register registers the db.profile tool. The sample is capped at maxRows and
+// masked columns never expose values (sample, min/max or top values).
+func Register(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string, maxRows int, timeout time.Duration, mask *runtime.Masker) error {
+	if db == nil {
+		return fmt.Errorf("dbprofile: nil db")
+	}
+	if maxRows <= 0 {
+		maxRows = 500
+	}
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+
+	tool := mcp.NewTool(
+		"db.profile",
+		mcp.WithDescription("Profile a table: a bounded random sample (TABLESAMPLE) plus per-column null ratio, distinct estimate, min/max, top values (pg_stats) and value-length distribution."),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table to profile (table or schema.table; default schema: public)")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns to profile (default: all)")),
+		mcp.WithNumber("sample_rows", mcp.Description(fmt.Sprintf("Rows to sample (default: %d, capped at %d)", defaultSampleRows, maxRows))),
+	)
+
+	allowed := make(map[string]bool, len(allowSchemas))
+	for _, sc := range allowSchemas {
+		allowed[sc] = true
+	}
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		schemaName, table := splitTable(request.GetString("table", ""))
+		if table == "" {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "missing table", nil), nil
+		}
+		if strings.Contains(schemaName+table, ":") {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid table name", map[string]any{"table": schemaName + "." + table}), nil
+		}
+		if len(allowed) > 0 && !allowed[schemaName] {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "schema not allowed", map[string]any{"schema": schemaName}), nil
+		}
+		sampleRows := int(mcp.ParseFloat64(request, "sample_rows", defaultSampleRows))
+		if sampleRows <= 0 || sampleRows > maxRows {
+			sampleRows = maxRows
+		}
+
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		p := profiler{db: db, schema: schemaName, table: table, mask: mask}
+		result, err := p.run(cctx, argparse.List(request.GetString("columns", "")), sampleRows)
+		if err != nil {
+			if ie, ok := err.(inputError); ok {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, ie.msg, ie.data), nil
+			}
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "profile failed", map[string]any{"error": err.Error()}), nil
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register db.profile: %w", err)
+	}
+	return nil
+}
+
+// inputError reports a problem with the tool arguments.
+type inputError struct {
+	msg  string
+	data map[string]any
+}
+
+func (e inputError) Error() string { return e.msg }
+
+type column struct {
+	name string
+	typ  string
+}
+
+type colStats struct {
+	nullFrac  *float64
+	nDistinct *float64
+	avgWidth  *float64
+	mcv       []string
+	mcf       []float64
+	histogram []string
+}
+
+type profiler struct {
+	db     types.DBConn
+	schema string
+	table  string
+	mask   *runtime.Masker
+}
+
+func (p profiler) run(ctx context.Context, only []string, sampleRows int) (map[string]any, error) {
+	cols, err := p.columns(ctx, only)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := p.rowEstimate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := p.stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sample, method, err := p.sample(ctx, cols, sampleRows, estimate)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]map[string]any, 0, len(cols))
+	for _, c := range cols {
+		profiles = append(profiles, p.profileColumn(c, stats[c.name], sample, estimate))
+	}
+	p.mask.MaskRows(sample)
+	if sample == nil {
+		sample = []map[string]any{}
+	}
+
+	result := map[string]any{
+		"table":           p.schema + "." + p.table,
+		"row_estimate":    estimate,
+		"sample_method":   method,
+		"sample_size":     len(sample),
+		"sample":          sample,
+		"stats_available": len(stats) > 0,
+		"columns":         profiles,
+	}
+	if len(stats) == 0 {
+		result["hint"] = "pg_stats has no entries for this table; run ANALYZE for table-wide estimates (sample-based values are shown)"
+	}
+	return result, nil
+}
+
+func (p profiler) columns(ctx context.Context, only []string) ([]column, error) {
+	rows, err := p.db.Columns(ctx, p.schema, p.table)
+	if err != nil {
+		return nil, fmt.Errorf("list columns: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, inputError{msg: "table not found", data: map[string]any{"table": p.schema + "." + p.table}}
+	}
+
+	all := make([]column, 0, len(rows))
+	byName := make(map[string]column, len(rows))
+	for _, r := range rows {
+		c := column{}
+		c.name, _ = r["name"].(string)
+		c.typ, _ = r["type"].(string)
+		// sqlx named binding treats ':' as a parameter marker, even inside
+		// quoted identifiers.
+		if c.name == "" || strings.Contains(c.name, ":") {
+			continue
+		}
+		all = append(all, c)
+		byName[c.name] = c
+	}
+	if len(only) == 0 {
+		return all, nil
+	}
+
+	out := make([]column, 0, len(only))
+	var unknown []string
+	for _, name := range only {
+		c, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		out = append(out, c)
+	}
+	if len(unknown) > 0 {
+		return nil, inputError{msg: "unknown columns", data: map[string]any{"columns": unknown}}
+	}
+	return out, nil
+}
+
+func (p profiler) rowEstimate(ctx context.Context) (int64, error) {
+	rows, err := p.db.QueryJSON(ctx, `SELECT c.reltuples AS reltuples FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = :schema AND c.relname = :table`, p.params())
+	if err != nil {
+		return 0, fmt.Errorf("row estimate: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	f, ok := toFloat(rows[0]["reltuples"])
+	if !ok || f < 0 {
+		// -1 means the table was never vacuumed or analyzed.
+		return 0, nil
+	}
+	return int64(f), nil
+}
+
+func (p profiler) stats(ctx context.Context) (map[string]colStats, error) {
+	rows, err := p.db.QueryJSON(ctx, `SELECT attname, null_frac, n_distinct, avg_width,
+		CAST(most_common_vals AS text) AS most_common_vals,
+		CAST(most_common_freqs AS text) AS most_common_freqs,
+		CAST(histogram_bounds AS text) AS histogram_bounds
+		FROM pg_stats WHERE schemaname = :schema AND tablename = :table`, p.params())
+	if err != nil {
+		return nil, fmt.Errorf("read pg_stats: %w", err)
+	}
+
+	out := make(map[string]colStats, len(rows))
+	for _, r := range rows {
+		st := colStats{
+			nullFrac:  floatPtr(r["null_frac"]),
+			nDistinct: floatPtr(r["n_distinct"]),
+			avgWidth:  floatPtr(r["avg_width"]),
+			mcv:       parseArray(toString(r["most_common_vals"])),
+			histogram: parseArray(toString(r["histogram_bounds"])),
+		}
+		for _, f := range parseArray(toString(r["most_common_freqs"])) {
+			v, _ := strconv.ParseFloat(f, 64)
+			st.mcf = append(st.mcf, v)
+		}
+		out[toString(r["attname"])] = st
+	}
+	return out, nil
+}
+
+// sample reads up to n rows. TABLESAMPLE SYSTEM picks random pages, so the
+// percentage is sized from the row estimate with headroom; small or
+// never-analyzed tables are read directly.
+func (p profiler) sample(ctx context.Context, cols []column, n int, estimate int64) ([]map[string]any, string, error) {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = quoteIdent(c.name)
+	}
+	q := "SELECT " + strings.Join(names, ", ") + " FROM " + quoteIdent(p.schema) + "." + quoteIdent(p.table)
+	params := map[string]any{"__max_rows": n}
+
+	method := fmt.Sprintf("LIMIT %d", n)
+	if pct := float64(n) * 4 * 100 / float64(estimate); estimate > 0 && pct < 100 {
+		pct = math.Max(pct, 0.01)
+		q += " TABLESAMPLE SYSTEM (:__pct)"
+		params["__pct"] = pct
+		method = fmt.Sprintf("TABLESAMPLE SYSTEM (%.4g) LIMIT %d", pct, n)
+	}
+	q += " LIMIT :__max_rows"
+
+	rows, err := p.querySample(ctx, cols, q, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("sample: %w", err)
+	}
+	if len(rows) > n {
+		rows = rows[:n]
+	}
+	return rows, method, nil
+}
+
+// querySample reads rows through types.TypedQuerier when available, as
+// dbquery.run does, and otherwise normalizes QueryJSON values by column type,
+// so UUIDs, numerics, JSON and bytea are not returned as raw bytes.
+func (p profiler) querySample(ctx context.Context, cols []column, q string, params map[string]any) ([]map[string]any, error) {
+	if tq, ok := p.db.(types.TypedQuerier); ok {
+		res, err := tq.QueryTyped(ctx, q, params)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]any, len(res.Rows))
+		for i, vals := range res.Rows {
+			row := make(map[string]any, len(res.Columns))
+			for j, c := range res.Columns {
+				if j < len(vals) {
+					row[c.Name] = vals[j]
+				}
+			}
+			rows[i] = row
+		}
+		return rows, nil
+	}
+
+	rows, err := p.db.QueryJSON(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for _, c := range cols {
+			if v, ok := row[c.name]; ok {
+				row[c.name] = runtime.NormalizeValue(dbTypeName(c.typ), v)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// dbTypeName converts an information_schema data_type to the type name
+// runtime.NormalizeValue expects, as reported by DatabaseTypeName.
+func dbTypeName(dataType string) string {
+	switch dataType {
+	case "timestamp without time zone":
+		return "TIMESTAMP"
+	case "timestamp with time zone":
+		return "TIMESTAMPTZ"
+	case "double precision":
+		return "FLOAT8"
+	case "real":
+		return "FLOAT4"
+	default:
+		return strings.ToUpper(dataType)
+	}
+}
+
+func (p profiler) profileColumn(c column, st colStats, sample []map[string]any, estimate int64) map[string]any {
+	masked := p.mask.Masked(c.name)
+	out := map[string]any{"name": c.name, "type": c.typ}
+	if masked {
+		out["masked"] = true
+	}
+
+	var (
+		values []string
+		nulls  int
+	)
+	for _, row := range sample {
+		v := row[c.name]
+		if v == nil {
+			nulls++
+			continue
+		}
+		values = append(values, toString(v))
+	}
+
+	// Null ratio and distinct estimate: pg_stats covers the whole table, the
+	// sample is the fallback.
+	switch {
+	case st.nullFrac != nil:
+		out["null_ratio"] = *st.nullFrac
+	case len(sample) > 0:
+		out["null_ratio"] = float64(nulls) / float64(len(sample))
+	}
+	switch {
+	case st.nDistinct != nil && *st.nDistinct >= 0:
+		out["distinct_estimate"] = int64(*st.nDistinct)
+	case st.nDistinct != nil:
+		// Negative n_distinct is a fraction of the row count.
+		out["distinct_estimate"] = int64(math.Round(-*st.nDistinct * float64(estimate)))
+	case len(values) > 0:
+		out["distinct_in_sample"] = len(countValues(values))
+	}
+	// Widths and lengths hint at masked values (a PIN is 4 characters).
+	if masked {
+		return out
+	}
+	if st.avgWidth != nil {
+		out["avg_width_bytes"] = *st.avgWidth
+	}
+	if len(values) > 0 {
+		out["length"] = lengthDistribution(values)
+	}
+
+	// Histogram bounds exclude the most common values, so both are needed
+	// for the table-wide range.
+	bounds := append(append([]string{}, st.histogram...), st.mcv...)
+	if len(bounds) == 0 {
+		bounds = values
+	}
+	if lo, hi, ok := minMax(bounds); ok {
+		out["min"] = lo
+		out["max"] = hi
+	}
+	if top := topValues(st, values); len(top) > 0 {
+		out["top_values"] = top
+	}
+	return out
+}
+
+func topValues(st colStats, sample []string) []map[string]any {
+	var out []map[string]any
+	if len(st.mcv) > 0 && len(st.mcv) == len(st.mcf) {
+		for i, v := range st.mcv {
+			if i == maxTopValues {
+				break
+			}
+			out = append(out, map[string]any{"value": v, "frequency": st.mcf[i]})
+		}
+		return out
+	}
+	if len(sample) == 0 {
+		return nil
+	}
+
+	counts := countValues(sample)
+	keys := make([]string, 0, len(counts))
+	for k, n := range counts {
+		if n > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for i, k := range keys {
+		if i == maxTopValues {
+			break
+		}
+		out = append(out, map[string]any{"value": k, "frequency": float64(counts[k]) / float64(len(sample)), "source": "sample"})
+	}
+	return out
+}
+
+func lengthDistribution(values []string) map[string]any {
+	lengths := make([]int, len(values))
+	total := 0
+	for i, v := range values {
+		lengths[i] = utf8.RuneCountInString(v)
+		total += lengths[i]
+	}
+	sort.Ints(lengths)
+	pct := func(p float64) int { return lengths[int(math.Ceil(p*float64(len(lengths))))-1] }
+	return map[string]any{
+		"min": lengths[0],
+		"max": lengths[len(lengths)-1],
+		"avg": float64(total) / float64(len(lengths)),
+		"p50": pct(0.5),
+		"p90": pct(0.9),
+	}
+}
+
+// minMax compares numerically when every value parses as a number and
+// lexically otherwise, which orders ISO dates and timestamps correctly.
+func minMax(values []string) (string, string, bool) {
+	if len(values) == 0 {
+		return "", "", false
+	}
+	numeric := true
+	for _, v := range values {
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+	less := func(a, b string) bool { return a < b }
+	if numeric {
+		less = func(a, b string) bool {
+			x, _ := strconv.ParseFloat(a, 64)
+			y, _ := strconv.ParseFloat(b, 64)
+			return x < y
+		}
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		if less(v, lo) {
+			lo = v
+		}
+		if less(hi, v) {
+			hi = v
+		}
+	}
+	return lo, hi, true
+}
+
+func countValues(values []string) map[string]int {
+	out := make(map[string]int)
+	for _, v := range values {
+		out[v]++
+	}
+	return out
+}
+
+func (p profiler) params() map[string]any {
+	return map[string]any{"schema": p.schema, "table": p.table}
+}
+
+// parseArray parses a Postgres array literal such as {a,"b c",NULL}. Nested
+// arrays are returned as their literal text.
+func parseArray(s string) []string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return nil
+	}
+
+	var (
+		out    []string
+		cur    strings.Builder
+		quoted bool
+		inQ    bool
+		depth  int
+	)
+	flush := func() {
+		v := cur.String()
+		if !(v == "NULL" && !quoted) {
+			out = append(out, v)
+		}
+		cur.Reset()
+		quoted = false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQ && c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case c == '"' && depth == 0:
+			inQ = !inQ
+			quoted = true
+		case inQ:
+			cur.WriteByte(c)
+		case c == '{':
+			depth++
+			cur.WriteByte(c)
+		case c == '}':
+			depth--
+			cur.WriteByte(c)
+		case c == ',' && depth == 0:
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return out
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func splitTable(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "."); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return "public", s
+}
+
+func toString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+		return fmt.Sprint(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case nil:
+		return 0, false
+	default:
+		f, err := strconv.ParseFloat(toString(v), 64)
+		return f, err == nil
+	}
+}
+
+func floatPtr(v any) *float64 {
+	if f, ok := toFloat(v); ok {
+		return &f
+	}
+	return nil
+}
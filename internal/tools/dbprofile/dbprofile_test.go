@@ -0,0 +1,250 @@
+package dbprofile
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockDBConn struct {
+	reltuples float64
+	stats     []map[string]any
+	sample    []map[string]any
+	queries   []string
+	params    []map[string]any
+}
+
+func (m *mockDBConn) QueryJSON(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	m.queries = append(m.queries, query)
+	m.params = append(m.params, params)
+	switch {
+	case strings.Contains(query, "pg_class"):
+		return []map[string]any{{"reltuples": m.reltuples}}, nil
+	case strings.Contains(query, "pg_stats"):
+		return m.stats, nil
+	default:
+		return m.sample, nil
+	}
+}
+
+func (m *mockDBConn) Schemas(ctx context.Context) ([]string, error) { return []string{"public"}, nil }
+
+func (m *mockDBConn) Tables(ctx context.Context, schema string) ([]string, error) {
+	return []string{"users"}, nil
+}
+
+func (m *mockDBConn) Columns(ctx context.Context, schema, table string) ([]map[string]any, error) {
+	if table == "events" {
+		return []map[string]any{
+			{"name": "id", "type": "uuid", "nullable": false},
+			{"name": "payload", "type": "jsonb", "nullable": true},
+			{"name": "score", "type": "double precision", "nullable": true},
+		}, nil
+	}
+	if table != "users" {
+		return nil, nil
+	}
+	return []map[string]any{
+		{"name": "id", "type": "bigint", "nullable": false},
+		{"name": "email", "type": "text", "nullable": true},
+		{"name": "password", "type": "text", "nullable": false},
+	}, nil
+}
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func call(t *testing.T, adder *mockToolAdder, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := adder.handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	return result
+}
+
+func columnProfile(t *testing.T, res map[string]any, name string) map[string]any {
+	t.Helper()
+	for _, c := range res["columns"].([]map[string]any) {
+		if c["name"] == name {
+			return c
+		}
+	}
+	t.Fatalf("no profile for column %s", name)
+	return nil
+}
+
+func TestRegister_PgStats(t *testing.T) {
+	db := &mockDBConn{
+		reltuples: 100000,
+		stats: []map[string]any{
+			{"attname": "id", "null_frac": 0.0, "n_distinct": -1.0, "avg_width": 8.0, "histogram_bounds": "{1,500,99999}"},
+			{"attname": "email", "null_frac": 0.1, "n_distinct": 2.0, "avg_width": 20.0,
+				"most_common_vals": `{a@x.io,"b, c@x.io"}`, "most_common_freqs": "{0.6,0.3}"},
+			{"attname": "password", "null_frac": 0.0, "n_distinct": -1.0, "avg_width": 8.0, "most_common_vals": "{hunter2}", "most_common_freqs": "{0.5}"},
+		},
+		sample: []map[string]any{
+			{"id": int64(7), "email": "a@x.io", "password": "hunter2"},
+			{"id": int64(9), "email": nil, "password": "s3cret!"},
+		},
+	}
+	adder := &mockToolAdder{}
+	if err := Register(adder, db, []string{"public"}, 50, time.Second, runtime.NewMasker([]string{"password"})); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	result := call(t, adder, map[string]any{"table": "users", "sample_rows": float64(1000)})
+	if result.IsError {
+		t.Fatalf("unexpected error: %+v", result.Content)
+	}
+	res := result.StructuredContent.(map[string]any)
+
+	sampleQuery := db.queries[len(db.queries)-1]
+	if !strings.Contains(sampleQuery, `FROM "public"."users" TABLESAMPLE SYSTEM`) {
+		t.Errorf("sample query = %q", sampleQuery)
+	}
+	if got := db.params[len(db.params)-1]["__max_rows"]; got != 50 {
+		t.Errorf("sample limit = %v, want capped to 50", got)
+	}
+
+	id := columnProfile(t, res, "id")
+	if id["distinct_estimate"] != int64(100000) || id["min"] != "1" || id["max"] != "99999" {
+		t.Errorf("id profile = %v", id)
+	}
+	email := columnProfile(t, res, "email")
+	top := email["top_values"].([]map[string]any)
+	if email["null_ratio"] != 0.1 || len(top) != 2 || top[1]["value"] != "b, c@x.io" || top[0]["frequency"] != 0.6 {
+		t.Errorf("email profile = %v", email)
+	}
+
+	password := columnProfile(t, res, "password")
+	if password["masked"] != true || password["top_values"] != nil || password["min"] != nil || password["length"] != nil || password["avg_width_bytes"] != nil {
+		t.Errorf("password profile leaks values: %v", password)
+	}
+	for _, row := range res["sample"].([]map[string]any) {
+		if row["password"] != runtime.MaskedValue {
+			t.Errorf("sample row not masked: %v", row)
+		}
+	}
+}
+
+func TestRegister_SampleFallbackAndErrors(t *testing.T) {
+	db := &mockDBConn{
+		reltuples: -1,
+		sample: []map[string]any{
+			{"id": int64(10), "email": "abc"},
+			{"id": int64(2), "email": "abc"},
+			{"id": int64(3), "email": nil},
+		},
+	}
+	adder := &mockToolAdder{}
+	if err := Register(adder, db, []string{"public"}, 500, time.Second, nil); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	result := call(t, adder, map[string]any{"table": "public.users", "columns": "id,email"})
+	res := result.StructuredContent.(map[string]any)
+	if strings.Contains(db.queries[len(db.queries)-1], "TABLESAMPLE") {
+		t.Errorf("unanalyzed table should be read directly: %q", db.queries[len(db.queries)-1])
+	}
+	if res["stats_available"] != false || res["hint"] == nil {
+		t.Errorf("result = %v", res)
+	}
+	id := columnProfile(t, res, "id")
+	if id["min"] != "2" || id["max"] != "10" {
+		t.Errorf("id min/max = %v/%v, want numeric ordering 2/10", id["min"], id["max"])
+	}
+	email := columnProfile(t, res, "email")
+	if email["null_ratio"] != 1.0/3 || email["distinct_in_sample"] != 1 {
+		t.Errorf("email profile = %v", email)
+	}
+	length := email["length"].(map[string]any)
+	if length["min"] != 3 || length["max"] != 3 {
+		t.Errorf("email length = %v", length)
+	}
+
+	for _, args := range []map[string]any{
+		{},
+		{"table": "private.users"},
+		{"table": "missing"},
+		{"table": "users", "columns": "nope"},
+	} {
+		if r := call(t, adder, args); !r.IsError {
+			t.Errorf("args %v: expected error", args)
+		}
+	}
+}
+
+func TestParseArray(t *testing.T) {
+	got := parseArray(`{a,"b,c","d \"e\"",NULL,"NULL","{1,2}"}`)
+	want := []string{"a", "b,c", `d "e"`, "NULL", "{1,2}"}
+	if len(got) != len(want) {
+		t.Fatalf("parseArray() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseArray()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// typedDB serves the sample through types.TypedQuerier.
+type typedDB struct {
+	mockDBConn
+	result *types.QueryResult
+}
+
+func (t *typedDB) QueryTyped(ctx context.Context, query string, params map[string]any) (*types.QueryResult, error) {
+	return t.result, nil
+}
+
+func TestRegister_SampleValuesNormalized(t *testing.T) {
+	const id = "3f1c2d4e-0000-4000-8000-000000000001"
+	raw := &mockDBConn{sample: []map[string]any{
+		{"id": []byte(id), "payload": []byte(`{"sku":"A-1","qty":2}`), "score": math.NaN()},
+	}}
+	typed := &typedDB{result: &types.QueryResult{
+		Columns: []types.ColumnInfo{{Name: "id", Type: "UUID"}, {Name: "payload", Type: "JSONB"}, {Name: "score", Type: "FLOAT8"}},
+		Rows:    [][]any{{id, map[string]any{"sku": "A-1", "qty": json.Number("2")}, "NaN"}},
+	}}
+
+	for name, db := range map[string]types.DBConn{"QueryJSON": raw, "TypedQuerier": typed} {
+		adder := &mockToolAdder{}
+		if err := Register(adder, db, nil, 50, time.Second, nil); err != nil {
+			t.Fatal(err)
+		}
+		result := call(t, adder, map[string]any{"table": "events"})
+		if result.IsError {
+			t.Fatalf("%s: unexpected error: %+v", name, result.Content)
+		}
+		res := result.StructuredContent.(map[string]any)
+		if _, err := json.Marshal(res); err != nil {
+			t.Fatalf("%s: result is not JSON-encodable: %v", name, err)
+		}
+		row := res["sample"].([]map[string]any)[0]
+		payload, _ := row["payload"].(map[string]any)
+		if row["id"] != id || payload["sku"] != "A-1" || row["score"] != "NaN" {
+			t.Errorf("%s: sample row = %#v", name, row)
+		}
+	}
+}
@@ -8,16 +8,20 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
 	"github.com/next-trace/scg-boost/types"
 )
 
 type dbQueryRunInput struct {
 	Query  string         `json:"query"`
 	Params map[string]any `json:"params,omitempty"`
+	Format string         `json:"format,omitempty" jsonschema:"enum=json,enum=table,enum=csv,description=Result format: json (default) returns typed rows; table returns a markdown table; csv returns CSV with a header row"`
 }
 
-// Register registers the dbquery.run tool with read-only enforcement.
-func Register(s internal_mcp.ToolAdder, db types.DBConn, maxRows int, timeout time.Duration) error {
+// Register registers the dbquery.run tool with read-only enforcement. Values
+// of columns matched by mask are replaced in the results; mask may be nil.
+// Results carry ordered column metadata when db implements types.TypedQuerier.
+func Register(s internal_mcp.ToolAdder, db types.DBConn, maxRows int, timeout time.Duration, mask *runtime.Masker) error {
 	if db == nil {
 		return fmt.Errorf("dbquery: nil db")
 	}
@@ -43,6 +47,17 @@ func Register(s internal_mcp.ToolAdder, db types.DBConn, maxRows int, timeout ti
 			return internal_mcp.ToolError(internal_mcp.ErrCodeReadOnly, "Only SELECT/CTE queries are allowed", map[string]any{"hint": "read-only enforced"}), nil
 		}
 
+		format := strings.ToLower(strings.TrimSpace(request.GetString("format", FormatJSON)))
+		switch format {
+		case "", FormatJSON:
+			format = FormatJSON
+		case "markdown":
+			format = FormatTable
+		case FormatTable, FormatCSV:
+		default:
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "unknown format", map[string]any{"format": format, "allowed": []string{FormatJSON, FormatTable, FormatCSV}}), nil
+		}
+
 		var params map[string]any
 		if ps, ok := request.GetArguments()["params"]; ok {
 			if p, ok := ps.(map[string]any); ok {
@@ -64,14 +79,28 @@ func Register(s internal_mcp.ToolAdder, db types.DBConn, maxRows int, timeout ti
 		cctx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		rows, err := db.QueryJSON(cctx, finalQuery, finalParams)
+		res, err := query(cctx, db, finalQuery, finalParams)
 		if err != nil {
 			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "query failed", map[string]any{"error": err.Error()}), nil
 		}
-		if len(rows) > maxRows {
-			rows = rows[:maxRows]
+		if len(res.Rows) > maxRows {
+			res.Rows = res.Rows[:maxRows]
+		}
+		mask.MaskResult(res)
+		uniqueColumnNames(res)
+
+		switch format {
+		case FormatTable:
+			text := renderTable(res)
+			return mcp.NewToolResultStructured(map[string]any{"columns": res.Columns, "rowCount": len(res.Rows), "format": format, "text": text}, text), nil
+		case FormatCSV:
+			text, err := renderCSV(res)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "render csv failed", map[string]any{"error": err.Error()}), nil
+			}
+			return mcp.NewToolResultStructured(map[string]any{"columns": res.Columns, "rowCount": len(res.Rows), "format": format, "text": text}, text), nil
 		}
-		return internal_mcp.NewToolResultJSON(map[string]any{"rows": rows, "rowCount": len(rows)})
+		return internal_mcp.NewToolResultJSON(map[string]any{"columns": res.Columns, "rows": toMaps(res), "rowCount": len(res.Rows)})
 	}
 
 	if err := s.AddTool(tool, handler); err != nil {
@@ -79,3 +108,16 @@ func Register(s internal_mcp.ToolAdder, db types.DBConn, maxRows int, timeout ti
 	}
 	return nil
 }
+
+// query runs q with typed columns when db supports it, falling back to
+// QueryJSON.
+func query(ctx context.Context, db types.DBConn, q string, params map[string]any) (*types.QueryResult, error) {
+	if tq, ok := db.(types.TypedQuerier); ok {
+		return tq.QueryTyped(ctx, q, params)
+	}
+	rows, err := db.QueryJSON(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	return fromMaps(rows), nil
+}
@@ -0,0 +1,166 @@
+package dbquery
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Result formats accepted by dbquery.run.
+const (
+	FormatJSON  = "json"
+	FormatTable = "table"
+	FormatCSV   = "csv"
+)
+
+// fromMaps builds a QueryResult from QueryJSON rows for DBConns that do not
+// implement types.TypedQuerier. Column order is not known, so columns are
+// sorted by name and types are left empty.
+func fromMaps(rows []map[string]any) *types.QueryResult {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range rows {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	res := &types.QueryResult{Columns: make([]types.ColumnInfo, len(names)), Rows: make([][]any, len(rows))}
+	for i, n := range names {
+		res.Columns[i] = types.ColumnInfo{Name: n}
+	}
+	for i, row := range rows {
+		vals := make([]any, len(names))
+		for j, n := range names {
+			vals[j] = row[n]
+		}
+		res.Rows[i] = vals
+	}
+	return res
+}
+
+// uniqueColumnNames renames repeated column names, as in
+// SELECT a.id, b.id, to id, id_2, ... so rows keyed by name keep every value.
+// It runs after masking, which matches the original names.
+func uniqueColumnNames(res *types.QueryResult) {
+	used := make(map[string]bool, len(res.Columns))
+	for _, c := range res.Columns {
+		used[c.Name] = true
+	}
+	seen := make(map[string]bool, len(res.Columns))
+	for i, c := range res.Columns {
+		if !seen[c.Name] {
+			seen[c.Name] = true
+			continue
+		}
+		for n := 2; ; n++ {
+			name := fmt.Sprintf("%s_%d", c.Name, n)
+			if !used[name] {
+				used[name], seen[name] = true, true
+				res.Columns[i].Name = name
+				break
+			}
+		}
+	}
+}
+
+// toMaps converts rows to column-keyed maps for the JSON format. Column
+// names must be unique; see uniqueColumnNames.
+func toMaps(res *types.QueryResult) []map[string]any {
+	out := make([]map[string]any, len(res.Rows))
+	for i, row := range res.Rows {
+		m := make(map[string]any, len(res.Columns))
+		for j, c := range res.Columns {
+			if j < len(row) {
+				m[c.Name] = row[j]
+			}
+		}
+		out[i] = m
+	}
+	return out
+}
+
+// renderTable renders res as a GitHub-flavored markdown table. NULLs are
+// shown as NULL; pipes and newlines in values are escaped.
+func renderTable(res *types.QueryResult) string {
+	var b strings.Builder
+	b.WriteString("|")
+	for _, c := range res.Columns {
+		b.WriteString(" " + tableCell(c.Name) + " |")
+	}
+	b.WriteString("\n|")
+	for range res.Columns {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range res.Rows {
+		b.WriteString("|")
+		for _, v := range row {
+			s := "NULL"
+			if v != nil {
+				s = cellString(v)
+			}
+			b.WriteString(" " + tableCell(s) + " |")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+var tableEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func tableCell(s string) string {
+	return tableEscaper.Replace(s)
+}
+
+// renderCSV renders res as RFC 4180 CSV with a header row. NULLs are empty.
+func renderCSV(res *types.QueryResult) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(res.Columns))
+	for i, c := range res.Columns {
+		header[i] = c.Name
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, row := range res.Rows {
+		rec := make([]string, len(row))
+		for i, v := range row {
+			if v != nil {
+				rec[i] = cellString(v)
+			}
+		}
+		if err := w.Write(rec); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// cellString formats a single value; objects and arrays become compact JSON.
+func cellString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case []byte:
+		return string(x)
+	case map[string]any, []any:
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
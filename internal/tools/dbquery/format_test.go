@@ -0,0 +1,28 @@
+package dbquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+func TestUniqueColumnNames(t *testing.T) {
+	res := &types.QueryResult{
+		Columns: []types.ColumnInfo{{Name: "id"}, {Name: "id"}, {Name: "id_2"}, {Name: "id"}},
+		Rows:    [][]any{{1, 2, 3, 4}},
+	}
+	uniqueColumnNames(res)
+
+	var names []string
+	for _, c := range res.Columns {
+		names = append(names, c.Name)
+	}
+	if want := []string{"id", "id_3", "id_2", "id_4"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	want := []map[string]any{{"id": 1, "id_3": 2, "id_2": 3, "id_4": 4}}
+	if got := toMaps(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("toMaps = %v, want %v", got, want)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
 )
 
 func TestIsReadOnly(t *testing.T) {
@@ -83,7 +85,7 @@ func TestRegister_RowLimit(t *testing.T) {
 	// 3. Call Register
 	maxRows := 100
 	timeout := 3 * time.Second
-	err := Register(toolAdder, db, maxRows, timeout)
+	err := Register(toolAdder, db, maxRows, timeout, nil)
 	if err != nil {
 		t.Fatalf("Register() error = %v", err)
 	}
@@ -115,3 +117,93 @@ func TestRegister_RowLimit(t *testing.T) {
 		t.Errorf("len(rows) = %d, want %d", len(resRows), maxRows)
 	}
 }
+
+func TestRegister_MaskColumns(t *testing.T) {
+	db := &mockDBConn{rows: []map[string]any{{"id": 1, "password": "hunter2"}}}
+	toolAdder := &mockToolAdder{}
+	if err := Register(toolAdder, db, 10, time.Second, runtime.NewMasker([]string{"pass*"})); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "dbquery.run", Arguments: map[string]any{"query": "SELECT * FROM users"}}}
+	result, err := toolAdder.handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	rows := result.StructuredContent.(map[string]any)["rows"].([]map[string]any)
+	if rows[0]["password"] != runtime.MaskedValue || rows[0]["id"] != 1 {
+		t.Errorf("rows = %v, want password masked", rows)
+	}
+}
+
+type typedDBConn struct {
+	mockDBConn
+	res *types.QueryResult
+}
+
+func (m *typedDBConn) QueryTyped(ctx context.Context, query string, params map[string]any) (*types.QueryResult, error) {
+	return m.res, nil
+}
+
+func TestRegister_Formats(t *testing.T) {
+	db := &typedDBConn{res: &types.QueryResult{
+		Columns: []types.ColumnInfo{{Name: "id", Type: "INT8"}, {Name: "note", Type: "TEXT"}, {Name: "token", Type: "TEXT"}},
+		Rows: [][]any{
+			{int64(1), "a|b", "s3cret"},
+			{int64(2), nil, nil},
+		},
+	}}
+	toolAdder := &mockToolAdder{}
+	if err := Register(toolAdder, db, 10, time.Second, runtime.NewMasker([]string{"token"})); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	call := func(format string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "dbquery.run", Arguments: map[string]any{"query": "SELECT * FROM notes", "format": format}}}
+		result, err := toolAdder.handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("format %q: unexpected tool error %v", format, result.Content)
+		}
+		return result
+	}
+
+	res := call("json").StructuredContent.(map[string]any)
+	cols := res["columns"].([]types.ColumnInfo)
+	if len(cols) != 3 || cols[0].Name != "id" || cols[0].Type != "INT8" {
+		t.Errorf("columns = %v", cols)
+	}
+	if rows := res["rows"].([]map[string]any); rows[0]["token"] != runtime.MaskedValue || rows[1]["token"] != nil {
+		t.Errorf("rows = %v, want token masked", rows)
+	}
+
+	wantTable := "| id | note | token |\n| --- | --- | --- |\n| 1 | a\\|b | *** |\n| 2 | NULL | NULL |\n"
+	if got := call("table").StructuredContent.(map[string]any)["text"]; got != wantTable {
+		t.Errorf("table =\n%s\nwant\n%s", got, wantTable)
+	}
+
+	wantCSV := "id,note,token\n1,a|b,***\n2,,\n"
+	if got := call("csv").StructuredContent.(map[string]any)["text"]; got != wantCSV {
+		t.Errorf("csv =\n%s\nwant\n%s", got, wantCSV)
+	}
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "dbquery.run", Arguments: map[string]any{"query": "SELECT 1", "format": "xml"}}}
+	if result, _ := toolAdder.handler(context.Background(), req); !result.IsError {
+		t.Error("unknown format: want tool error")
+	}
+}
+
+func TestRegister_FallbackColumns(t *testing.T) {
+	db := &mockDBConn{rows: []map[string]any{{"b": 2, "a": 1}}}
+	toolAdder := &mockToolAdder{}
+	if err := Register(toolAdder, db, 10, time.Second, nil); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "dbquery.run", Arguments: map[string]any{"query": "SELECT 1", "format": "csv"}}}
+	result, _ := toolAdder.handler(context.Background(), req)
+	if got := result.StructuredContent.(map[string]any)["text"]; got != "a,b\n1,2\n" {
+		t.Errorf("csv = %q", got)
+	}
+}
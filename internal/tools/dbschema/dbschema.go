@@ -6,50 +6,35 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/schema"
 	"github.com/next-trace/scg-boost/types"
 )
 
-// Register registers the dbschema.list tool.
-func Register(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string) error {
-	if db == nil {
+// Register registers the dbschema.list tool. When snapshotPath is set and the
+// live database cannot be introspected (or db is nil), the tool answers from
+// the offline snapshot and marks the result as stale.
+func Register(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string, snapshotPath string) error {
+	if db == nil && snapshotPath == "" {
 		return fmt.Errorf("dbschema: nil db")
 	}
 
 	tool := mcp.NewTool(
 		"dbschema.list",
-		mcp.WithDescription("List tables and columns for allowed schemas."),
+		mcp.WithDescription("List tables and columns for allowed schemas. Falls back to the offline schema snapshot when the database is unreachable."),
 	)
 
 	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		schemasToList := allowSchemas
-		if len(schemasToList) == 0 {
-			var err error
-			schemasToList, err = db.Schemas(ctx)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list schemas: %v", err)), nil
-			}
+		if db == nil {
+			return snapshotResult(snapshotPath, allowSchemas, "no database configured")
 		}
 
-		var tables []map[string]any
-		for _, schema := range schemasToList {
-			tableNames, err := db.Tables(ctx, schema)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list tables for schema %s: %v", schema, err)), nil
-			}
-
-			for _, tableName := range tableNames {
-				cols, err := db.Columns(ctx, schema, tableName)
-				if err != nil {
-					return mcp.NewToolResultError(fmt.Sprintf("failed to list columns for table %s.%s: %v", schema, tableName, err)), nil
-				}
-				tables = append(tables, map[string]any{
-					"schema":  schema,
-					"table":   tableName,
-					"columns": cols,
-				})
+		tables, err := listLive(ctx, db, allowSchemas)
+		if err != nil {
+			if snapshotPath != "" {
+				return snapshotResult(snapshotPath, allowSchemas, err.Error())
 			}
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-
 		return mcp.NewToolResultJSON(map[string]any{"tables": tables})
 	}
 
@@ -58,3 +43,59 @@ func Register(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string)
 	}
 	return nil
 }
+
+func listLive(ctx context.Context, db types.DBConn, allowSchemas []string) ([]map[string]any, error) {
+	schemasToList := allowSchemas
+	if len(schemasToList) == 0 {
+		var err error
+		schemasToList, err = db.Schemas(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schemas: %w", err)
+		}
+	}
+
+	var tables []map[string]any
+	for _, sc := range schemasToList {
+		tableNames, err := db.Tables(ctx, sc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables for schema %s: %w", sc, err)
+		}
+
+		for _, tableName := range tableNames {
+			cols, err := db.Columns(ctx, sc, tableName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list columns for table %s.%s: %w", sc, tableName, err)
+			}
+			tables = append(tables, map[string]any{
+				"schema":  sc,
+				"table":   tableName,
+				"columns": cols,
+			})
+		}
+	}
+	return tables, nil
+}
+
+// snapshotResult answers dbschema.list from the offline snapshot.
+func snapshotResult(path string, allowSchemas []string, liveErr string) (*mcp.CallToolResult, error) {
+	snap, err := schema.Load(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s; snapshot unavailable: %v", liveErr, err)), nil
+	}
+
+	var tables []map[string]any
+	for _, t := range snap.Filter(allowSchemas) {
+		tables = append(tables, map[string]any{
+			"schema":  t.Schema,
+			"table":   t.Name,
+			"columns": schema.ColumnMaps(t.Columns),
+		})
+	}
+	return mcp.NewToolResultJSON(map[string]any{
+		"tables":            tables,
+		"stale":             true,
+		"source":            "snapshot",
+		"snapshot_taken_at": snap.TakenAt,
+		"live_error":        liveErr,
+	})
+}
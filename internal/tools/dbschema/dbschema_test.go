@@ -2,10 +2,15 @@ package dbschema
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/schema"
+	"github.com/next-trace/scg-boost/types"
 )
 
 type mockDBConn struct {
@@ -85,7 +90,7 @@ func TestRegister(t *testing.T) {
 
 	t.Run("allowlist disabled", func(t *testing.T) {
 		toolAdder := &mockToolAdder{}
-		err := Register(toolAdder, db, nil)
+		err := Register(toolAdder, db, nil, "")
 		if err != nil {
 			t.Fatalf("Register() error = %v", err)
 		}
@@ -112,7 +117,7 @@ func TestRegister(t *testing.T) {
 
 	t.Run("allowlist enabled", func(t *testing.T) {
 		toolAdder := &mockToolAdder{}
-		err := Register(toolAdder, db, []string{"public"})
+		err := Register(toolAdder, db, []string{"public"}, "")
 		if err != nil {
 			t.Fatalf("Register() error = %v", err)
 		}
@@ -142,3 +147,86 @@ func TestRegister(t *testing.T) {
 		}
 	})
 }
+
+func TestRegister_SnapshotFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.snapshot.json")
+	snap := &schema.Snapshot{Source: schema.SourceLive, Tables: []schema.Table{
+		{Schema: "public", Name: "users", Columns: []schema.Column{{Name: "id", Type: "bigint"}}},
+		{Schema: "private", Name: "secrets", Columns: []schema.Column{{Name: "key", Type: "text"}}},
+	}}
+	if err := schema.Save(path, snap); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	db := &mockDBConn{err: errors.New("connection refused")}
+	toolAdder := &mockToolAdder{}
+	if err := Register(toolAdder, db, []string{"public"}, path); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	result, err := toolAdder.handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected snapshot result, got error: %+v", result.Content)
+	}
+	resMap := result.StructuredContent.(map[string]any)
+	if resMap["stale"] != true {
+		t.Errorf("stale = %v, want true", resMap["stale"])
+	}
+	tables := resMap["tables"].([]map[string]any)
+	if len(tables) != 1 || tables[0]["table"] != "users" {
+		t.Errorf("tables = %v, want only public.users", tables)
+	}
+}
+
+func TestListLive_WrapsErrors(t *testing.T) {
+	cause := errors.New("connection refused")
+	for _, allow := range [][]string{nil, {"public"}} {
+		if _, err := listLive(context.Background(), &mockDBConn{err: cause}, allow); !errors.Is(err, cause) {
+			t.Errorf("listLive(%v) error = %v, want it to wrap %v", allow, err, cause)
+		}
+	}
+}
+
+func TestRegisterERD_SeedsAndHops(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.snapshot.json")
+	fk := func(name, col, ref string) []types.ForeignKeyInfo {
+		return []types.ForeignKeyInfo{{Name: name, Columns: []string{col}, RefSchema: "public", RefTable: ref, RefColumns: []string{"id"}}}
+	}
+	snap := &schema.Snapshot{Source: schema.SourceLive, Tables: []schema.Table{
+		{Schema: "public", Name: "users", Columns: []schema.Column{{Name: "id", Type: "bigint"}}},
+		{Schema: "public", Name: "orders", Columns: []schema.Column{{Name: "id", Type: "bigint"}, {Name: "user_id", Type: "bigint"}}, ForeignKeys: fk("orders_user_fk", "user_id", "users")},
+		{Schema: "public", Name: "items", Columns: []schema.Column{{Name: "id", Type: "bigint"}, {Name: "order_id", Type: "bigint"}}, ForeignKeys: fk("items_order_fk", "order_id", "orders")},
+	}}
+	if err := schema.Save(path, snap); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	toolAdder := &mockToolAdder{}
+	if err := RegisterERD(toolAdder, nil, nil, path); err != nil {
+		t.Fatalf("RegisterERD() error = %v", err)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"tables": "users", "hops": float64(1)}
+	result, err := toolAdder.handler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("handler = %+v, %v", result, err)
+	}
+	resMap := result.StructuredContent.(map[string]any)
+	if resMap["tables"] != 2 || resMap["relationships"] != 1 || resMap["stale"] != true {
+		t.Errorf("result = %v, want users+orders with one relationship from the snapshot", resMap)
+	}
+	diagram := resMap["diagram"].(string)
+	if !strings.Contains(diagram, "users ||--o{ orders") || strings.Contains(diagram, "items") {
+		t.Errorf("diagram = %s", diagram)
+	}
+
+	req.Params.Arguments = map[string]any{"format": "svg"}
+	result, _ = toolAdder.handler(context.Background(), req)
+	if !result.IsError {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,127 @@
+package dbschema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/schema"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// RegisterDrift registers the dbschema.drift tool, which compares the live
+// schema against the offline snapshot or the schema produced by replaying the
+// migration files.
+func RegisterDrift(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string, snapshotPath, migrationsDir string) error {
+	if db == nil {
+		return fmt.Errorf("dbschema: nil db")
+	}
+	if snapshotPath == "" && migrationsDir == "" {
+		return fmt.Errorf("dbschema: drift needs a snapshot path or migrations dir")
+	}
+
+	tool := mcp.NewTool(
+		"dbschema.drift",
+		mcp.WithDescription("Diff the live schema against the offline snapshot or the migration files and report added, removed and changed tables, columns and indexes."),
+		mcp.WithString("against", mcp.Enum("snapshot", "migrations"), mcp.Description("Baseline to compare against (default: snapshot if configured, otherwise migrations)")),
+	)
+
+	defaultBase := "snapshot"
+	if snapshotPath == "" {
+		defaultBase = "migrations"
+	}
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		against := request.GetString("against", defaultBase)
+
+		drift, err := Compare(ctx, db, allowSchemas, against, snapshotPath, migrationsDir)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, err.Error(), nil), nil
+		}
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"against":     against,
+			"has_changes": drift.HasChanges(),
+			"drift":       drift,
+		})
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register dbschema.drift: %w", err)
+	}
+	return nil
+}
+
+// Compare introspects db and diffs it against the chosen baseline.
+func Compare(ctx context.Context, db types.DBConn, allowSchemas []string, against, snapshotPath, migrationsDir string) (schema.Drift, error) {
+	var (
+		base *schema.Snapshot
+		err  error
+	)
+	switch against {
+	case "snapshot":
+		if snapshotPath == "" {
+			return schema.Drift{}, fmt.Errorf("no schema snapshot configured")
+		}
+		base, err = schema.Load(snapshotPath)
+	case "migrations":
+		if migrationsDir == "" {
+			return schema.Drift{}, fmt.Errorf("no migrations dir configured")
+		}
+		base, err = schema.FromMigrations(migrationsDir)
+	default:
+		return schema.Drift{}, fmt.Errorf("unknown baseline %q (want snapshot or migrations)", against)
+	}
+	if err != nil {
+		return schema.Drift{}, err
+	}
+
+	live, err := schema.Introspect(ctx, db, allowSchemas)
+	if err != nil {
+		return schema.Drift{}, fmt.Errorf("introspect live schema: %w", err)
+	}
+
+	expected := &schema.Snapshot{Source: base.Source, TakenAt: base.TakenAt, Tables: base.Filter(allowSchemas)}
+	if against == "migrations" && len(allowSchemas) == 0 {
+		// Migrations rarely create every schema the database reports
+		// (extensions, tooling), so only compare schemas they touch.
+		expected.Tables = base.Tables
+		live.Tables = (&schema.Snapshot{Tables: live.Tables}).Filter(schemasOf(base.Tables))
+	}
+	if against == "migrations" {
+		live.Tables = withoutTrackingTables(live.Tables)
+	}
+	return schema.Diff(expected, live), nil
+}
+
+// trackingTables are created by migration tools rather than by migrations.
+var trackingTables = map[string]bool{
+	"schema_migrations":      true,
+	"goose_db_version":       true,
+	"atlas_schema_revisions": true,
+}
+
+func withoutTrackingTables(tables []schema.Table) []schema.Table {
+	out := tables[:0:0]
+	for _, t := range tables {
+		if !trackingTables[t.Name] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func schemasOf(tables []schema.Table) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range tables {
+		if !seen[t.Schema] {
+			seen[t.Schema] = true
+			out = append(out, t.Schema)
+		}
+	}
+	if len(out) == 0 {
+		out = []string{"public"}
+	}
+	return out
+}
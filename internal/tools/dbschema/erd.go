@@ -0,0 +1,136 @@
+package dbschema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/schema"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// ERD resource URIs.
+const (
+	ERDResourceURI    = "scg://db/erd"
+	ERDDOTResourceURI = "scg://db/erd.dot"
+)
+
+// defaultERDHops is how far relationships are followed from seed tables.
+const defaultERDHops = 1
+
+// RegisterERD registers the dbschema.erd tool and the scg://db/erd (Mermaid)
+// and scg://db/erd.dot (Graphviz) resources. Like dbschema.list, they fall
+// back to the offline snapshot when the database cannot be introspected.
+func RegisterERD(s internal_mcp.ToolAdder, db types.DBConn, allowSchemas []string, snapshotPath string) error {
+	if db == nil && snapshotPath == "" {
+		return fmt.Errorf("dbschema: nil db")
+	}
+
+	tool := mcp.NewTool(
+		"dbschema.erd",
+		mcp.WithDescription("Render the database schema, including foreign keys, as a Mermaid erDiagram or Graphviz DOT. Filter by schema, or by seed tables plus N hops of relationships."),
+		mcp.WithString("format", mcp.Enum(schema.FormatMermaid, schema.FormatDOT), mcp.Description("Diagram format (default: mermaid)")),
+		mcp.WithString("schemas", mcp.Description("Comma-separated schemas to include (default: all allowed)")),
+		mcp.WithString("tables", mcp.Description("Comma-separated seed tables (table or schema.table); only these and their neighbours are drawn")),
+		mcp.WithNumber("hops", mcp.Description("Relationship hops to follow from seed tables (default: 1)")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := request.GetString("format", schema.FormatMermaid)
+		hops := int(mcp.ParseFloat64(request, "hops", defaultERDHops))
+		if hops < 0 {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "hops must be >= 0", map[string]any{"hops": hops}), nil
+		}
+
+		snap, liveErr, err := loadSchema(ctx, db, allowSchemas, snapshotPath)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to load schema", map[string]any{"error": err.Error()}), nil
+		}
+		tables := selectTables(snap, allowSchemas, argparse.List(request.GetString("schemas", "")), argparse.List(request.GetString("tables", "")), hops)
+
+		diagram, err := schema.RenderERD(tables, format)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, err.Error(), nil), nil
+		}
+
+		result := map[string]any{
+			"format":        format,
+			"diagram":       diagram,
+			"tables":        len(tables),
+			"relationships": len(schema.Relationships(tables)),
+		}
+		if liveErr != "" {
+			result["stale"] = true
+			result["source"] = "snapshot"
+			result["snapshot_taken_at"] = snap.TakenAt
+			result["live_error"] = liveErr
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register dbschema.erd: %w", err)
+	}
+
+	resources := []struct {
+		uri, format, mime string
+	}{
+		{ERDResourceURI, schema.FormatMermaid, "text/vnd.mermaid"},
+		{ERDDOTResourceURI, schema.FormatDOT, "text/vnd.graphviz"},
+	}
+	for _, r := range resources {
+		res := mcp.NewResource(r.uri, r.uri,
+			mcp.WithResourceDescription("Entity-relationship diagram of the database schema ("+r.format+")"),
+			mcp.WithMIMEType(r.mime),
+		)
+		if err := s.AddResource(res, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			snap, _, err := loadSchema(ctx, db, allowSchemas, snapshotPath)
+			if err != nil {
+				return nil, err
+			}
+			diagram, err := schema.RenderERD(snap.Filter(allowSchemas), r.format)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: r.mime, Text: diagram}}, nil
+		}); err != nil {
+			return fmt.Errorf("register %s: %w", r.uri, err)
+		}
+	}
+	return nil
+}
+
+// loadSchema introspects db, falling back to the snapshot at snapshotPath.
+// liveErr is non-empty when the snapshot was used.
+func loadSchema(ctx context.Context, db types.DBConn, allowSchemas []string, snapshotPath string) (_ *schema.Snapshot, liveErr string, _ error) {
+	if db != nil {
+		snap, err := schema.Introspect(ctx, db, allowSchemas)
+		if err == nil {
+			return snap, "", nil
+		}
+		liveErr = err.Error()
+	} else {
+		liveErr = "no database configured"
+	}
+	if snapshotPath == "" {
+		return nil, liveErr, errors.New(liveErr)
+	}
+	snap, err := schema.Load(snapshotPath)
+	if err != nil {
+		return nil, liveErr, fmt.Errorf("%s; snapshot unavailable: %v", liveErr, err)
+	}
+	return snap, liveErr, nil
+}
+
+// selectTables applies the allowlist, the requested schemas and the seed
+// neighbourhood, in that order.
+func selectTables(snap *schema.Snapshot, allowSchemas, schemas, seeds []string, hops int) []schema.Table {
+	tables := snap.Filter(allowSchemas)
+	if len(schemas) > 0 {
+		tables = (&schema.Snapshot{Tables: tables}).Filter(schemas)
+	}
+	return schema.Neighborhood(tables, seeds, hops)
+}
@@ -0,0 +1,509 @@
+// Package diagnose implements diagnose.snapshot, which queries every
+// configured provider concurrently and reports one incident snapshot.
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/security"
+	"github.com/next-trace/scg-boost/internal/tools/health"
+	"github.com/next-trace/scg-boost/internal/tools/logs"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Section statuses, from best to worst.
+const (
+	StatusOK          = "ok"
+	StatusWarn        = "warn"
+	StatusUnavailable = "unavailable" // the provider failed or timed out
+	StatusFail        = "fail"
+)
+
+// Overall snapshot statuses.
+const (
+	OverallHealthy  = "healthy"
+	OverallDegraded = "degraded"
+	OverallCritical = "critical"
+)
+
+const (
+	defaultTimeout = 3 * time.Second
+	maxTimeout     = 30 * time.Second
+	defaultErrors  = 5
+	defaultTraces  = 5
+	defaultWindow  = 15 * time.Minute
+	maxItems       = 50
+)
+
+var errorLevels = []string{"error", "critical", "fatal", "panic"}
+
+// Providers are the sources a snapshot draws on. Nil ones are skipped.
+type Providers struct {
+	HealthProbe     types.HealthProbe
+	HealthChecker   types.HealthChecker
+	LogReader       types.LogReader
+	LogQuerier      types.LogQuerier
+	MetricsReader   types.MetricsReader
+	MigrationReader types.MigrationReader
+	CacheInspector  types.CacheInspector
+	EnvChecker      types.EnvChecker
+	TraceReader     types.TraceReader
+	TraceQuerier    types.TraceQuerier
+	// RedactFields extends logs.DefaultRedactFields for error entries.
+	RedactFields []string
+	// Authorizer, when set, gates each section on the scopes of the tool
+	// that exposes the same data (health.status, logs.search, env.check,
+	// ...); sections the caller lacks a scope for are reported as denied.
+	Authorizer types.Authorizer
+}
+
+func (p Providers) empty() bool {
+	return p.HealthProbe == nil && p.HealthChecker == nil && p.LogReader == nil && p.LogQuerier == nil && p.MetricsReader == nil &&
+		p.MigrationReader == nil && p.CacheInspector == nil && p.EnvChecker == nil && p.TraceReader == nil && p.TraceQuerier == nil
+}
+
+// Section is one provider's part of the snapshot.
+type Section struct {
+	Status     string  `json:"status"`
+	Summary    string  `json:"summary"`
+	Data       any     `json:"data,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context) Section
+}
+
+// Register registers the diagnose.snapshot tool. It is not registered when
+// no provider is configured.
+func Register(s internal_mcp.ToolAdder, p Providers) error {
+	if p.empty() {
+		return nil
+	}
+	red := logs.NewRedactor(p.RedactFields)
+
+	tool := mcp.NewTool(
+		"diagnose.snapshot",
+		mcp.WithDescription("Collect health, recent errors, metrics, pending migrations, cache stats, env issues and recent traces in one call. Providers run concurrently with a timeout each; failures are reported inline. Returns an overall status and a markdown report."),
+		mcp.WithNumber("errors", mcp.Description(fmt.Sprintf("Number of recent errors to include (default %d)", defaultErrors))),
+		mcp.WithNumber("traces", mcp.Description(fmt.Sprintf("Number of recent traces to include (default %d)", defaultTraces))),
+		mcp.WithString("window", mcp.Description("Only include errors logged within this duration, e.g. \"1h\" (default 15m)")),
+		mcp.WithString("timeout", mcp.Description("Per-provider timeout, e.g. \"2s\" (default 3s, max 30s)")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout := defaultTimeout
+		if raw := strings.TrimSpace(request.GetString("timeout", "")); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid timeout", map[string]any{"timeout": raw}), nil
+			}
+			timeout = min(d, maxTimeout)
+		}
+		window := defaultWindow
+		if raw := strings.TrimSpace(request.GetString("window", "")); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid window", map[string]any{"window": raw}), nil
+			}
+			window = d
+		}
+		nErrors := clamp(int(mcp.ParseFloat64(request, "errors", defaultErrors)), defaultErrors)
+		nTraces := clamp(int(mcp.ParseFloat64(request, "traces", defaultTraces)), defaultTraces)
+
+		now := time.Now().UTC()
+		checks, skipped, denied := p.checks(ctx, red, nErrors, nTraces, now.Add(-window))
+		sections := runChecks(ctx, checks, timeout)
+		overall := overallStatus(sections)
+
+		report := map[string]any{
+			"status":       overall,
+			"generated_at": now.Format(time.RFC3339),
+			"sections":     sections,
+			"skipped":      skipped,
+		}
+		if len(denied) > 0 {
+			report["denied"] = denied
+		}
+		md := renderMarkdown(overall, now, sections, skipped, denied)
+		report["markdown"] = md
+		return mcp.NewToolResultStructured(report, md), nil
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register diagnose.snapshot: %w", err)
+	}
+	return nil
+}
+
+func clamp(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return min(n, maxItems)
+}
+
+// runChecks runs every check concurrently. A check that exceeds timeout or
+// panics is reported as unavailable; a provider that ignores ctx is left
+// behind rather than blocking the snapshot.
+func runChecks(ctx context.Context, checks []check, timeout time.Duration) map[string]Section {
+	out := make(map[string]Section, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			done := make(chan Section, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- unavailable(fmt.Errorf("panic: %v", r))
+					}
+				}()
+				done <- c.run(cctx)
+			}()
+
+			var sec Section
+			select {
+			case sec = <-done:
+			case <-cctx.Done():
+				sec = unavailable(fmt.Errorf("timed out after %s", timeout))
+			}
+			sec.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+			mu.Lock()
+			out[c.name] = sec
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+func unavailable(err error) Section {
+	return Section{Status: StatusUnavailable, Summary: "provider failed", Error: err.Error()}
+}
+
+// overallStatus is critical when any section fails, degraded when any warns
+// or is unavailable, and healthy otherwise.
+func overallStatus(sections map[string]Section) string {
+	overall := OverallHealthy
+	for _, s := range sections {
+		switch s.Status {
+		case StatusFail:
+			return OverallCritical
+		case StatusWarn, StatusUnavailable:
+			overall = OverallDegraded
+		}
+	}
+	return overall
+}
+
+// checks builds the checks for configured providers the caller may read,
+// lists the unconfigured ones, and maps denied ones to the missing scope.
+func (p Providers) checks(ctx context.Context, red *logs.Redactor, nErrors, nTraces int, since time.Time) ([]check, []string, map[string]string) {
+	var checks []check
+	var skipped []string
+	denied := make(map[string]string)
+	add := func(name string, configured bool, tool string, run func(ctx context.Context) Section) {
+		if !configured {
+			skipped = append(skipped, name)
+			return
+		}
+		if p.Authorizer != nil {
+			if scope := security.MissingToolScope(ctx, p.Authorizer, tool); scope != "" {
+				denied[name] = scope
+				return
+			}
+		}
+		checks = append(checks, check{name: name, run: run})
+	}
+
+	logsTool, tracesTool := "logs.lastError", "trace.lookup"
+	if p.LogQuerier != nil {
+		logsTool = "logs.search"
+	}
+	if p.TraceQuerier != nil {
+		tracesTool = "trace.search"
+	}
+	add("health", p.HealthProbe != nil || p.HealthChecker != nil, "health.status", p.health)
+	add("errors", p.LogQuerier != nil || p.LogReader != nil, logsTool, func(ctx context.Context) Section { return p.errors(ctx, red, nErrors, since) })
+	add("metrics", p.MetricsReader != nil, "metrics.summary", p.metrics)
+	add("migrations", p.MigrationReader != nil, "migrations.status", p.migrations)
+	add("cache", p.CacheInspector != nil, "cache.stats", p.cache)
+	add("env", p.EnvChecker != nil, "env.check", p.env)
+	add("traces", p.TraceQuerier != nil || p.TraceReader != nil, tracesTool, func(ctx context.Context) Section { return p.traces(ctx, nTraces) })
+	return checks, skipped, denied
+}
+
+func (p Providers) health(ctx context.Context) Section {
+	r := health.Collect(ctx, p.HealthProbe, p.HealthChecker)
+	sec := Section{Status: StatusOK, Summary: "healthy", Data: r.JSON()}
+	if p.HealthProbe != nil {
+		sec.Summary = "live and ready"
+	}
+	var problems []string
+	for _, c := range r.Checks {
+		if c.Status == types.HealthStatusUp {
+			continue
+		}
+		msg := c.Name + " " + c.Status
+		if c.Message != "" {
+			msg += ": " + c.Message
+		}
+		problems = append(problems, msg)
+	}
+	if r.CheckError != "" {
+		problems = append(problems, "checks failed: "+r.CheckError)
+	}
+	if r.ReadinessError != "" {
+		problems = append([]string{"not ready: " + r.ReadinessError}, problems...)
+	}
+	if r.LivenessError != "" {
+		problems = append([]string{"not live: " + r.LivenessError}, problems...)
+	}
+	if len(problems) > 0 {
+		sec.Summary = strings.Join(problems, "; ")
+	}
+	switch r.Status {
+	case types.HealthStatusDown:
+		sec.Status = StatusFail
+	case types.HealthStatusDegraded:
+		sec.Status = StatusWarn
+	}
+	return sec
+}
+
+// errors reports error entries logged at or after since.
+func (p Providers) errors(ctx context.Context, red *logs.Redactor, n int, since time.Time) Section {
+	var entries []types.LogEntry
+	if p.LogQuerier != nil {
+		page, err := p.LogQuerier.SearchLogs(ctx, types.LogQuery{Levels: errorLevels, Since: since, Limit: n})
+		if err != nil {
+			return unavailable(err)
+		}
+		if page != nil {
+			entries = page.Entries
+		}
+	} else {
+		e, err := p.LogReader.LastError(ctx)
+		if err != nil {
+			return unavailable(err)
+		}
+		if e != nil && !e.Timestamp.Before(since) {
+			entries = append(entries, *e)
+		}
+	}
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	if len(entries) == 0 {
+		return Section{Status: StatusOK, Summary: "no errors since " + since.Format(time.RFC3339), Data: []any{}}
+	}
+	out := make([]map[string]any, len(entries))
+	for i, e := range entries {
+		out[i] = red.Entry(e)
+	}
+	return Section{
+		Status:  StatusWarn,
+		Summary: fmt.Sprintf("%d recent error(s); latest at %s: %s", len(entries), entries[0].Timestamp.Format(time.RFC3339), red.Message(entries[0].Message)),
+		Data:    out,
+	}
+}
+
+func (p Providers) metrics(ctx context.Context) Section {
+	m, err := p.MetricsReader.Summary(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+	return Section{Status: StatusOK, Summary: fmt.Sprintf("%d metric families", metricFamilies(m)), Data: m}
+}
+
+// metricFamilies counts the metric families in a MetricsReader summary: the
+// "families" count or "metrics" map of promtext-style summaries, or else one
+// per top-level key.
+func metricFamilies(m map[string]any) int {
+	switch n := m["families"].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	if metrics, ok := m["metrics"].(map[string]any); ok {
+		return len(metrics)
+	}
+	return len(m)
+}
+
+func (p Providers) migrations(ctx context.Context) Section {
+	ms, err := p.MigrationReader.Status(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+	var pending, dirty, mismatched, missing []string
+	for _, m := range ms {
+		switch {
+		case m.Dirty:
+			dirty = append(dirty, m.Name)
+		case m.Missing:
+			missing = append(missing, m.Name)
+		case m.ChecksumMismatch:
+			mismatched = append(mismatched, m.Name)
+		case !m.Applied:
+			pending = append(pending, m.Name)
+		}
+	}
+	data := map[string]any{"total": len(ms), "pending": pending, "dirty": dirty, "checksum_mismatch": mismatched, "missing": missing}
+	var problems []string
+	for _, g := range []struct {
+		label string
+		names []string
+	}{{"dirty", dirty}, {"missing", missing}, {"changed after apply", mismatched}, {"pending", pending}} {
+		if len(g.names) > 0 {
+			problems = append(problems, fmt.Sprintf("%d %s", len(g.names), g.label))
+		}
+	}
+	sec := Section{Status: StatusOK, Summary: fmt.Sprintf("%d migration(s), all applied", len(ms)), Data: data}
+	if len(problems) > 0 {
+		sec.Status, sec.Summary = StatusWarn, strings.Join(problems, ", ")
+	}
+	if len(dirty) > 0 {
+		sec.Status = StatusFail
+	}
+	return sec
+}
+
+func (p Providers) cache(ctx context.Context) Section {
+	st, err := p.CacheInspector.Stats(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+	data := map[string]any{"hits": st.Hits, "misses": st.Misses, "keys": st.Keys, "memory_used_bytes": st.MemoryUsed}
+	summary := fmt.Sprintf("%d keys", st.Keys)
+	if total := st.Hits + st.Misses; total > 0 {
+		ratio := float64(st.Hits) / float64(total)
+		data["hit_ratio"] = ratio
+		summary += fmt.Sprintf(", %.1f%% hit ratio", ratio*100)
+	}
+	return Section{Status: StatusOK, Summary: summary, Data: data}
+}
+
+func (p Providers) env(ctx context.Context) Section {
+	issues, err := p.EnvChecker.Check(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+	counts := make(map[string]int)
+	for _, is := range issues {
+		counts[is.Severity]++
+	}
+	sec := Section{Status: StatusOK, Summary: "no issues", Data: issues}
+	switch {
+	case counts["error"] > 0:
+		sec.Status = StatusFail
+	case counts["warning"] > 0:
+		sec.Status = StatusWarn
+	}
+	if len(issues) > 0 {
+		sec.Summary = fmt.Sprintf("%d error(s), %d warning(s), %d info", counts["error"], counts["warning"], counts["info"])
+	}
+	return sec
+}
+
+func (p Providers) traces(ctx context.Context, n int) Section {
+	if p.TraceQuerier != nil {
+		sums, err := p.TraceQuerier.SearchTraces(ctx, types.TraceQuery{Limit: n})
+		if err != nil {
+			return unavailable(err)
+		}
+		if len(sums) > n {
+			sums = sums[:n]
+		}
+		withErrors := 0
+		for _, s := range sums {
+			if s.ErrorCount > 0 {
+				withErrors++
+			}
+		}
+		sec := Section{Status: StatusOK, Summary: fmt.Sprintf("%d recent trace(s)", len(sums)), Data: sums}
+		if withErrors > 0 {
+			sec.Status = StatusWarn
+			sec.Summary += fmt.Sprintf(", %d with errors", withErrors)
+		}
+		return sec
+	}
+	recent, err := p.TraceReader.Lookup(ctx, n)
+	if err != nil {
+		return unavailable(err)
+	}
+	return Section{Status: StatusOK, Summary: fmt.Sprintf("%d recent trace(s)", len(recent)), Data: recent}
+}
+
+// sectionOrder fixes the report layout.
+var sectionOrder = []string{"health", "errors", "migrations", "env", "traces", "metrics", "cache"}
+
+func renderMarkdown(overall string, at time.Time, sections map[string]Section, skipped []string, denied map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Diagnostic snapshot\n\nOverall: **%s** (%s)\n\n", overall, at.Format(time.RFC3339))
+	b.WriteString("| Section | Status | Summary |\n| --- | --- | --- |\n")
+	for _, n := range sectionOrder {
+		s, ok := sections[n]
+		if !ok {
+			continue
+		}
+		summary := s.Summary
+		if s.Error != "" {
+			summary += ": " + s.Error
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", n, s.Status, mdCell(summary))
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\nNot configured: %s\n", strings.Join(skipped, ", "))
+	}
+	if len(denied) > 0 {
+		var names []string
+		for _, n := range sectionOrder {
+			if scope, ok := denied[n]; ok {
+				names = append(names, fmt.Sprintf("%s (requires %s)", n, scope))
+			}
+		}
+		fmt.Fprintf(&b, "\nNot permitted: %s\n", strings.Join(names, ", "))
+	}
+
+	if errs, ok := sections["errors"].Data.([]map[string]any); ok && len(errs) > 0 {
+		b.WriteString("\n## Recent errors\n\n")
+		for _, e := range errs {
+			fmt.Fprintf(&b, "- %v [%v] %v\n", e["ts"], e["lvl"], e["msg"])
+		}
+	}
+	if m, ok := sections["migrations"].Data.(map[string]any); ok {
+		for _, key := range []string{"dirty", "missing", "checksum_mismatch", "pending"} {
+			if names, _ := m[key].([]string); len(names) > 0 {
+				fmt.Fprintf(&b, "\n## Migrations: %s\n\n- %s\n", strings.ReplaceAll(key, "_", " "), strings.Join(names, "\n- "))
+			}
+		}
+	}
+	if issues, ok := sections["env"].Data.([]types.EnvIssue); ok && len(issues) > 0 {
+		b.WriteString("\n## Environment issues\n\n")
+		for _, is := range issues {
+			fmt.Fprintf(&b, "- %s `%s`: %s\n", is.Severity, is.Key, is.Message)
+		}
+	}
+	return b.String()
+}
+
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,231 @@
+package diagnose
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+type probe struct{ live, ready error }
+
+func (p probe) Liveness(context.Context) error  { return p.live }
+func (p probe) Readiness(context.Context) error { return p.ready }
+
+type lastError struct{ entry *types.LogEntry }
+
+func (l lastError) LastError(context.Context) (*types.LogEntry, error) { return l.entry, nil }
+
+type migrations []types.MigrationStatus
+
+func (m migrations) Status(context.Context) ([]types.MigrationStatus, error) { return m, nil }
+
+type envIssues []types.EnvIssue
+
+func (e envIssues) Check(context.Context) ([]types.EnvIssue, error) { return e, nil }
+
+type failingMetrics struct{}
+
+func (failingMetrics) Summary(context.Context) (map[string]any, error) {
+	return nil, errors.New("prometheus unreachable")
+}
+
+// slowCache ignores its context, like a misbehaving provider.
+type slowCache struct{}
+
+func (slowCache) Stats(context.Context) (types.CacheStats, error) {
+	time.Sleep(time.Second)
+	return types.CacheStats{}, nil
+}
+
+type panicTraces struct{}
+
+func (panicTraces) Lookup(context.Context, int) ([]map[string]any, error) { panic("boom") }
+
+func call(t *testing.T, p Providers, args map[string]any) map[string]any {
+	t.Helper()
+	s := &mockToolAdder{}
+	if err := Register(s, p); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "diagnose.snapshot", Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool error: %v", res.Content)
+	}
+	return res.StructuredContent.(map[string]any)
+}
+
+func TestSnapshot_Degraded(t *testing.T) {
+	out := call(t, Providers{
+		HealthProbe: probe{ready: errors.New("db pool exhausted")},
+		LogReader:   lastError{entry: &types.LogEntry{Timestamp: time.Now(), Level: "error", Message: "login failed password=hunter2"}},
+		MigrationReader: migrations{
+			{Name: "001_init", Applied: true},
+			{Name: "002_orders", Applied: false},
+		},
+		EnvChecker:     envIssues{{Key: "CACHE_TTL", Severity: "warning", Message: "unset"}},
+		MetricsReader:  failingMetrics{},
+		CacheInspector: slowCache{},
+		TraceReader:    panicTraces{},
+	}, map[string]any{"timeout": "50ms"})
+
+	if out["status"] != OverallDegraded {
+		t.Errorf("status = %v, want %s", out["status"], OverallDegraded)
+	}
+	sections := out["sections"].(map[string]Section)
+	want := map[string]string{
+		"health":     StatusWarn,
+		"errors":     StatusWarn,
+		"migrations": StatusWarn,
+		"env":        StatusWarn,
+		"metrics":    StatusUnavailable,
+		"cache":      StatusUnavailable,
+		"traces":     StatusUnavailable,
+	}
+	for name, status := range want {
+		if sections[name].Status != status {
+			t.Errorf("%s = %+v, want status %s", name, sections[name], status)
+		}
+	}
+	if !strings.Contains(sections["health"].Summary, "db pool exhausted") {
+		t.Errorf("health summary = %q, want the readiness error", sections["health"].Summary)
+	}
+	if !strings.Contains(sections["cache"].Error, "timed out") || !strings.Contains(sections["traces"].Error, "panic") {
+		t.Errorf("cache/traces errors = %q / %q", sections["cache"].Error, sections["traces"].Error)
+	}
+
+	md := out["markdown"].(string)
+	for _, s := range []string{"Overall: **degraded**", "| metrics | unavailable | provider failed: prometheus unreachable |", "- 002_orders", "password=***"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("markdown missing %q:\n%s", s, md)
+		}
+	}
+	if strings.Contains(md, "hunter2") {
+		t.Error("markdown leaks a secret")
+	}
+}
+
+func TestSnapshot_Overall(t *testing.T) {
+	out := call(t, Providers{HealthProbe: probe{}, EnvChecker: envIssues{}}, nil)
+	if out["status"] != OverallHealthy {
+		t.Errorf("status = %v, want healthy", out["status"])
+	}
+	if skipped := out["skipped"].([]string); len(skipped) != 5 {
+		t.Errorf("skipped = %v, want 5 unconfigured providers", skipped)
+	}
+
+	out = call(t, Providers{HealthProbe: probe{live: errors.New("deadlock")}}, nil)
+	if out["status"] != OverallCritical {
+		t.Errorf("status = %v, want critical", out["status"])
+	}
+
+	out = call(t, Providers{MigrationReader: migrations{{Name: "003", Applied: true, Dirty: true}}}, nil)
+	if out["status"] != OverallCritical {
+		t.Errorf("dirty migration: status = %v, want critical", out["status"])
+	}
+
+	none := &mockToolAdder{}
+	if err := Register(none, Providers{}); err != nil || none.handler != nil {
+		t.Errorf("no providers: err = %v, registered = %v", err, none.handler != nil)
+	}
+}
+
+type components []types.HealthCheck
+
+func (c components) Checks(context.Context) ([]types.HealthCheck, error) { return c, nil }
+
+func TestSnapshot_HealthChecks(t *testing.T) {
+	out := call(t, Providers{
+		HealthProbe:   probe{},
+		HealthChecker: components{{Name: "redis", Status: types.HealthStatusDegraded, Message: "high latency"}},
+	}, nil)
+	sec := out["sections"].(map[string]Section)["health"]
+	if sec.Status != StatusWarn || sec.Summary != "redis degraded: high latency" {
+		t.Errorf("health = %+v", sec)
+	}
+
+	out = call(t, Providers{HealthChecker: components{{Name: "db", Status: types.HealthStatusDown}}}, nil)
+	if out["status"] != OverallCritical {
+		t.Errorf("db down: status = %v, want critical", out["status"])
+	}
+}
+
+type staticMetrics map[string]any
+
+func (m staticMetrics) Summary(context.Context) (map[string]any, error) { return m, nil }
+
+func TestSnapshot_MetricFamilies(t *testing.T) {
+	summary := staticMetrics{
+		"source":     "http://localhost:8080/metrics",
+		"scraped_at": "2024-03-01T12:00:00Z",
+		"families":   12,
+		"metrics":    map[string]any{},
+	}
+	sec := call(t, Providers{MetricsReader: summary}, nil)["sections"].(map[string]Section)["metrics"]
+	if sec.Summary != "12 metric families" {
+		t.Errorf("metrics summary = %q, want the family count", sec.Summary)
+	}
+}
+
+func TestSnapshot_ErrorWindow(t *testing.T) {
+	old := lastError{entry: &types.LogEntry{Timestamp: time.Now().Add(-time.Hour), Level: "error", Message: "stale"}}
+
+	sec := call(t, Providers{LogReader: old}, nil)["sections"].(map[string]Section)["errors"]
+	if sec.Status != StatusOK {
+		t.Errorf("error an hour old with default window: %+v, want ok", sec)
+	}
+	sec = call(t, Providers{LogReader: old}, map[string]any{"window": "2h"})["sections"].(map[string]Section)["errors"]
+	if sec.Status != StatusWarn {
+		t.Errorf("error an hour old with 2h window: %+v, want warn", sec)
+	}
+}
+
+type scopes map[string]bool
+
+func (s scopes) HasScope(_ context.Context, scope string) bool { return s[scope] }
+
+func TestSnapshot_DeniedSections(t *testing.T) {
+	out := call(t, Providers{
+		HealthProbe: probe{},
+		LogReader:   lastError{entry: &types.LogEntry{Timestamp: time.Now(), Level: "error", Message: "secret failure"}},
+		EnvChecker:  envIssues{{Key: "DB_PASSWORD", Severity: "error", Message: "unset"}},
+		Authorizer:  scopes{"health.status": true},
+	}, nil)
+
+	sections := out["sections"].(map[string]Section)
+	if _, ok := sections["health"]; !ok || len(sections) != 1 {
+		t.Errorf("sections = %v, want only health", sections)
+	}
+	denied := out["denied"].(map[string]string)
+	if denied["errors"] != "logs.lastError" || denied["env"] != "env.check" {
+		t.Errorf("denied = %v", denied)
+	}
+	if out["status"] != OverallHealthy {
+		t.Errorf("status = %v, want healthy from permitted sections", out["status"])
+	}
+	md := out["markdown"].(string)
+	if strings.Contains(md, "secret failure") || !strings.Contains(md, "Not permitted: errors (requires logs.lastError), env (requires env.check)") {
+		t.Errorf("markdown:\n%s", md)
+	}
+}
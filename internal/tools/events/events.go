@@ -2,32 +2,233 @@ package events
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/tools/logs"
 	"github.com/next-trace/scg-boost/types"
 )
 
-// Register registers the events.outbox.peek tool.
+const (
+	defaultLimit = 10
+	maxLimit     = 500
+	// statsWindow is the failure rate window when no since is given.
+	statsWindow = time.Hour
+)
+
+// Register registers the events.outbox.peek tool and, when or implements
+// types.OutboxQuerier, events.deadletter.peek.
 func Register(s internal_mcp.ToolAdder, or types.OutboxReader) error {
 	if or == nil {
 		return nil // Tool not registered if no outbox reader
 	}
+	q, _ := or.(types.OutboxQuerier)
+	red := logs.NewRedactor(nil)
 
 	tool := mcp.NewTool(
 		"events.outbox.peek",
-		mcp.WithDescription("Peek at the most recent events in the outbox."),
-		mcp.WithInputSchema[map[string]any](),
+		mcp.WithDescription("Peek at the most recent events in the outbox, optionally filtered by status, event type, aggregate ID and time range, with outbox stats (pending count, oldest pending age, failure rate)."),
+		mcp.WithNumber("limit", mcp.Description("Maximum events (default 10, max 500)")),
+		mcp.WithString("status", mcp.Description("Comma-separated statuses: pending, published, failed, dead_letter")),
+		mcp.WithString("type", mcp.Description("Comma-separated event types")),
+		mcp.WithString("aggregate_id", mcp.Description("Only events of this aggregate")),
+		mcp.WithString("since", mcp.Description("Created at or after: RFC 3339 or a duration ago such as 15m")),
+		mcp.WithString("until", mcp.Description("Created at or before: RFC 3339 or a duration ago")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from a previous call")),
 	)
 
 	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		limit := int(mcp.ParseFloat64(request, "limit", 10))
-		events, err := or.Peek(ctx, limit)
+		limit := clampLimit(int(mcp.ParseFloat64(request, "limit", defaultLimit)))
+		if q == nil {
+			if hasFilters(request) {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "filters require an outbox reader that implements types.OutboxQuerier", nil), nil
+			}
+			events, err := or.Peek(ctx, limit)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to peek outbox", map[string]any{"error": err.Error()}), nil
+			}
+			return internal_mcp.NewToolResultJSON(map[string]any{"events": events})
+		}
+
+		now := time.Now()
+		query, errResult := parseQuery(request, now)
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.Limit = limit
+		result, errResult := queryEvents(ctx, q, query, red)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		since := query.Since
+		if since.IsZero() {
+			since = now.Add(-statsWindow)
+		}
+		stats, err := q.OutboxStats(ctx, since)
 		if err != nil {
-			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to peek outbox", map[string]any{"error": err.Error()}), nil
+			result["stats_error"] = err.Error()
+		} else if stats != nil {
+			result["stats"] = statsJSON(stats, since, now)
 		}
-		return internal_mcp.NewToolResultJSON(map[string]any{"events": events})
+		return internal_mcp.NewToolResultJSON(result)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register events.outbox.peek: %w", err)
+	}
+	if q == nil {
+		return nil
 	}
 
-	return s.AddTool(tool, handler)
+	dlTool := mcp.NewTool(
+		"events.deadletter.peek",
+		mcp.WithDescription("List failed and dead-lettered outbox events, newest first, with attempts and the last publish error."),
+		mcp.WithNumber("limit", mcp.Description("Maximum events (default 10, max 500)")),
+		mcp.WithString("type", mcp.Description("Comma-separated event types")),
+		mcp.WithString("aggregate_id", mcp.Description("Only events of this aggregate")),
+		mcp.WithString("since", mcp.Description("Created at or after: RFC 3339 or a duration ago such as 24h")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from a previous call")),
+	)
+	dlHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, errResult := parseQuery(request, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.Statuses = []string{types.OutboxStatusFailed, types.OutboxStatusDeadLetter}
+		query.Limit = clampLimit(int(mcp.ParseFloat64(request, "limit", defaultLimit)))
+		result, errResult := queryEvents(ctx, q, query, red)
+		if errResult != nil {
+			return errResult, nil
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+	if err := s.AddTool(dlTool, dlHandler); err != nil {
+		return fmt.Errorf("register events.deadletter.peek: %w", err)
+	}
+	return nil
+}
+
+func hasFilters(request mcp.CallToolRequest) bool {
+	for _, k := range []string{"status", "type", "aggregate_id", "since", "until", "cursor"} {
+		if request.GetString(k, "") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// outboxStatuses are the values accepted by the status filter.
+var outboxStatuses = []string{types.OutboxStatusPending, types.OutboxStatusPublished, types.OutboxStatusFailed, types.OutboxStatusDeadLetter}
+
+// parseQuery reads the filter arguments shared by both tools.
+func parseQuery(request mcp.CallToolRequest, now time.Time) (types.OutboxQuery, *mcp.CallToolResult) {
+	q := types.OutboxQuery{
+		Statuses:    argparse.List(request.GetString("status", "")),
+		Types:       argparse.List(request.GetString("type", "")),
+		AggregateID: request.GetString("aggregate_id", ""),
+		Cursor:      request.GetString("cursor", ""),
+	}
+	for _, st := range q.Statuses {
+		if !slices.Contains(outboxStatuses, st) {
+			return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, fmt.Sprintf("invalid status %q: want one of %s", st, strings.Join(outboxStatuses, ", ")), map[string]any{"status": st})
+		}
+	}
+	var err error
+	if q.Since, err = argparse.Time(request.GetString("since", ""), now); err != nil {
+		return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid since", map[string]any{"error": err.Error()})
+	}
+	if q.Until, err = argparse.Time(request.GetString("until", ""), now); err != nil {
+		return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid until", map[string]any{"error": err.Error()})
+	}
+	return q, nil
+}
+
+func queryEvents(ctx context.Context, q types.OutboxQuerier, query types.OutboxQuery, red *logs.Redactor) (map[string]any, *mcp.CallToolResult) {
+	page, err := q.QueryOutbox(ctx, query)
+	if err != nil {
+		return nil, internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to query outbox", map[string]any{"error": err.Error()})
+	}
+	events := []map[string]any{}
+	result := map[string]any{}
+	if page != nil {
+		list := page.Events
+		if len(list) > query.Limit {
+			list = list[:query.Limit]
+		}
+		for _, e := range list {
+			events = append(events, eventJSON(e, red))
+		}
+		if page.NextCursor != "" {
+			result["next_cursor"] = page.NextCursor
+		}
+	}
+	result["events"] = events
+	result["count"] = len(events)
+	return result, nil
+}
+
+// eventJSON returns the tool representation of e with secrets in the
+// payload and headers redacted.
+func eventJSON(e types.OutboxEvent, red *logs.Redactor) map[string]any {
+	out := map[string]any{
+		"id":         e.ID,
+		"type":       e.Type,
+		"status":     e.Status,
+		"attempts":   e.Attempts,
+		"created_at": e.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if e.AggregateType != "" {
+		out["aggregate_type"] = e.AggregateType
+	}
+	if e.AggregateID != "" {
+		out["aggregate_id"] = e.AggregateID
+	}
+	if e.LastError != "" {
+		out["last_error"] = red.Message(e.LastError)
+	}
+	if !e.PublishedAt.IsZero() {
+		out["published_at"] = e.PublishedAt.Format(time.RFC3339Nano)
+	}
+	if e.Payload != nil {
+		out["payload"] = red.Fields(map[string]any{"payload": e.Payload})["payload"]
+	}
+	if len(e.Headers) > 0 {
+		headers := make(map[string]any, len(e.Headers))
+		for k, v := range e.Headers {
+			headers[k] = v
+		}
+		out["headers"] = red.Fields(headers)
+	}
+	return out
+}
+
+func statsJSON(st *types.OutboxStats, since, now time.Time) map[string]any {
+	out := map[string]any{
+		"pending":      st.Pending,
+		"published":    st.Published,
+		"failed":       st.Failed,
+		"dead_letters": st.DeadLetters,
+		"since":        since.Format(time.RFC3339),
+	}
+	if done := st.Published + st.Failed; done > 0 {
+		out["failure_rate"] = float64(st.Failed) / float64(done)
+	}
+	if !st.OldestPending.IsZero() {
+		out["oldest_pending"] = st.OldestPending.Format(time.RFC3339)
+		out["oldest_pending_age"] = now.Sub(st.OldestPending).Round(time.Second).String()
+	}
+	return out
+}
+
+func clampLimit(n int) int {
+	if n <= 0 {
+		return defaultLimit
+	}
+	return min(n, maxLimit)
 }
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handlers map[string]internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	if m.handlers == nil {
+		m.handlers = map[string]internal_mcp.ToolHandler{}
+	}
+	m.handlers[tool.Name] = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func (m *mockToolAdder) call(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handlers[name](context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+type legacyOutbox []map[string]any
+
+func (o legacyOutbox) Peek(_ context.Context, limit int) ([]map[string]any, error) {
+	return o[:min(limit, len(o))], nil
+}
+
+// outbox filters an in-memory list, newest first.
+type outbox struct {
+	legacyOutbox
+	events   []types.OutboxEvent
+	statsErr error
+	since    time.Time
+}
+
+func (o *outbox) QueryOutbox(_ context.Context, q types.OutboxQuery) (*types.OutboxPage, error) {
+	page := &types.OutboxPage{}
+	for _, e := range o.events {
+		if q.Match(e) {
+			if len(page.Events) == q.Limit {
+				page.NextCursor = e.ID
+				break
+			}
+			page.Events = append(page.Events, e)
+		}
+	}
+	return page, nil
+}
+
+func (o *outbox) OutboxStats(_ context.Context, since time.Time) (*types.OutboxStats, error) {
+	o.since = since
+	if o.statsErr != nil {
+		return nil, o.statsErr
+	}
+	return &types.OutboxStats{Pending: 1, OldestPending: time.Now().Add(-90 * time.Second), Published: 3, Failed: 1, DeadLetters: 1}, nil
+}
+
+func testOutbox() *outbox {
+	now := time.Now()
+	return &outbox{events: []types.OutboxEvent{
+		{ID: "4", AggregateID: "order-1", Type: "OrderShipped", Status: types.OutboxStatusPending, CreatedAt: now.Add(-90 * time.Second)},
+		{ID: "3", AggregateID: "order-2", Type: "OrderPlaced", Status: types.OutboxStatusDeadLetter, Attempts: 5, LastError: "broker rejected: token=abc123", CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "2", AggregateID: "order-1", Type: "OrderPlaced", Status: types.OutboxStatusPublished, CreatedAt: now.Add(-time.Hour), PublishedAt: now.Add(-time.Hour),
+			Payload: map[string]any{"total": 12.5, "card": map[string]any{"secret": "4242"}}, Headers: map[string]string{"Authorization": "Bearer x", "trace_id": "t1"}},
+		{ID: "1", AggregateID: "order-1", Type: "OrderCreated", Status: types.OutboxStatusPublished, CreatedAt: now.Add(-2 * time.Hour)},
+	}}
+}
+
+func TestOutboxPeek_Filters(t *testing.T) {
+	s := &mockToolAdder{}
+	ob := testOutbox()
+	if err := Register(s, ob); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, "events.outbox.peek", map[string]any{"aggregate_id": "order-1", "type": "OrderPlaced,OrderCreated", "limit": 1}).StructuredContent.(map[string]any)
+	events := out["events"].([]map[string]any)
+	if len(events) != 1 || events[0]["id"] != "2" || out["next_cursor"] != "1" {
+		t.Fatalf("events = %v, next_cursor = %v", events, out["next_cursor"])
+	}
+	e := events[0]
+	if e["headers"].(map[string]any)["Authorization"] != "***" || e["headers"].(map[string]any)["trace_id"] != "t1" {
+		t.Errorf("headers = %v", e["headers"])
+	}
+	if card := e["payload"].(map[string]any)["card"].(map[string]any); card["secret"] != "***" {
+		t.Errorf("payload = %v", e["payload"])
+	}
+
+	stats := out["stats"].(map[string]any)
+	if stats["pending"] != 1 || stats["failure_rate"] != 0.25 || stats["oldest_pending_age"] != "1m30s" {
+		t.Errorf("stats = %v", stats)
+	}
+	if d := time.Since(ob.since); d < statsWindow || d > statsWindow+time.Minute {
+		t.Errorf("stats window = %v, want 1h", d)
+	}
+
+	out = s.call(t, "events.outbox.peek", map[string]any{"status": "pending", "since": "5m"}).StructuredContent.(map[string]any)
+	if out["count"] != 1 || out["events"].([]map[string]any)[0]["id"] != "4" {
+		t.Errorf("pending since 5m = %v", out["events"])
+	}
+
+	if res := s.call(t, "events.outbox.peek", map[string]any{"since": "last week"}); !res.IsError {
+		t.Error("invalid since accepted")
+	}
+	if res := s.call(t, "events.outbox.peek", map[string]any{"status": "pending,sent"}); !res.IsError {
+		t.Error("unknown status accepted")
+	}
+
+	ob.statsErr = errors.New("stats query timed out")
+	out = s.call(t, "events.outbox.peek", nil).StructuredContent.(map[string]any)
+	if out["stats_error"] != "stats query timed out" || out["count"] != 4 {
+		t.Errorf("stats error = %v", out)
+	}
+}
+
+func TestDeadLetterPeek(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, testOutbox()); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, "events.deadletter.peek", nil).StructuredContent.(map[string]any)
+	events := out["events"].([]map[string]any)
+	if len(events) != 1 || events[0]["id"] != "3" || events[0]["attempts"] != 5 {
+		t.Fatalf("events = %v", events)
+	}
+	if events[0]["last_error"] != "broker rejected: token=***" {
+		t.Errorf("last_error = %v", events[0]["last_error"])
+	}
+}
+
+func TestOutboxPeek_Legacy(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, legacyOutbox{{"id": 1}, {"id": 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.handlers["events.deadletter.peek"]; ok {
+		t.Error("events.deadletter.peek registered without an OutboxQuerier")
+	}
+	out := s.call(t, "events.outbox.peek", map[string]any{"limit": 1}).StructuredContent.(map[string]any)
+	if events := out["events"].([]map[string]any); len(events) != 1 {
+		t.Errorf("events = %v", events)
+	}
+	if res := s.call(t, "events.outbox.peek", map[string]any{"status": "failed"}); !res.IsError {
+		t.Error("filters accepted without an OutboxQuerier")
+	}
+}
@@ -0,0 +1,722 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Violation is one schema failure at a JSON path such as $.items[0].sku.
+type Violation struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// contractSchema is a schema file in the contracts directory.
+type contractSchema struct {
+	name    string // path relative to the directory
+	root    *jsonschema.Schema
+	aliases []string
+	// warnings list keywords in the file that are not enforced.
+	warnings []string
+}
+
+// schemaSet holds the contract schemas of a directory, resolving $ref
+// between them by $id or relative file name.
+type schemaSet struct {
+	schemas []*contractSchema
+	byRef   map[string]*contractSchema
+	skipped []skippedSchema
+}
+
+// skippedSchema is a file that could not be read or parsed as a schema.
+type skippedSchema struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// loadSchemas reads every *.json file under dir. Draft-07 constructs are
+// normalized to the 2020-12 model invopop/jsonschema uses. Files that cannot
+// be read or parsed are skipped and listed in the set's skipped schemas.
+func loadSchemas(dir string) (*schemaSet, error) {
+	set := &schemaSet{byRef: map[string]*contractSchema{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		// #nosec G304 -- schema directory is configured by the host.
+		b, err := os.ReadFile(path)
+		if err != nil {
+			set.skipped = append(set.skipped, skippedSchema{Name: rel, Error: err.Error()})
+			return nil
+		}
+		root, warnings, err := parseSchema(b)
+		if err != nil {
+			set.skipped = append(set.skipped, skippedSchema{Name: rel, Error: err.Error()})
+			return nil
+		}
+		cs := &contractSchema{name: rel, root: root, warnings: warnings}
+		base := strings.TrimSuffix(strings.TrimSuffix(rel, ".json"), ".schema")
+		cs.aliases = append(cs.aliases, rel, base, filepath.Base(base))
+		if root.ID != "" {
+			cs.aliases = append(cs.aliases, string(root.ID))
+		}
+		if root.Title != "" {
+			cs.aliases = append(cs.aliases, root.Title)
+		}
+		set.schemas = append(set.schemas, cs)
+		for _, a := range []string{rel, "./" + rel, string(root.ID)} {
+			if a != "" {
+				set.byRef[a] = cs
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(set.schemas, func(i, j int) bool { return set.schemas[i].name < set.schemas[j].name })
+	return set, nil
+}
+
+// parseSchema decodes a schema file. Keywords the validator does not
+// enforce are returned as warnings rather than silently ignored.
+func parseSchema(b []byte) (*jsonschema.Schema, []string, error) {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, nil, err
+	}
+	n := &normalizer{}
+	norm, err := json.Marshal(n.schema(raw, "#"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(norm, &s); err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(n.warnings)
+	return &s, n.warnings, nil
+}
+
+// Keywords whose values are a schema, a map of schemas or a list of schemas.
+// Only these positions are normalized, so a property that happens to be
+// named "definitions" or "type" is left alone.
+var (
+	subschemaKeywords     = []string{"items", "additionalItems", "additionalProperties", "contains", "propertyNames", "not", "if", "then", "else"}
+	subschemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+	subschemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// knownKeywords are enforced by the validator, rewritten by the normalizer
+// or annotations without effect on validation. Anything else is reported.
+var knownKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$anchor": true, "$ref": true, "$defs": true, "$comment": true,
+	"definitions": true, "dependencies": true, "additionalItems": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true, "if": true, "then": true, "else": true,
+	"dependentSchemas": true, "dependentRequired": true,
+	"prefixItems": true, "items": true, "contains": true, "maxContains": true, "minContains": true,
+	"properties": true, "patternProperties": true, "additionalProperties": true, "propertyNames": true,
+	"type": true, "enum": true, "const": true, "format": true,
+	"multipleOf": true, "maximum": true, "exclusiveMaximum": true, "minimum": true, "exclusiveMinimum": true,
+	"maxLength": true, "minLength": true, "pattern": true,
+	"maxItems": true, "minItems": true, "uniqueItems": true,
+	"maxProperties": true, "minProperties": true, "required": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true,
+	"contentEncoding": true, "contentMediaType": true, "contentSchema": true,
+}
+
+// normalizer rewrites draft-07 constructs that the invopop model cannot
+// hold: definitions become $defs, type arrays become anyOf, tuple items and
+// additionalItems become prefixItems and items, and dependencies become
+// dependentRequired and dependentSchemas. It records unsupported keywords.
+type normalizer struct {
+	warnings []string
+}
+
+func (n *normalizer) schema(v any, path string) any {
+	t, ok := v.(map[string]any)
+	if !ok {
+		return v // boolean schema
+	}
+	out := make(map[string]any, len(t))
+	for k, child := range t {
+		out[k] = child
+		if !knownKeywords[k] && !strings.HasPrefix(k, "x-") {
+			n.warnings = append(n.warnings, fmt.Sprintf("%s: keyword %q is not supported and is ignored", path, k))
+		}
+	}
+	for _, k := range subschemaKeywords {
+		if child, ok := out[k]; ok {
+			if list, isList := child.([]any); isList && k == "items" {
+				out[k] = n.list(list, path+"/"+k)
+			} else {
+				out[k] = n.schema(child, path+"/"+k)
+			}
+		}
+	}
+	for _, k := range subschemaMapKeywords {
+		if m, ok := out[k].(map[string]any); ok {
+			norm := make(map[string]any, len(m))
+			for name, child := range m {
+				norm[name] = n.schema(child, path+"/"+k+"/"+pointerEscape(name))
+			}
+			out[k] = norm
+		}
+	}
+	for _, k := range subschemaListKeywords {
+		if list, ok := out[k].([]any); ok {
+			out[k] = n.list(list, path+"/"+k)
+		}
+	}
+
+	if defs, ok := out["definitions"]; ok {
+		if _, has := out["$defs"]; !has {
+			out["$defs"] = defs
+		}
+		delete(out, "definitions")
+	}
+	if ref, ok := out["$ref"].(string); ok {
+		out["$ref"] = strings.Replace(ref, "#/definitions/", "#/$defs/", 1)
+	}
+	if types, ok := out["type"].([]any); ok {
+		delete(out, "type")
+		alts := make([]any, len(types))
+		for i, typ := range types {
+			alts[i] = map[string]any{"type": typ}
+		}
+		if prev, ok := out["anyOf"]; ok {
+			out["allOf"] = append(asSlice(out["allOf"]), map[string]any{"anyOf": prev})
+		}
+		out["anyOf"] = alts
+	}
+	// Draft-07 tuples: an items array validates by position and
+	// additionalItems covers the rest; without a tuple it has no effect.
+	if tuple, ok := out["items"].([]any); ok {
+		if _, has := out["prefixItems"]; !has {
+			out["prefixItems"] = tuple
+		}
+		delete(out, "items")
+		if rest, ok := out["additionalItems"]; ok {
+			out["items"] = rest
+		}
+	}
+	delete(out, "additionalItems")
+	// Draft-07 dependencies: a list of names is dependentRequired, a schema
+	// is dependentSchemas.
+	if deps, ok := out["dependencies"].(map[string]any); ok {
+		required, _ := out["dependentRequired"].(map[string]any)
+		schemas, _ := out["dependentSchemas"].(map[string]any)
+		for name, dep := range deps {
+			if names, isList := dep.([]any); isList {
+				if required == nil {
+					required = make(map[string]any)
+				}
+				required[name] = names
+				continue
+			}
+			if schemas == nil {
+				schemas = make(map[string]any)
+			}
+			schemas[name] = n.schema(dep, path+"/dependencies/"+pointerEscape(name))
+		}
+		if required != nil {
+			out["dependentRequired"] = required
+		}
+		if schemas != nil {
+			out["dependentSchemas"] = schemas
+		}
+	}
+	delete(out, "dependencies")
+	return out
+}
+
+func (n *normalizer) list(list []any, path string) []any {
+	out := make([]any, len(list))
+	for i, child := range list {
+		out[i] = n.schema(child, fmt.Sprintf("%s/%d", path, i))
+	}
+	return out
+}
+
+// pointerEscape escapes a JSON pointer segment.
+func pointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// find returns the schema for a name or event type: a relative path, a
+// file name without .json or .schema.json, a $id or a title.
+func (set *schemaSet) find(name string) *contractSchema {
+	for _, cs := range set.schemas {
+		for _, a := range cs.aliases {
+			if strings.EqualFold(a, name) {
+				return cs
+			}
+		}
+	}
+	return nil
+}
+
+func (set *schemaSet) names() []string {
+	out := make([]string, len(set.schemas))
+	for i, cs := range set.schemas {
+		out[i] = cs.name
+	}
+	return out
+}
+
+// validator checks one document against a contract schema.
+type validator struct {
+	set        *schemaSet
+	violations []Violation
+	depth      int
+}
+
+// validate returns the violations of doc, a value decoded with UseNumber.
+func (set *schemaSet) validate(cs *contractSchema, doc any) []Violation {
+	v := &validator{set: set}
+	v.check(cs, cs.root, doc, "$")
+	return v.violations
+}
+
+func (v *validator) fail(path, keyword, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// passes reports whether doc satisfies s without recording violations.
+func (v *validator) passes(cs *contractSchema, s *jsonschema.Schema, doc any, path string) bool {
+	sub := &validator{set: v.set, depth: v.depth}
+	sub.check(cs, s, doc, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) check(cs *contractSchema, s *jsonschema.Schema, doc any, path string) {
+	if s == nil || reflect.DeepEqual(s, jsonschema.TrueSchema) {
+		return
+	}
+	if reflect.DeepEqual(s, jsonschema.FalseSchema) {
+		v.fail(path, "false", "no value is allowed here")
+		return
+	}
+	if s.Ref != "" {
+		target, tcs := v.resolve(cs, s.Ref)
+		switch {
+		case target == nil:
+			v.fail(path, "$ref", "cannot resolve %s", s.Ref)
+		case v.depth > 64:
+			v.fail(path, "$ref", "reference cycle through %s", s.Ref)
+		default:
+			v.depth++
+			v.check(tcs, target, doc, path)
+			v.depth--
+		}
+	}
+
+	if s.Type != "" && !hasType(doc, s.Type) {
+		v.fail(path, "type", "expected %s, got %s", s.Type, typeOf(doc))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, doc) {
+		v.fail(path, "enum", "%s is not one of %s", show(doc), show(s.Enum))
+	}
+	if s.Const != nil && !equalValues(s.Const, doc) {
+		v.fail(path, "const", "expected %s, got %s", show(s.Const), show(doc))
+	}
+
+	switch d := doc.(type) {
+	case json.Number:
+		v.checkNumber(s, d, path)
+	case string:
+		v.checkString(s, d, path)
+	case []any:
+		v.checkArray(cs, s, d, path)
+	case map[string]any:
+		v.checkObject(cs, s, d, path)
+	}
+
+	for _, sub := range s.AllOf {
+		v.check(cs, sub, doc, path)
+	}
+	if len(s.AnyOf) > 0 {
+		ok := false
+		for _, sub := range s.AnyOf {
+			if v.passes(cs, sub, doc, path) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			v.fail(path, "anyOf", "%s matches none of the allowed schemas", show(doc))
+		}
+	}
+	if len(s.OneOf) > 0 {
+		n := 0
+		for _, sub := range s.OneOf {
+			if v.passes(cs, sub, doc, path) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.fail(path, "oneOf", "%s matches %d schemas, want exactly one", show(doc), n)
+		}
+	}
+	if s.Not != nil && v.passes(cs, s.Not, doc, path) {
+		v.fail(path, "not", "%s matches a disallowed schema", show(doc))
+	}
+	if s.If != nil {
+		if v.passes(cs, s.If, doc, path) {
+			v.check(cs, s.Then, doc, path)
+		} else {
+			v.check(cs, s.Else, doc, path)
+		}
+	}
+}
+
+// resolve follows a local (#/$defs/x) or cross-file (file.json#/$defs/x)
+// reference.
+func (v *validator) resolve(cs *contractSchema, ref string) (*jsonschema.Schema, *contractSchema) {
+	file, frag, _ := strings.Cut(ref, "#")
+	if file != "" {
+		next := v.set.byRef[file]
+		if next == nil {
+			dir := filepath.ToSlash(filepath.Dir(cs.name))
+			next = v.set.byRef[strings.TrimPrefix(filepath.ToSlash(filepath.Join(dir, file)), "./")]
+		}
+		if next == nil {
+			return nil, nil
+		}
+		cs = next
+	}
+	s := cs.root
+	for _, part := range strings.Split(strings.Trim(frag, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		switch {
+		case part == "$defs":
+			continue
+		case s.Definitions[part] != nil:
+			s = s.Definitions[part]
+		case part == "properties" || part == "items":
+			if part == "items" && s.Items != nil {
+				s = s.Items
+			}
+			continue
+		case s.Properties != nil:
+			p, ok := s.Properties.Get(part)
+			if !ok {
+				return nil, nil
+			}
+			s = p
+		default:
+			return nil, nil
+		}
+	}
+	return s, cs
+}
+
+func (v *validator) checkNumber(s *jsonschema.Schema, n json.Number, path string) {
+	f, err := n.Float64()
+	if err != nil {
+		return
+	}
+	limit := func(bound json.Number, keyword string, bad func(f, b float64) bool, rel string) {
+		if bound == "" {
+			return
+		}
+		b, err := bound.Float64()
+		if err == nil && bad(f, b) {
+			v.fail(path, keyword, "%s must be %s %s", n, rel, bound)
+		}
+	}
+	limit(s.Minimum, "minimum", func(f, b float64) bool { return f < b }, ">=")
+	limit(s.Maximum, "maximum", func(f, b float64) bool { return f > b }, "<=")
+	limit(s.ExclusiveMinimum, "exclusiveMinimum", func(f, b float64) bool { return f <= b }, ">")
+	limit(s.ExclusiveMaximum, "exclusiveMaximum", func(f, b float64) bool { return f >= b }, "<")
+	if s.MultipleOf != "" {
+		if m, err := s.MultipleOf.Float64(); err == nil && m > 0 {
+			if q := f / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				v.fail(path, "multipleOf", "%s is not a multiple of %s", n, s.MultipleOf)
+			}
+		}
+	}
+}
+
+func (v *validator) checkString(s *jsonschema.Schema, str, path string) {
+	n := uint64(utf8.RuneCountInString(str))
+	if s.MinLength != nil && n < *s.MinLength {
+		v.fail(path, "minLength", "length %d is shorter than %d", n, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		v.fail(path, "maxLength", "length %d is longer than %d", n, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			v.fail(path, "pattern", "%q does not match %s", str, s.Pattern)
+		}
+	}
+	if s.Format != "" && !validFormat(s.Format, str) {
+		v.fail(path, "format", "%q is not a valid %s", str, s.Format)
+	}
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks common formats; unknown formats are annotations.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		return err == nil
+	case "email":
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	case "uuid":
+		return uuidRe.MatchString(s)
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	default:
+		return true
+	}
+}
+
+func (v *validator) checkArray(cs *contractSchema, s *jsonschema.Schema, arr []any, path string) {
+	n := uint64(len(arr))
+	if s.MinItems != nil && n < *s.MinItems {
+		v.fail(path, "minItems", "%d items, want at least %d", n, *s.MinItems)
+	}
+	if s.MaxItems != nil && n > *s.MaxItems {
+		v.fail(path, "maxItems", "%d items, want at most %d", n, *s.MaxItems)
+	}
+	if s.UniqueItems {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equalValues(arr[i], arr[j]) {
+					v.fail(path, "uniqueItems", "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+	for i, item := range arr {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(s.PrefixItems) {
+			v.check(cs, s.PrefixItems[i], item, p)
+		} else {
+			v.check(cs, s.Items, item, p)
+		}
+	}
+	if s.Contains != nil {
+		found := uint64(0)
+		for i, item := range arr {
+			if v.passes(cs, s.Contains, item, fmt.Sprintf("%s[%d]", path, i)) {
+				found++
+			}
+		}
+		minC := uint64(1)
+		if s.MinContains != nil {
+			minC = *s.MinContains
+		}
+		if found < minC {
+			v.fail(path, "contains", "%d items match, want at least %d", found, minC)
+		}
+		if s.MaxContains != nil && found > *s.MaxContains {
+			v.fail(path, "maxContains", "%d items match, want at most %d", found, *s.MaxContains)
+		}
+	}
+}
+
+func (v *validator) checkObject(cs *contractSchema, s *jsonschema.Schema, obj map[string]any, path string) {
+	n := uint64(len(obj))
+	if s.MinProperties != nil && n < *s.MinProperties {
+		v.fail(path, "minProperties", "%d properties, want at least %d", n, *s.MinProperties)
+	}
+	if s.MaxProperties != nil && n > *s.MaxProperties {
+		v.fail(path, "maxProperties", "%d properties, want at most %d", n, *s.MaxProperties)
+	}
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(propPath(path, name), "required", "missing required property")
+		}
+	}
+	for name, ds := range s.DependentSchemas {
+		if _, ok := obj[name]; ok {
+			v.check(cs, ds, obj, path)
+		}
+	}
+	for name, deps := range s.DependentRequired {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		for _, dep := range deps {
+			if _, ok := obj[dep]; !ok {
+				v.fail(propPath(path, dep), "dependentRequired", "required when %s is present", name)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := propPath(path, k)
+		matched := false
+		if s.Properties != nil {
+			if ps, ok := s.Properties.Get(k); ok {
+				matched = true
+				v.check(cs, ps, obj[k], p)
+			}
+		}
+		for pattern, ps := range s.PatternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(k) {
+				matched = true
+				v.check(cs, ps, obj[k], p)
+			}
+		}
+		if !matched && s.AdditionalProperties != nil {
+			if reflect.DeepEqual(s.AdditionalProperties, jsonschema.FalseSchema) {
+				v.fail(p, "additionalProperties", "property is not allowed by the schema")
+			} else {
+				v.check(cs, s.AdditionalProperties, obj[k], p)
+			}
+		}
+		if s.PropertyNames != nil && !v.passes(cs, s.PropertyNames, k, p) {
+			v.fail(p, "propertyNames", "property name %q is not allowed", k)
+		}
+	}
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func propPath(path, name string) string {
+	if identRe.MatchString(name) {
+		return path + "." + name
+	}
+	return fmt.Sprintf("%s[%q]", path, name)
+}
+
+func hasType(doc any, typ string) bool {
+	switch typ {
+	case "integer":
+		n, ok := doc.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := doc.(json.Number)
+		return ok
+	default:
+		return typeOf(doc) == typ
+	}
+}
+
+func typeOf(doc any) string {
+	switch doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+// equalValues compares JSON values, treating numbers numerically.
+func equalValues(a, b any) bool {
+	return bytes.Equal(canonical(a), canonical(b))
+}
+
+func canonical(v any) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(numbersToFloat(v))
+	return buf.Bytes()
+}
+
+func numbersToFloat(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return t.String()
+		}
+		return f
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = numbersToFloat(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = numbersToFloat(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func containsValue(list []any, v any) bool {
+	for _, e := range list {
+		if equalValues(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func show(v any) string {
+	s := strings.TrimSpace(string(canonical(v)))
+	if len(s) > 80 {
+		s = s[:77] + "..."
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		doc     string
+		keyword string // empty when doc is valid
+	}{
+		{"const", `{"const": "v1"}`, `"v2"`, "const"},
+		{"integer accepts 2.0", `{"type": "integer"}`, `2.0`, ""},
+		{"multipleOf", `{"multipleOf": 0.5}`, `1.25`, "multipleOf"},
+		{"exclusiveMaximum", `{"exclusiveMaximum": 10}`, `10`, "exclusiveMaximum"},
+		{"maxLength counts runes", `{"maxLength": 3}`, `"äöü"`, ""},
+		{"date-time", `{"format": "date-time"}`, `"2024-13-01T00:00:00Z"`, "format"},
+		{"unknown format", `{"format": "iban"}`, `"x"`, ""},
+		{"uniqueItems", `{"uniqueItems": true}`, `[1, 1.0]`, "uniqueItems"},
+		{"prefixItems", `{"prefixItems": [{"type": "string"}], "items": {"type": "number"}}`, `["a", 1, "b"]`, "type"},
+		{"contains", `{"contains": {"const": 3}}`, `[1, 2]`, "contains"},
+		{"oneOf", `{"oneOf": [{"minimum": 0}, {"maximum": 10}]}`, `5`, "oneOf"},
+		{"not", `{"not": {"type": "null"}}`, `null`, "not"},
+		{"if then", `{"if": {"properties": {"kind": {"const": "card"}}}, "then": {"required": ["last4"]}}`, `{"kind": "card"}`, "required"},
+		{"if else", `{"if": {"properties": {"kind": {"const": "card"}}}, "then": {"required": ["last4"]}}`, `{"kind": "cash"}`, ""},
+		{"dependentRequired", `{"dependentRequired": {"refund": ["reason"]}}`, `{"refund": 1}`, "dependentRequired"},
+		{"patternProperties", `{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`, `{"x-a": 1}`, "type"},
+		{"$defs ref", `{"$defs": {"id": {"type": "string"}}, "properties": {"id": {"$ref": "#/$defs/id"}}}`, `{"id": 1}`, "type"},
+		{"unresolved ref", `{"$ref": "#/$defs/missing"}`, `1`, "$ref"},
+		{"draft-07 tuple", `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`, `["a", 1, true]`, "false"},
+		{"draft-07 tuple item", `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}}`, `["a", "b", true]`, "type"},
+		{"draft-07 tuple rest", `{"items": [{"type": "string"}], "additionalItems": {"type": "boolean"}}`, `["a", true, false]`, ""},
+		{"dependentSchemas", `{"dependentSchemas": {"refund": {"required": ["reason"]}}}`, `{"refund": 1}`, "required"},
+		{"dependentSchemas absent", `{"dependentSchemas": {"refund": {"required": ["reason"]}}}`, `{"total": 1}`, ""},
+		{"draft-07 dependencies list", `{"dependencies": {"refund": ["reason"]}}`, `{"refund": 1}`, "dependentRequired"},
+		{"draft-07 dependencies schema", `{"dependencies": {"refund": {"properties": {"reason": {"type": "string"}}}}}`, `{"refund": 1, "reason": 2}`, "type"},
+		{"property named definitions", `{"properties": {"definitions": {"type": "array"}}, "required": ["definitions"]}`, `{"definitions": "x"}`, "type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, warnings, err := parseSchema([]byte(tt.schema))
+			if err != nil || len(warnings) > 0 {
+				t.Fatalf("parseSchema() warnings = %v, error = %v", warnings, err)
+			}
+			cs := &contractSchema{name: "test.json", root: root}
+			set := &schemaSet{schemas: []*contractSchema{cs}, byRef: map[string]*contractSchema{}}
+			doc, err := normalizePayload([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := set.validate(cs, doc)
+			switch {
+			case tt.keyword == "" && len(got) > 0:
+				t.Errorf("want valid, got %v", got)
+			case tt.keyword != "" && (len(got) == 0 || got[0].Keyword != tt.keyword):
+				t.Errorf("want %s violation, got %v", tt.keyword, got)
+			}
+		})
+	}
+}
+
+func TestParseSchema_UnsupportedKeywords(t *testing.T) {
+	_, warnings, err := parseSchema([]byte(`{
+		"properties": {"order": {"unevaluatedProperties": false, "x-owner": "billing"}},
+		"$dynamicRef": "#meta"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`#/properties/order: keyword "unevaluatedProperties" is not supported and is ignored`,
+		`#: keyword "$dynamicRef" is not supported and is ignored`,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("warnings = %q, want %q", warnings, want)
+	}
+	for _, w := range want {
+		if !slices.Contains(warnings, w) {
+			t.Errorf("warnings = %q, missing %q", warnings, w)
+		}
+	}
+}
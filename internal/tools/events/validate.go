@@ -0,0 +1,217 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/tools/logs"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultSchemasDir is where contract schemas are read from, relative to
+// the project root.
+const DefaultSchemasDir = "contracts/events"
+
+const defaultValidateLimit = 20
+
+// RegisterValidate registers the events.validate tool, which checks a sample
+// payload or recent outbox payloads against the JSON schemas in dir.
+// Schemas are reloaded on every call so contract updates apply immediately.
+// or may be nil, in which case only samples can be validated.
+func RegisterValidate(s internal_mcp.ToolAdder, or types.OutboxReader, dir string) error {
+	if dir == "" {
+		return nil // Tool not registered without a schema directory
+	}
+	red := logs.NewRedactor(nil)
+
+	tool := mcp.NewTool(
+		"events.validate",
+		mcp.WithDescription("Validate event payloads against the contract JSON schemas. Checks a supplied sample, or the most recent outbox events matched to schemas by event type, and reports per-field violations."),
+		mcp.WithString("schema", mcp.Description("Schema name, file, $id or title (default: the event type)")),
+		mcp.WithObject("payload", mcp.Description("Sample payload to validate instead of outbox events")),
+		mcp.WithString("type", mcp.Description("Comma-separated event types to validate")),
+		mcp.WithString("event_id", mcp.Description("Validate only this outbox event")),
+		mcp.WithNumber("limit", mcp.Description("Maximum outbox events to check (default 20, max 500)")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		set, err := loadSchemas(dir)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to load event schemas", map[string]any{"error": err.Error(), "dir": dir}), nil
+		}
+		schemaName := request.GetString("schema", "")
+		eventTypes := argparse.List(request.GetString("type", ""))
+
+		var results []map[string]any
+		if sample, ok := request.GetArguments()["payload"]; ok {
+			name := schemaName
+			if name == "" && len(eventTypes) > 0 {
+				name = eventTypes[0]
+			}
+			if name == "" {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "schema or type is required to validate a payload", map[string]any{"schemas": set.names()}), nil
+			}
+			cs := set.find(name)
+			if cs == nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "unknown schema", map[string]any{"schema": name, "schemas": set.names()}), nil
+			}
+			results = append(results, validateOne(set, cs, name, sample, red))
+		} else {
+			if or == nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "payload is required when no outbox reader is configured", nil), nil
+			}
+			limit := int(mcp.ParseFloat64(request, "limit", defaultValidateLimit))
+			if limit <= 0 {
+				limit = defaultValidateLimit
+			}
+			limit = min(limit, maxLimit)
+			events, err := recentEvents(ctx, or, eventTypes, request.GetString("event_id", ""), limit)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to read outbox", map[string]any{"error": err.Error()}), nil
+			}
+			for _, e := range events {
+				name := schemaName
+				if name == "" {
+					name = e.Type
+				}
+				var r map[string]any
+				if cs := set.find(name); cs != nil {
+					r = validateOne(set, cs, e.Type, e.Payload, red)
+				} else {
+					r = map[string]any{"type": e.Type, "valid": false, "error": fmt.Sprintf("no schema for %q", name)}
+				}
+				if e.ID != "" {
+					r["event_id"] = e.ID
+				}
+				results = append(results, r)
+			}
+		}
+
+		invalid := 0
+		for _, r := range results {
+			if r["valid"] != true {
+				invalid++
+			}
+		}
+		if results == nil {
+			results = []map[string]any{}
+		}
+		out := map[string]any{
+			"valid":   invalid == 0,
+			"checked": len(results),
+			"invalid": invalid,
+			"results": results,
+		}
+		if len(set.skipped) > 0 {
+			out["skipped_schemas"] = set.skipped
+		}
+		return internal_mcp.NewToolResultJSON(out)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register events.validate: %w", err)
+	}
+	return nil
+}
+
+// recentEvents reads events through types.OutboxQuerier when available and
+// falls back to Peek maps with "id", "type" and "payload" keys.
+func recentEvents(ctx context.Context, or types.OutboxReader, eventTypes []string, id string, limit int) ([]types.OutboxEvent, error) {
+	// Neither interface filters by ID, so look through the last maxLimit
+	// events when one is requested.
+	n := limit
+	if id != "" {
+		n = maxLimit
+	}
+	var events []types.OutboxEvent
+	if q, ok := or.(types.OutboxQuerier); ok {
+		page, err := q.QueryOutbox(ctx, types.OutboxQuery{Types: eventTypes, Limit: n})
+		if err != nil {
+			return nil, err
+		}
+		if page != nil {
+			events = page.Events
+		}
+	} else {
+		raw, err := or.Peek(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range raw {
+			e := types.OutboxEvent{Payload: m["payload"]}
+			e.ID, _ = m["id"].(string)
+			e.Type, _ = m["type"].(string)
+			events = append(events, e)
+		}
+	}
+
+	query := types.OutboxQuery{Types: eventTypes}
+	out := events[:0]
+	for _, e := range events {
+		if (id == "" || e.ID == id) && query.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func validateOne(set *schemaSet, cs *contractSchema, eventType string, payload any, red *logs.Redactor) map[string]any {
+	r := map[string]any{"type": eventType, "schema": cs.name}
+	if len(cs.warnings) > 0 {
+		r["warnings"] = cs.warnings
+	}
+	doc, err := normalizePayload(payload)
+	if err != nil {
+		r["valid"] = false
+		r["error"] = err.Error()
+		return r
+	}
+	violations := set.validate(cs, doc)
+	for i := range violations {
+		violations[i].Message = red.Message(violations[i].Message)
+	}
+	if violations == nil {
+		violations = []Violation{}
+	}
+	r["valid"] = len(violations) == 0
+	r["violations"] = violations
+	return r
+}
+
+// normalizePayload converts a payload to generic JSON values with exact
+// numbers. Raw JSON as []byte, json.RawMessage or string is decoded.
+func normalizePayload(payload any) (any, error) {
+	var b []byte
+	switch p := payload.(type) {
+	case []byte:
+		b = p
+	case json.RawMessage:
+		b = p
+	case string:
+		if !json.Valid([]byte(p)) {
+			b, _ = json.Marshal(p)
+		} else {
+			b = []byte(p)
+		}
+	default:
+		var err error
+		if b, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("payload is not JSON: %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("payload is not JSON: %w", err)
+	}
+	return doc, nil
+}
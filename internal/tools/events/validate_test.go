@@ -0,0 +1,180 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+const orderPlacedSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://contracts.example.com/order-placed.json",
+  "title": "OrderPlaced",
+  "type": "object",
+  "required": ["order_id", "total", "items"],
+  "additionalProperties": false,
+  "properties": {
+    "order_id": {"type": "string", "format": "uuid"},
+    "total": {"type": "number", "minimum": 0},
+    "currency": {"enum": ["EUR", "USD"]},
+    "coupon": {"type": ["string", "null"]},
+    "customer": {"$ref": "common/customer.json"},
+    "items": {
+      "type": "array",
+      "minItems": 1,
+      "items": {"$ref": "#/definitions/item"}
+    }
+  },
+  "definitions": {
+    "item": {
+      "type": "object",
+      "required": ["sku", "qty"],
+      "properties": {
+        "sku": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]+$"},
+        "qty": {"type": "integer", "minimum": 1}
+      }
+    }
+  }
+}`
+
+const customerSchema = `{
+  "type": "object",
+  "required": ["email"],
+  "properties": {"email": {"type": "string", "format": "email"}}
+}`
+
+func writeSchemas(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"order_placed.schema.json": orderPlacedSchema,
+		"common/customer.json":     customerSchema,
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func violationsOf(t *testing.T, r map[string]any) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	for _, v := range r["violations"].([]Violation) {
+		out[v.Path] = v.Keyword
+	}
+	return out
+}
+
+func TestValidate_Sample(t *testing.T) {
+	dir := writeSchemas(t)
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"type": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "audited.json"), []byte(`{"type": "object", "unevaluatedProperties": false}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := &mockToolAdder{}
+	if err := RegisterValidate(s, nil, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string]any{
+		"order_id": "0b6f2a9e-5a3c-4f2e-9a57-3d1c2b8e4f10",
+		"total":    12.5,
+		"coupon":   nil,
+		"customer": map[string]any{"email": "a@example.com"},
+		"items":    []any{map[string]any{"sku": "ABC-1", "qty": 2}},
+	}
+	out := s.call(t, "events.validate", map[string]any{"schema": "OrderPlaced", "payload": valid}).StructuredContent.(map[string]any)
+	if out["valid"] != true {
+		t.Fatalf("valid payload rejected: %v", out["results"])
+	}
+	if skipped, _ := out["skipped_schemas"].([]skippedSchema); len(skipped) != 1 || skipped[0].Name != "broken.json" {
+		t.Errorf("skipped_schemas = %v, want broken.json", out["skipped_schemas"])
+	}
+
+	bad := map[string]any{
+		"order_id": "not-a-uuid",
+		"total":    -1,
+		"currency": "GBP",
+		"coupon":   5,
+		"customer": map[string]any{},
+		"items":    []any{map[string]any{"sku": "abc", "qty": 1.5}},
+		"extra":    true,
+	}
+	out = s.call(t, "events.validate", map[string]any{"schema": "order_placed", "payload": bad}).StructuredContent.(map[string]any)
+	if out["valid"] != false || out["invalid"] != 1 {
+		t.Fatalf("out = %v", out)
+	}
+	got := violationsOf(t, out["results"].([]map[string]any)[0])
+	want := map[string]string{
+		"$.order_id":       "format",
+		"$.total":          "minimum",
+		"$.currency":       "enum",
+		"$.coupon":         "anyOf",
+		"$.customer.email": "required",
+		"$.items[0].sku":   "pattern",
+		"$.items[0].qty":   "type",
+		"$.extra":          "additionalProperties",
+	}
+	for path, kw := range want {
+		if got[path] != kw {
+			t.Errorf("%s: keyword = %q, want %q (all: %v)", path, got[path], kw, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("violations = %v, want %d", got, len(want))
+	}
+
+	out = s.call(t, "events.validate", map[string]any{"schema": "audited", "payload": map[string]any{"extra": 1}}).StructuredContent.(map[string]any)
+	if w, _ := out["results"].([]map[string]any)[0]["warnings"].([]string); len(w) != 1 {
+		t.Errorf("warnings = %v, want the unsupported unevaluatedProperties", out["results"])
+	}
+
+	if res := s.call(t, "events.validate", map[string]any{"schema": "nope", "payload": valid}); !res.IsError {
+		t.Error("unknown schema accepted")
+	}
+	if res := s.call(t, "events.validate", nil); !res.IsError {
+		t.Error("outbox validation accepted without an outbox reader")
+	}
+}
+
+func TestValidate_Outbox(t *testing.T) {
+	s := &mockToolAdder{}
+	ob := &outbox{events: []types.OutboxEvent{
+		{ID: "3", Type: "OrderShipped", Payload: map[string]any{}},
+		{ID: "2", Type: "OrderPlaced", Payload: []byte(`{"order_id":"x","total":1,"items":[]}`)},
+		{ID: "1", Type: "OrderPlaced", Payload: `{"order_id":"0b6f2a9e-5a3c-4f2e-9a57-3d1c2b8e4f10","total":1,"items":[{"sku":"ABC-1","qty":1}]}`},
+	}}
+	if err := RegisterValidate(s, ob, writeSchemas(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.call(t, "events.validate", map[string]any{"type": "OrderPlaced"}).StructuredContent.(map[string]any)
+	results := out["results"].([]map[string]any)
+	if out["checked"] != 2 || out["invalid"] != 1 || results[0]["event_id"] != "2" || results[1]["valid"] != true {
+		t.Fatalf("out = %v", out)
+	}
+	if got := violationsOf(t, results[0]); got["$.items"] != "minItems" || got["$.order_id"] != "format" {
+		t.Errorf("violations = %v", got)
+	}
+
+	out = s.call(t, "events.validate", map[string]any{"event_id": "3"}).StructuredContent.(map[string]any)
+	results = out["results"].([]map[string]any)
+	if len(results) != 1 || results[0]["valid"] != false || results[0]["error"] == nil {
+		t.Errorf("event without a schema: %v", results)
+	}
+
+	none := &mockToolAdder{}
+	if err := RegisterValidate(none, ob, ""); err != nil || none.handlers != nil {
+		t.Errorf("no schema dir: err = %v, registered = %v", err, none.handlers != nil)
+	}
+}
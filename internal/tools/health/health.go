@@ -2,40 +2,164 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
 	"github.com/next-trace/scg-boost/types"
 )
 
-// Register registers the health.status tool.
-func Register(s internal_mcp.ToolAdder, hp types.HealthProbe) error {
-	if hp == nil {
+// Report is the combined result of a HealthProbe and a HealthChecker.
+type Report struct {
+	// Status is the overall types.HealthStatus* value: down when liveness
+	// fails or a component is down, degraded when readiness fails, a
+	// component is degraded or the checker errors.
+	Status string
+	// Liveness and Readiness are "ok" or "fail"; empty without a probe.
+	Liveness       string
+	Readiness      string
+	LivenessError  string
+	ReadinessError string
+	Checks         []types.HealthCheck
+	CheckError     string
+}
+
+// Collect runs the probe and the checker; either may be nil.
+func Collect(ctx context.Context, hp types.HealthProbe, hc types.HealthChecker) Report {
+	r := Report{Status: types.HealthStatusUp}
+	degrade := func(status string) {
+		if status == types.HealthStatusDown || r.Status == types.HealthStatusUp {
+			r.Status = status
+		}
+	}
+	if hp != nil {
+		r.Liveness, r.Readiness = "ok", "ok"
+		if err := hp.Liveness(ctx); err != nil {
+			r.Liveness, r.LivenessError = "fail", err.Error()
+			degrade(types.HealthStatusDown)
+		}
+		if err := hp.Readiness(ctx); err != nil {
+			r.Readiness, r.ReadinessError = "fail", err.Error()
+			degrade(types.HealthStatusDegraded)
+		}
+	}
+	if hc != nil {
+		checks, err := hc.Checks(ctx)
+		if err != nil {
+			r.CheckError = err.Error()
+			degrade(types.HealthStatusDegraded)
+		}
+		sort.SliceStable(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+		for _, c := range checks {
+			if c.Status != types.HealthStatusUp {
+				degrade(c.Status)
+			}
+		}
+		r.Checks = checks
+	}
+	return r
+}
+
+// JSON returns the tool representation of r. The liveness and readiness
+// keys keep the original health.status shape.
+func (r Report) JSON() map[string]any {
+	out := map[string]any{"status": r.Status}
+	if r.Liveness != "" {
+		out["liveness"] = r.Liveness
+		out["readiness"] = r.Readiness
+	}
+	if r.LivenessError != "" {
+		out["liveness_error"] = r.LivenessError
+	}
+	if r.ReadinessError != "" {
+		out["readiness_error"] = r.ReadinessError
+	}
+	if r.CheckError != "" {
+		out["checks_error"] = r.CheckError
+	}
+	if r.Checks != nil {
+		checks := make([]map[string]any, len(r.Checks))
+		for i, c := range r.Checks {
+			m := map[string]any{
+				"name":       c.Name,
+				"status":     c.Status,
+				"latency_ms": float64(c.Latency.Microseconds()) / 1000,
+			}
+			if c.Message != "" {
+				m["message"] = c.Message
+			}
+			if !c.LastChange.IsZero() {
+				m["last_change"] = c.LastChange.Format(time.RFC3339)
+			}
+			if len(c.Details) > 0 {
+				m["details"] = c.Details
+			}
+			checks[i] = m
+		}
+		out["checks"] = checks
+	}
+	return out
+}
+
+// changeTracker fills in LastChange for checkers that do not track it,
+// using the first time this process observed the current status.
+type changeTracker struct {
+	mu   sync.Mutex
+	seen map[string]observed
+}
+
+type observed struct {
+	status string
+	since  time.Time
+}
+
+func (t *changeTracker) apply(checks []types.HealthCheck, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen == nil {
+		t.seen = make(map[string]observed)
+	}
+	for i := range checks {
+		c := &checks[i]
+		prev, ok := t.seen[c.Name]
+		if !ok || prev.status != c.Status {
+			prev = observed{status: c.Status, since: now}
+			t.seen[c.Name] = prev
+		}
+		if c.LastChange.IsZero() {
+			c.LastChange = prev.since
+		}
+	}
+}
+
+// Register registers the health.status tool. It reports the probe's
+// liveness and readiness (with error messages) and, when hc is set, named
+// component checks. Either hp or hc may be nil.
+func Register(s internal_mcp.ToolAdder, hp types.HealthProbe, hc types.HealthChecker) error {
+	if hp == nil && hc == nil {
 		return nil // Tool not registered if no health probe
 	}
+	if hc == nil {
+		hc, _ = hp.(types.HealthChecker)
+	}
+	tracker := &changeTracker{}
 
 	tool := mcp.NewTool(
 		"health.status",
-		mcp.WithDescription("Get the health status of the service, including liveness and readiness."),
+		mcp.WithDescription("Get the health status of the service, including liveness, readiness and per-component checks with status, latency, message and last change."),
 	)
 
 	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		livenessErr := hp.Liveness(ctx)
-		readinessErr := hp.Readiness(ctx)
-
-		status := map[string]any{
-			"liveness":  "ok",
-			"readiness": "ok",
-		}
-		if livenessErr != nil {
-			status["liveness"] = "fail"
-		}
-		if readinessErr != nil {
-			status["readiness"] = "fail"
-		}
-
-		return internal_mcp.NewToolResultJSON(status)
+		r := Collect(ctx, hp, hc)
+		tracker.apply(r.Checks, time.Now())
+		return internal_mcp.NewToolResultJSON(r.JSON())
 	}
 
-	return s.AddTool(tool, handler)
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register health.status: %w", err)
+	}
+	return nil
 }
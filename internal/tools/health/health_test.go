@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+type probe struct{ live, ready error }
+
+func (p probe) Liveness(context.Context) error  { return p.live }
+func (p probe) Readiness(context.Context) error { return p.ready }
+
+type checker struct{ checks []types.HealthCheck }
+
+func (c *checker) Checks(context.Context) ([]types.HealthCheck, error) {
+	return append([]types.HealthCheck(nil), c.checks...), nil
+}
+
+// probeChecker is a HealthProbe that also reports component checks.
+type probeChecker struct {
+	probe
+	checker
+}
+
+func call(t *testing.T, s *mockToolAdder) map[string]any {
+	t.Helper()
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "health.status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StructuredContent.(map[string]any)
+}
+
+func TestHealthStatus_ProbeOnly(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, probe{ready: errors.New("db pool exhausted")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := call(t, s)
+	if out["liveness"] != "ok" || out["readiness"] != "fail" {
+		t.Errorf("liveness/readiness = %v/%v, want ok/fail", out["liveness"], out["readiness"])
+	}
+	if out["readiness_error"] != "db pool exhausted" {
+		t.Errorf("readiness_error = %v", out["readiness_error"])
+	}
+	if out["status"] != types.HealthStatusDegraded {
+		t.Errorf("status = %v, want degraded", out["status"])
+	}
+	if _, ok := out["checks"]; ok {
+		t.Error("checks reported without a checker")
+	}
+}
+
+func TestHealthStatus_Checks(t *testing.T) {
+	changed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	hc := &checker{checks: []types.HealthCheck{
+		{Name: "redis", Status: types.HealthStatusUp, Latency: 1500 * time.Microsecond},
+		{Name: "db", Status: types.HealthStatusDown, Message: "connection refused", LastChange: changed},
+	}}
+	s := &mockToolAdder{}
+	if err := Register(s, &probeChecker{checker: *hc}, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := call(t, s)
+	if out["status"] != types.HealthStatusDown || out["liveness"] != "ok" {
+		t.Errorf("status = %v, liveness = %v", out["status"], out["liveness"])
+	}
+	checks := out["checks"].([]map[string]any)
+	if len(checks) != 2 || checks[0]["name"] != "db" || checks[1]["name"] != "redis" {
+		t.Fatalf("checks = %v, want db then redis", checks)
+	}
+	if checks[0]["message"] != "connection refused" || checks[0]["last_change"] != changed.Format(time.RFC3339) {
+		t.Errorf("db check = %v", checks[0])
+	}
+	if checks[1]["latency_ms"] != 1.5 {
+		t.Errorf("redis latency_ms = %v, want 1.5", checks[1]["latency_ms"])
+	}
+	if _, ok := checks[1]["last_change"]; !ok {
+		t.Error("redis last_change not tracked")
+	}
+}
+
+func TestHealthStatus_TracksLastChange(t *testing.T) {
+	hc := &checker{checks: []types.HealthCheck{{Name: "broker", Status: types.HealthStatusUp}}}
+	tr := &changeTracker{}
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	step := func(status string, now time.Time) time.Time {
+		hc.checks[0].Status = status
+		checks, _ := hc.Checks(context.Background())
+		tr.apply(checks, now)
+		return checks[0].LastChange
+	}
+	if got := step(types.HealthStatusUp, t0); !got.Equal(t0) {
+		t.Errorf("first observation = %v, want %v", got, t0)
+	}
+	if got := step(types.HealthStatusUp, t0.Add(time.Minute)); !got.Equal(t0) {
+		t.Errorf("unchanged status moved last change to %v", got)
+	}
+	if got := step(types.HealthStatusDegraded, t0.Add(2*time.Minute)); !got.Equal(t0.Add(2 * time.Minute)) {
+		t.Errorf("status change last change = %v", got)
+	}
+}
+
+func TestRegister_Nil(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, nil, nil); err != nil || s.handler != nil {
+		t.Errorf("err = %v, registered = %v", err, s.handler != nil)
+	}
+	if err := Register(s, nil, &checker{}); err != nil || s.handler == nil {
+		t.Errorf("checker only: err = %v, registered = %v", err, s.handler != nil)
+	}
+}
@@ -0,0 +1,290 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultHistorySize is the number of samples kept when none is configured.
+const DefaultHistorySize = 720
+
+// maxTransitions caps the transitions returned by health.history.
+const maxTransitions = 200
+
+// Sample is the status of every component at one point in time. The probe
+// contributes "liveness" and "readiness" components.
+type Sample struct {
+	At         time.Time
+	Components map[string]string
+}
+
+// History samples the health probe and checker into a bounded ring buffer.
+type History struct {
+	hp       types.HealthProbe
+	hc       types.HealthChecker
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []Sample
+	next    int
+	full    bool
+}
+
+// NewHistory returns a History keeping the last size samples taken every
+// interval. hc may be nil; when hp implements types.HealthChecker its checks
+// are sampled too.
+func NewHistory(hp types.HealthProbe, hc types.HealthChecker, interval time.Duration, size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	if hc == nil {
+		hc, _ = hp.(types.HealthChecker)
+	}
+	return &History{hp: hp, hc: hc, interval: interval, samples: make([]Sample, size)}
+}
+
+// Run samples immediately and then every interval until ctx is canceled.
+func (h *History) Run(ctx context.Context) {
+	h.Sample(ctx, time.Now())
+	t := time.NewTicker(h.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			h.Sample(ctx, now)
+		}
+	}
+}
+
+// Sample records the current health at now.
+func (h *History) Sample(ctx context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout())
+	defer cancel()
+	r := Collect(ctx, h.hp, h.hc)
+	components := make(map[string]string, len(r.Checks)+2)
+	if r.Liveness != "" {
+		components["liveness"] = probeStatus(r.Liveness)
+		components["readiness"] = probeStatus(r.Readiness)
+	}
+	for _, c := range r.Checks {
+		components[c.Name] = c.Status
+	}
+	h.add(Sample{At: now, Components: components})
+}
+
+// timeout bounds one sample so a hanging dependency cannot stall polling.
+func (h *History) timeout() time.Duration {
+	if h.interval > 0 && h.interval < 10*time.Second {
+		return h.interval
+	}
+	return 10 * time.Second
+}
+
+func probeStatus(s string) string {
+	if s == "ok" {
+		return types.HealthStatusUp
+	}
+	return types.HealthStatusDown
+}
+
+func (h *History) add(s Sample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples[h.next] = s
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Samples returns the buffered samples, oldest first.
+func (h *History) Samples() []Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]Sample(nil), h.samples[:h.next]...)
+	}
+	out := make([]Sample, 0, len(h.samples))
+	out = append(out, h.samples[h.next:]...)
+	return append(out, h.samples[:h.next]...)
+}
+
+// Transition is a component status change between two samples.
+type Transition struct {
+	At        time.Time
+	Component string
+	From, To  string
+}
+
+// ComponentHistory summarizes one component over the buffered samples.
+type ComponentHistory struct {
+	Name    string
+	Status  string
+	Samples int
+	// UptimePct is the share of samples in which the component was up.
+	UptimePct   float64
+	Transitions int
+	// StreakSince is the first sample of the current status run.
+	StreakSince   time.Time
+	StreakSamples int
+}
+
+// summarize computes per-component statistics and transitions, oldest first.
+func summarize(samples []Sample) ([]ComponentHistory, []Transition) {
+	byName := map[string]*ComponentHistory{}
+	var transitions []Transition
+	for _, s := range samples {
+		for name, status := range s.Components {
+			c, ok := byName[name]
+			if !ok {
+				c = &ComponentHistory{Name: name, Status: status, StreakSince: s.At}
+				byName[name] = c
+			}
+			if status != c.Status {
+				transitions = append(transitions, Transition{At: s.At, Component: name, From: c.Status, To: status})
+				c.Transitions++
+				c.Status, c.StreakSince, c.StreakSamples = status, s.At, 0
+			}
+			c.Samples++
+			c.StreakSamples++
+			if status == types.HealthStatusUp {
+				c.UptimePct++
+			}
+		}
+	}
+	out := make([]ComponentHistory, 0, len(byName))
+	for _, c := range byName {
+		c.UptimePct = c.UptimePct * 100 / float64(c.Samples)
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if transitions[i].At.Equal(transitions[j].At) {
+			return transitions[i].Component < transitions[j].Component
+		}
+		return transitions[i].At.Before(transitions[j].At)
+	})
+	return out, transitions
+}
+
+// RegisterHistory registers the health.history tool backed by h.
+func RegisterHistory(s internal_mcp.ToolAdder, h *History) error {
+	if h == nil {
+		return nil
+	}
+
+	tool := mcp.NewTool(
+		"health.history",
+		mcp.WithDescription("Get the sampled health timeline: status transitions, uptime percentage per component and current streaks. Use it to spot flapping readiness or dependencies."),
+		mcp.WithString("component", mcp.Description("Only report this component (e.g. readiness, db)")),
+		mcp.WithString("since", mcp.Description("Only use samples after this time: RFC 3339 or a duration ago such as 15m")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		samples := h.Samples()
+		if raw := request.GetString("since", ""); raw != "" {
+			since, err := argparse.Time(raw, time.Now())
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid since", map[string]any{"error": err.Error()}), nil
+			}
+			i := sort.Search(len(samples), func(i int) bool { return !samples[i].At.Before(since) })
+			samples = samples[i:]
+		}
+		components, transitions := summarize(samples)
+		if name := request.GetString("component", ""); name != "" {
+			components = filterComponents(components, name)
+			transitions = filterTransitions(transitions, name)
+		}
+
+		out := map[string]any{
+			"interval":    h.interval.String(),
+			"samples":     len(samples),
+			"components":  componentsJSON(components, samples),
+			"transitions": transitionsJSON(transitions),
+		}
+		if len(samples) > 0 {
+			out["from"] = samples[0].At.Format(time.RFC3339)
+			out["to"] = samples[len(samples)-1].At.Format(time.RFC3339)
+		}
+		if len(transitions) > maxTransitions {
+			out["truncated"] = true
+		}
+		return internal_mcp.NewToolResultJSON(out)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register health.history: %w", err)
+	}
+	return nil
+}
+
+func filterComponents(cs []ComponentHistory, name string) []ComponentHistory {
+	out := cs[:0]
+	for _, c := range cs {
+		if c.Name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func filterTransitions(ts []Transition, name string) []Transition {
+	out := ts[:0]
+	for _, t := range ts {
+		if t.Component == name {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func componentsJSON(cs []ComponentHistory, samples []Sample) []map[string]any {
+	var last time.Time
+	if len(samples) > 0 {
+		last = samples[len(samples)-1].At
+	}
+	out := make([]map[string]any, len(cs))
+	for i, c := range cs {
+		out[i] = map[string]any{
+			"name":        c.Name,
+			"status":      c.Status,
+			"samples":     c.Samples,
+			"uptime_pct":  float64(int(c.UptimePct*100+0.5)) / 100,
+			"transitions": c.Transitions,
+			"streak": map[string]any{
+				"status":      c.Status,
+				"since":       c.StreakSince.Format(time.RFC3339),
+				"samples":     c.StreakSamples,
+				"duration_ms": last.Sub(c.StreakSince).Milliseconds(),
+			},
+		}
+	}
+	return out
+}
+
+// transitionsJSON returns the most recent transitions, newest first.
+func transitionsJSON(ts []Transition) []map[string]any {
+	if len(ts) > maxTransitions {
+		ts = ts[len(ts)-maxTransitions:]
+	}
+	out := make([]map[string]any, len(ts))
+	for i, t := range ts {
+		out[len(ts)-1-i] = map[string]any{
+			"ts":        t.At.Format(time.RFC3339),
+			"component": t.Component,
+			"from":      t.From,
+			"to":        t.To,
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// flappingProbe fails readiness when ready is false.
+type flappingProbe struct{ ready bool }
+
+func (p *flappingProbe) Liveness(context.Context) error { return nil }
+func (p *flappingProbe) Readiness(context.Context) error {
+	if p.ready {
+		return nil
+	}
+	return errors.New("not ready")
+}
+
+func TestHistory_RingBuffer(t *testing.T) {
+	h := NewHistory(&flappingProbe{ready: true}, nil, time.Second, 3)
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		h.Sample(context.Background(), t0.Add(time.Duration(i)*time.Second))
+	}
+	samples := h.Samples()
+	if len(samples) != 3 || !samples[0].At.Equal(t0.Add(2*time.Second)) || !samples[2].At.Equal(t0.Add(4*time.Second)) {
+		t.Fatalf("samples = %v, want the last three oldest first", samples)
+	}
+}
+
+func TestHealthHistory(t *testing.T) {
+	p := &flappingProbe{}
+	hc := &checker{}
+	h := NewHistory(p, hc, time.Minute, 0)
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// readiness: fail, ok, ok, fail; db: up throughout, degraded at the end.
+	for i, ready := range []bool{false, true, true, false} {
+		p.ready = ready
+		status := types.HealthStatusUp
+		if i == 3 {
+			status = types.HealthStatusDegraded
+		}
+		hc.checks = []types.HealthCheck{{Name: "db", Status: status}}
+		h.Sample(context.Background(), t0.Add(time.Duration(i)*time.Minute))
+	}
+
+	s := &mockToolAdder{}
+	if err := RegisterHistory(s, h); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "health.history"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := res.StructuredContent.(map[string]any)
+	if out["samples"] != 4 || out["interval"] != "1m0s" {
+		t.Errorf("samples = %v, interval = %v", out["samples"], out["interval"])
+	}
+
+	byName := map[string]map[string]any{}
+	for _, c := range out["components"].([]map[string]any) {
+		byName[c["name"].(string)] = c
+	}
+	ready := byName["readiness"]
+	if ready["uptime_pct"] != 50.0 || ready["transitions"] != 2 || ready["status"] != types.HealthStatusDown {
+		t.Errorf("readiness = %v", ready)
+	}
+	if streak := ready["streak"].(map[string]any); streak["samples"] != 1 || streak["duration_ms"] != int64(0) {
+		t.Errorf("readiness streak = %v", streak)
+	}
+	if live := byName["liveness"]; live["uptime_pct"] != 100.0 || live["streak"].(map[string]any)["duration_ms"] != int64(3*time.Minute/time.Millisecond) {
+		t.Errorf("liveness = %v", live)
+	}
+	if db := byName["db"]; db["uptime_pct"] != 75.0 || db["status"] != types.HealthStatusDegraded {
+		t.Errorf("db = %v", db)
+	}
+
+	transitions := out["transitions"].([]map[string]any)
+	if len(transitions) != 3 {
+		t.Fatalf("transitions = %v, want 3", transitions)
+	}
+	if oldest := transitions[2]; oldest["component"] != "readiness" || oldest["to"] != types.HealthStatusUp {
+		t.Errorf("oldest transition = %v, want readiness recovering", oldest)
+	}
+}
+
+func TestHealthHistory_Filters(t *testing.T) {
+	h := NewHistory(&flappingProbe{}, nil, time.Minute, 10)
+	now := time.Now()
+	h.Sample(context.Background(), now.Add(-time.Hour))
+	h.Sample(context.Background(), now)
+
+	s := &mockToolAdder{}
+	if err := RegisterHistory(s, h); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "health.history",
+		Arguments: map[string]any{"since": "10m", "component": "readiness"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := res.StructuredContent.(map[string]any)
+	if out["samples"] != 1 {
+		t.Errorf("samples = %v, want 1 within 10m", out["samples"])
+	}
+	if cs := out["components"].([]map[string]any); len(cs) != 1 || cs[0]["name"] != "readiness" {
+		t.Errorf("components = %v", cs)
+	}
+
+	res, _ = s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "health.history",
+		Arguments: map[string]any{"since": "yesterday"},
+	}})
+	if !res.IsError {
+		t.Error("invalid since accepted")
+	}
+}
+
+func TestHistory_Run(t *testing.T) {
+	h := NewHistory(&flappingProbe{ready: true}, nil, 5*time.Millisecond, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	<-done
+	if n := len(h.Samples()); n < 2 {
+		t.Errorf("samples = %d, want periodic sampling", n)
+	}
+}
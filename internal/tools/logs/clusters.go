@@ -0,0 +1,187 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const (
+	defaultClusterWindow = time.Hour
+	defaultClusters      = 20
+	defaultScanEntries   = 2000
+	maxScanEntries       = 20000
+)
+
+// defaultClusterLevels are clustered when no level is given.
+var defaultClusterLevels = []string{"error", "critical", "fatal", "panic"}
+
+var (
+	quotedRe = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|\B'(?:[^'\\]|\\.)*'\B|` + "`[^`]*`")
+	uuidRe   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexRe    = regexp.MustCompile(`(?i)\b(?:0x[0-9a-f]+|[0-9a-f]{6,})\b`)
+	numberRe = regexp.MustCompile(`[-+]?\b\d+(?:\.\d+)?`)
+)
+
+// Template masks quoted values, UUIDs, hex strings and numbers in msg (in
+// that order, so a UUID is not split into hex and numbers), so messages that
+// differ only by IDs share a template.
+func Template(msg string) string {
+	msg = quotedRe.ReplaceAllString(msg, "<str>")
+	msg = uuidRe.ReplaceAllString(msg, "<uuid>")
+	msg = hexRe.ReplaceAllStringFunc(msg, func(tok string) string {
+		// Words such as "facade" and plain numbers are not hex IDs; numbers
+		// are masked next.
+		if strings.HasPrefix(strings.ToLower(tok), "0x") || (strings.ContainsAny(tok, "0123456789") && strings.ContainsAny(strings.ToLower(tok), "abcdef")) {
+			return "<hex>"
+		}
+		return tok
+	})
+	msg = numberRe.ReplaceAllString(msg, "<num>")
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+type cluster struct {
+	template  string
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+	levels    map[string]int
+	example   types.LogEntry
+}
+
+// RegisterClusters registers the logs.clusters tool, which groups entries
+// by message template. Example fields are redacted like logs.search.
+func RegisterClusters(s internal_mcp.ToolAdder, q types.LogQuerier, redactFields []string) error {
+	if q == nil {
+		return nil // Tool not registered if no log querier
+	}
+	red := NewRedactor(redactFields)
+
+	tool := mcp.NewTool(
+		"logs.clusters",
+		mcp.WithDescription("Group error log entries into message templates (numbers, UUIDs, hex and quoted values masked), with counts, first/last seen and one example per template."),
+		mcp.WithString("since", mcp.Description("Window start: RFC 3339 or a duration ago (default \"1h\")")),
+		mcp.WithString("until", mcp.Description("Window end: RFC 3339 or a duration ago (default now)")),
+		mcp.WithString("level", mcp.Description("Comma-separated levels to cluster (default \"error,critical,fatal,panic\")")),
+		mcp.WithString("contains", mcp.Description("Case-insensitive substring of the message")),
+		mcp.WithObject("fields", mcp.Description("Field equality filters, e.g. {\"service\": \"api\"}")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum clusters to return, largest first (default %d)", defaultClusters))),
+		mcp.WithNumber("max_entries", mcp.Description(fmt.Sprintf("Maximum entries to scan (default %d, max %d)", defaultScanEntries, maxScanEntries))),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		now := time.Now()
+		query, errResult := parseQuery(request, red, now)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if query.Since.IsZero() {
+			query.Since = now.Add(-defaultClusterWindow)
+		}
+		if len(query.Levels) == 0 {
+			query.Levels = defaultClusterLevels
+		}
+		limit := int(mcp.ParseFloat64(request, "limit", defaultClusters))
+		if limit <= 0 {
+			limit = defaultClusters
+		}
+		maxEntries := int(mcp.ParseFloat64(request, "max_entries", defaultScanEntries))
+		if maxEntries <= 0 {
+			maxEntries = defaultScanEntries
+		}
+		maxEntries = min(maxEntries, maxScanEntries)
+
+		clusters := make(map[string]*cluster)
+		scanned, truncated := 0, false
+		for scanned < maxEntries {
+			query.Limit = min(maxLimit, maxEntries-scanned)
+			page, err := q.SearchLogs(ctx, query)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "log search failed", map[string]any{"error": err.Error()}), nil
+			}
+			entries, next := pageEntries(page, query.Limit)
+			for _, e := range entries {
+				addToCluster(clusters, e)
+			}
+			scanned += len(entries)
+			if next == "" || len(entries) == 0 {
+				break
+			}
+			if scanned >= maxEntries {
+				truncated = true
+				break
+			}
+			query.Cursor = next
+		}
+
+		sorted := make([]*cluster, 0, len(clusters))
+		for _, c := range clusters {
+			sorted = append(sorted, c)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].count != sorted[j].count {
+				return sorted[i].count > sorted[j].count
+			}
+			return sorted[i].lastSeen.After(sorted[j].lastSeen)
+		})
+		total := len(sorted)
+		if len(sorted) > limit {
+			sorted = sorted[:limit]
+		}
+
+		out := make([]map[string]any, len(sorted))
+		for i, c := range sorted {
+			out[i] = map[string]any{
+				"template":   red.Message(c.template),
+				"count":      c.count,
+				"first_seen": c.firstSeen.Format(timeFormat),
+				"last_seen":  c.lastSeen.Format(timeFormat),
+				"levels":     c.levels,
+				"example":    red.Entry(c.example),
+			}
+		}
+		result := map[string]any{
+			"since":         query.Since.Format(timeFormat),
+			"clusters":      out,
+			"cluster_count": total,
+			"entries":       scanned,
+			"truncated":     truncated,
+		}
+		if !query.Until.IsZero() {
+			result["until"] = query.Until.Format(timeFormat)
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register logs.clusters: %w", err)
+	}
+	return nil
+}
+
+// addToCluster counts e under its template. Entries arrive newest first, so
+// the first one seen becomes the example.
+func addToCluster(clusters map[string]*cluster, e types.LogEntry) {
+	t := Template(e.Message)
+	c, ok := clusters[t]
+	if !ok {
+		c = &cluster{template: t, firstSeen: e.Timestamp, lastSeen: e.Timestamp, levels: make(map[string]int), example: e}
+		clusters[t] = c
+	}
+	c.count++
+	c.levels[strings.ToLower(e.Level)]++
+	if e.Timestamp.Before(c.firstSeen) {
+		c.firstSeen = e.Timestamp
+	}
+	if e.Timestamp.After(c.lastSeen) {
+		c.lastSeen = e.Timestamp
+	}
+}
@@ -9,11 +9,15 @@ import (
 	"github.com/next-trace/scg-boost/types"
 )
 
-// Register registers the logs.lastError tool.
-func Register(s internal_mcp.ToolAdder, lr types.LogReader) error {
+const timeFormat = time.RFC3339Nano
+
+// Register registers the logs.lastError tool. Field values whose names match
+// DefaultRedactFields or redactFields are hidden.
+func Register(s internal_mcp.ToolAdder, lr types.LogReader, redactFields []string) error {
 	if lr == nil {
 		return nil // Tool not registered if no log reader
 	}
+	red := NewRedactor(redactFields)
 
 	tool := mcp.NewTool(
 		"logs.lastError",
@@ -28,11 +32,7 @@ func Register(s internal_mcp.ToolAdder, lr types.LogReader) error {
 		if log == nil {
 			return internal_mcp.NewToolResultJSON(map[string]any{"message": "no errors recorded"})
 		}
-		return internal_mcp.NewToolResultJSON(map[string]any{
-			"ts":  log.Timestamp.Format(time.RFC3339),
-			"msg": log.Message,
-			"lvl": log.Level,
-		})
+		return internal_mcp.NewToolResultJSON(red.Entry(*log))
 	}
 
 	return s.AddTool(tool, handler)
@@ -0,0 +1,247 @@
+package logs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handlers map[string]internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	if m.handlers == nil {
+		m.handlers = make(map[string]internal_mcp.ToolHandler)
+	}
+	m.handlers[tool.Name] = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func (m *mockToolAdder) call(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handlers[name](context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatalf("%s: handler error = %v", name, err)
+	}
+	return res
+}
+
+// memQuerier serves entries (oldest first) with integer offset cursors.
+type memQuerier struct {
+	entries []types.LogEntry
+	last    types.LogQuery
+}
+
+func (m *memQuerier) SearchLogs(ctx context.Context, q types.LogQuery) (*types.LogPage, error) {
+	m.last = q
+	skip, _ := strconv.Atoi(q.Cursor)
+	page := &types.LogPage{}
+	matched := 0
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if !q.Match(m.entries[i]) {
+			continue
+		}
+		matched++
+		if matched <= skip {
+			continue
+		}
+		if len(page.Entries) == q.Limit {
+			page.NextCursor = strconv.Itoa(skip + q.Limit)
+			break
+		}
+		page.Entries = append(page.Entries, m.entries[i])
+	}
+	return page, nil
+}
+
+func testEntries() []types.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []types.LogEntry{
+		{Timestamp: base, Level: "info", Message: "started"},
+		{Timestamp: base.Add(time.Minute), Level: "error", Message: "db timeout", Fields: map[string]any{"request_id": "r1", "password": "hunter2"}},
+		{Timestamp: base.Add(2 * time.Minute), Level: "warn", Message: "retrying token=abc123", Fields: map[string]any{"request_id": "r1", "http": map[string]any{"authorization": "Bearer x"}}},
+		{Timestamp: base.Add(3 * time.Minute), Level: "error", Message: "db timeout again", Fields: map[string]any{"request_id": "r2"}},
+	}
+}
+
+func TestSearch_FiltersAndRedacts(t *testing.T) {
+	q := &memQuerier{entries: testEntries()}
+	s := &mockToolAdder{}
+	if err := RegisterSearch(s, q, nil); err != nil {
+		t.Fatalf("RegisterSearch() error = %v", err)
+	}
+
+	res := s.call(t, "logs.search", map[string]any{"fields": map[string]any{"request_id": "r1"}})
+	out := res.StructuredContent.(map[string]any)
+	entries := out["entries"].([]map[string]any)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v, want 2 for request r1", entries)
+	}
+	if entries[0]["msg"] != "retrying token=***" {
+		t.Errorf("msg = %q, want token redacted", entries[0]["msg"])
+	}
+	http := entries[0]["fields"].(map[string]any)["http"].(map[string]any)
+	if http["authorization"] != runtime.MaskedValue {
+		t.Errorf("nested authorization = %v, want masked", http["authorization"])
+	}
+	if f := entries[1]["fields"].(map[string]any); f["password"] != runtime.MaskedValue || f["request_id"] != "r1" {
+		t.Errorf("fields = %v, want password masked and request_id kept", f)
+	}
+
+	res = s.call(t, "logs.search", map[string]any{"level": "error", "pattern": "again$"})
+	if n := res.StructuredContent.(map[string]any)["count"]; n != 1 {
+		t.Errorf("count = %v, want 1", n)
+	}
+
+	res = s.call(t, "logs.search", map[string]any{"pattern": "("})
+	if !res.IsError {
+		t.Error("invalid pattern: want tool error")
+	}
+
+	for _, args := range []map[string]any{{"pattern": "token=a.*"}, {"pattern": "token=abc123"}, {"contains": "abc1"}} {
+		if n := s.call(t, "logs.search", args).StructuredContent.(map[string]any)["count"]; n != 0 {
+			t.Errorf("%v matched %v entries through a masked secret", args, n)
+		}
+	}
+	if n := s.call(t, "logs.search", map[string]any{"pattern": `token=\*\*\*`}).StructuredContent.(map[string]any)["count"]; n != 1 {
+		t.Errorf("pattern on the redacted message matched %v entries, want 1", n)
+	}
+
+	for _, field := range []string{"password", "http.authorization"} {
+		res = s.call(t, "logs.search", map[string]any{"fields": map[string]any{field: "hunter2"}})
+		if !res.IsError {
+			t.Errorf("filter on redacted field %q: want tool error", field)
+		}
+	}
+}
+
+func TestSearch_Paging(t *testing.T) {
+	q := &memQuerier{entries: testEntries()}
+	s := &mockToolAdder{}
+	if err := RegisterSearch(s, q, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, "logs.search", map[string]any{"limit": 3}).StructuredContent.(map[string]any)
+	next, _ := out["next_cursor"].(string)
+	if out["count"] != 3 || next == "" {
+		t.Fatalf("first page = %v, want 3 entries and a cursor", out)
+	}
+	out = s.call(t, "logs.search", map[string]any{"limit": 3, "cursor": next}).StructuredContent.(map[string]any)
+	entries := out["entries"].([]map[string]any)
+	if len(entries) != 1 || entries[0]["msg"] != "started" || out["next_cursor"] != nil {
+		t.Errorf("second page = %v, want only the oldest entry", out)
+	}
+}
+
+func TestTail(t *testing.T) {
+	q := &memQuerier{entries: testEntries()}
+	s := &mockToolAdder{}
+	if err := RegisterSearch(s, q, []string{"request_id"}); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, "logs.tail", map[string]any{"lines": 2}).StructuredContent.(map[string]any)
+	entries := out["entries"].([]map[string]any)
+	if len(entries) != 2 || entries[0]["lvl"] != "warn" || entries[1]["msg"] != "db timeout again" {
+		t.Fatalf("entries = %v, want last two in chronological order", entries)
+	}
+	if entries[1]["fields"].(map[string]any)["request_id"] != runtime.MaskedValue {
+		t.Error("extra redact pattern not applied")
+	}
+
+	since := out["next_since"].(string)
+	out = s.call(t, "logs.tail", map[string]any{"since": since}).StructuredContent.(map[string]any)
+	if out["count"] != 0 {
+		t.Errorf("poll after next_since = %v, want no entries", out)
+	}
+
+	s.call(t, "logs.tail", map[string]any{"since": "90m"})
+	if q.last.Since.IsZero() || time.Since(q.last.Since) < 89*time.Minute {
+		t.Errorf("since = %v, want ~90m ago", q.last.Since)
+	}
+}
+
+type mockLogReader struct{ entry *types.LogEntry }
+
+func (m *mockLogReader) LastError(ctx context.Context) (*types.LogEntry, error) { return m.entry, nil }
+
+func TestLastError_Fields(t *testing.T) {
+	s := &mockToolAdder{}
+	e := testEntries()[1]
+	if err := Register(s, &mockLogReader{entry: &e}, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, "logs.lastError", nil).StructuredContent.(map[string]any)
+	f := out["fields"].(map[string]any)
+	if out["msg"] != "db timeout" || f["request_id"] != "r1" || f["password"] != runtime.MaskedValue {
+		t.Errorf("lastError = %v", out)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tests := map[string]string{
+		`order 12345 failed: user 3f1c2d4e-0000-4000-8000-000000000001 not found`: "order <num> failed: user <uuid> not found",
+		`cache miss for key "user:42" at 0x1f3a`:                                  "cache miss for key <str> at <hex>",
+		`commit a1b2c3d4e5 can't be applied to 'main'`:                            "commit <hex> can't be applied to <str>",
+		`timeout after 1.5s on db2 facade`:                                        "timeout after <num>s on db2 facade",
+	}
+	for in, want := range tests {
+		if got := Template(in); got != want {
+			t.Errorf("Template(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	base := time.Now().Add(-30 * time.Minute).UTC()
+	var entries []types.LogEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, types.LogEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Level:     "error",
+			Message:   "order " + strconv.Itoa(1000+i) + " failed",
+			Fields:    map[string]any{"order_id": 1000 + i, "api_key": "k"},
+		})
+	}
+	entries = append(entries,
+		types.LogEntry{Timestamp: base.Add(10 * time.Minute), Level: "error", Message: "db down"},
+		types.LogEntry{Timestamp: base.Add(11 * time.Minute), Level: "info", Message: "order 1 failed"},
+		types.LogEntry{Timestamp: base.Add(-2 * time.Hour), Level: "error", Message: "order 9 failed"},
+	)
+	s := &mockToolAdder{}
+	if err := RegisterClusters(s, &memQuerier{entries: entries}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.call(t, "logs.clusters", nil).StructuredContent.(map[string]any)
+	clusters := out["clusters"].([]map[string]any)
+	if len(clusters) != 2 || out["entries"] != 6 {
+		t.Fatalf("clusters = %v (entries %v), want 2 clusters from 6 entries in the last hour", clusters, out["entries"])
+	}
+	c := clusters[0]
+	if c["template"] != "order <num> failed" || c["count"] != 5 {
+		t.Errorf("top cluster = %v", c)
+	}
+	if c["first_seen"] != base.Format(timeFormat) || c["last_seen"] != base.Add(4*time.Minute).Format(timeFormat) {
+		t.Errorf("first/last seen = %v / %v", c["first_seen"], c["last_seen"])
+	}
+	ex := c["example"].(map[string]any)
+	if ex["msg"] != "order 1004 failed" || ex["fields"].(map[string]any)["api_key"] != runtime.MaskedValue {
+		t.Errorf("example = %v, want newest entry with redacted fields", ex)
+	}
+
+	out = s.call(t, "logs.clusters", map[string]any{"since": "3h", "max_entries": 3}).StructuredContent.(map[string]any)
+	if out["entries"] != 3 || out["truncated"] != true {
+		t.Errorf("capped scan = %v", out)
+	}
+}
@@ -0,0 +1,91 @@
+package logs
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/next-trace/scg-boost/internal/runtime"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultRedactFields are field name globs whose values log tools always hide.
+var DefaultRedactFields = []string{
+	"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*",
+	"*credential*", "*private_key*", "authorization", "cookie", "set-cookie",
+}
+
+// secretInMessageRe finds key=value or key: value secrets inside messages.
+var secretInMessageRe = regexp.MustCompile(`(?i)\b(password|passwd|secret|token|api[_-]?key|authorization)(\s*[=:]\s*)((?:bearer|basic)\s+)?("[^"]*"|\S+)`)
+
+// Redactor hides sensitive values in log entries returned by tools.
+type Redactor struct {
+	mask *runtime.Masker
+}
+
+// NewRedactor masks fields matching DefaultRedactFields or extra.
+func NewRedactor(extra []string) *Redactor {
+	return &Redactor{mask: runtime.NewMasker(append(append([]string(nil), DefaultRedactFields...), extra...))}
+}
+
+// Entry returns the tool representation of e with secrets redacted.
+func (r *Redactor) Entry(e types.LogEntry) map[string]any {
+	out := map[string]any{
+		"ts":  e.Timestamp.Format(timeFormat),
+		"lvl": e.Level,
+		"msg": r.Message(e.Message),
+	}
+	if len(e.Fields) > 0 {
+		out["fields"] = r.Fields(e.Fields)
+	}
+	return out
+}
+
+// Message masks key=value secrets in msg.
+func (r *Redactor) Message(msg string) string {
+	return secretInMessageRe.ReplaceAllString(msg, "${1}${2}${3}"+runtime.MaskedValue)
+}
+
+// Fields copies fields, masking sensitive keys at any depth.
+func (r *Redactor) Fields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		switch {
+		case v != nil && r.mask.Masked(k):
+			out[k] = runtime.MaskedValue
+		default:
+			out[k] = r.value(v)
+		}
+	}
+	return out
+}
+
+// MaskedField reports whether values of the named field are hidden. Dotted
+// paths such as "auth.token" are hidden when any segment is.
+func (r *Redactor) MaskedField(name string) bool {
+	if r.mask.Masked(name) {
+		return true
+	}
+	for _, seg := range strings.Split(name, ".") {
+		if r.mask.Masked(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) value(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		return r.Fields(x)
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = r.value(e)
+		}
+		return out
+	case string:
+		return r.Message(x)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,159 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// RegisterSearch registers the logs.search and logs.tail tools. Field values
+// whose names match DefaultRedactFields or redactFields are hidden.
+func RegisterSearch(s internal_mcp.ToolAdder, q types.LogQuerier, redactFields []string) error {
+	if q == nil {
+		return nil // Tools not registered if no log querier
+	}
+	red := NewRedactor(redactFields)
+
+	filterOpts := []mcp.ToolOption{
+		mcp.WithString("level", mcp.Description("Comma-separated levels to include, e.g. \"error,warn\"")),
+		mcp.WithString("contains", mcp.Description("Case-insensitive substring of the message")),
+		mcp.WithString("pattern", mcp.Description("Regular expression the message must match")),
+		mcp.WithObject("fields", mcp.Description("Field equality filters, e.g. {\"request_id\": \"abc\"}")),
+		mcp.WithString("since", mcp.Description("Only entries at or after this time: RFC 3339 or a duration ago such as \"15m\"")),
+	}
+
+	searchTool := mcp.NewTool("logs.search", append([]mcp.ToolOption{
+		mcp.WithDescription("Search structured logs, newest first. Use next_cursor to page through older entries."),
+		mcp.WithString("until", mcp.Description("Only entries at or before this time: RFC 3339 or a duration ago")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum entries to return (default %d, max %d)", defaultLimit, maxLimit))),
+		mcp.WithString("cursor", mcp.Description("next_cursor from a previous logs.search call")),
+	}, filterOpts...)...)
+	searchHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, errResult := parseQuery(request, red, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.Limit = clampLimit(int(mcp.ParseFloat64(request, "limit", defaultLimit)))
+		query.Cursor = request.GetString("cursor", "")
+
+		page, err := q.SearchLogs(ctx, query)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "log search failed", map[string]any{"error": err.Error()}), nil
+		}
+		entries, next := pageEntries(page, query.Limit)
+		out := make([]map[string]any, len(entries))
+		for i, e := range entries {
+			out[i] = red.Entry(e)
+		}
+		result := map[string]any{"entries": out, "count": len(out)}
+		if next != "" {
+			result["next_cursor"] = next
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+	if err := s.AddTool(searchTool, searchHandler); err != nil {
+		return fmt.Errorf("register logs.search: %w", err)
+	}
+
+	tailTool := mcp.NewTool("logs.tail", append([]mcp.ToolOption{
+		mcp.WithDescription("Get the most recent log entries in chronological order. Pass next_since as since to poll for newer entries."),
+		mcp.WithNumber("lines", mcp.Description(fmt.Sprintf("Number of entries (default %d, max %d)", defaultLimit, maxLimit))),
+	}, filterOpts...)...)
+	tailHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, errResult := parseQuery(request, red, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.Limit = clampLimit(int(mcp.ParseFloat64(request, "lines", defaultLimit)))
+
+		page, err := q.SearchLogs(ctx, query)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "log tail failed", map[string]any{"error": err.Error()}), nil
+		}
+		entries, _ := pageEntries(page, query.Limit)
+		out := make([]map[string]any, len(entries))
+		var latest time.Time
+		for i, e := range entries {
+			// Entries arrive newest first; tail reads oldest first.
+			out[len(entries)-1-i] = red.Entry(e)
+			if e.Timestamp.After(latest) {
+				latest = e.Timestamp
+			}
+		}
+		result := map[string]any{"entries": out, "count": len(out)}
+		if !latest.IsZero() {
+			result["next_since"] = latest.Add(time.Nanosecond).Format(timeFormat)
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+	if err := s.AddTool(tailTool, tailHandler); err != nil {
+		return fmt.Errorf("register logs.tail: %w", err)
+	}
+	return nil
+}
+
+// parseQuery reads the filter arguments shared by logs.search and logs.tail.
+// Field filters on redacted fields are rejected and message filters match
+// the redacted message: either would otherwise reveal the hidden values.
+func parseQuery(request mcp.CallToolRequest, red *Redactor, now time.Time) (types.LogQuery, *mcp.CallToolResult) {
+	q := types.LogQuery{Levels: argparse.List(request.GetString("level", ""))}
+	q.Contains = request.GetString("contains", "")
+	q.Redact = red.Message
+
+	if p := request.GetString("pattern", ""); p != "" {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid pattern", map[string]any{"error": err.Error()})
+		}
+		q.Pattern = re
+	}
+
+	if raw, ok := request.GetArguments()["fields"].(map[string]any); ok {
+		q.Fields = make(map[string]string, len(raw))
+		for k, v := range raw {
+			if red.MaskedField(k) {
+				return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, fmt.Sprintf("cannot filter on redacted field %q", k), map[string]any{"field": k})
+			}
+			q.Fields[k] = fmt.Sprint(v)
+		}
+	}
+
+	var err error
+	if q.Since, err = argparse.Time(request.GetString("since", ""), now); err != nil {
+		return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid since", map[string]any{"error": err.Error()})
+	}
+	if q.Until, err = argparse.Time(request.GetString("until", ""), now); err != nil {
+		return q, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid until", map[string]any{"error": err.Error()})
+	}
+	return q, nil
+}
+
+func clampLimit(n int) int {
+	if n <= 0 {
+		return defaultLimit
+	}
+	return min(n, maxLimit)
+}
+
+// pageEntries guards against queriers that ignore the limit.
+func pageEntries(page *types.LogPage, limit int) ([]types.LogEntry, string) {
+	if page == nil {
+		return nil, ""
+	}
+	entries := page.Entries
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, page.NextCursor
+}
@@ -0,0 +1,526 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// DefaultBaselineDir is the baseline directory relative to the project root.
+const DefaultBaselineDir = ".scg/metrics"
+
+const (
+	baselineScrapes  = 5
+	defaultTolerance = 0.25
+	// minRate ignores counters too quiet to compare meaningfully.
+	minRate = 0.01
+)
+
+var baselineNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// Baseline is a named snapshot of metric statistics saved as JSON.
+type Baseline struct {
+	Name    string    `json:"name"`
+	SavedAt time.Time `json:"saved_at"`
+	Window  string    `json:"window"`
+	Stats
+}
+
+// Stats are the metric statistics a baseline compares. Readers without
+// types.MetricsScraper only provide Values, flattened from their summary.
+type Stats struct {
+	Counters []CounterStat      `json:"counters,omitempty"`
+	Gauges   []GaugeStat        `json:"gauges,omitempty"`
+	Latency  []LatencyStat      `json:"latency,omitempty"`
+	Values   map[string]float64 `json:"values,omitempty"`
+}
+
+// CounterStat is a counter's per-second rate over the sampling window.
+type CounterStat struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Rate   float64           `json:"rate"`
+}
+
+// GaugeStat is the range a gauge moved in over the sampling window.
+type GaugeStat struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Min    float64           `json:"min"`
+	Max    float64           `json:"max"`
+	Last   float64           `json:"last"`
+}
+
+// LatencyStat holds histogram quantiles; nil when a histogram is empty.
+type LatencyStat struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	P50    *float64          `json:"p50,omitempty"`
+	P90    *float64          `json:"p90,omitempty"`
+	P99    *float64          `json:"p99,omitempty"`
+}
+
+// RegisterBaseline registers metrics.baseline.save and metrics.compare,
+// storing baselines as <dir>/<name>.json.
+func RegisterBaseline(s internal_mcp.ToolAdder, mr types.MetricsReader, dir string) error {
+	if mr == nil || dir == "" {
+		return nil
+	}
+
+	save := mcp.NewTool(
+		"metrics.baseline.save",
+		mcp.WithDescription("Sample metrics over a short window and save counter rates, gauge ranges and latency quantiles as a named baseline, e.g. before a change or load test."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Baseline name (letters, digits, dot, dash, underscore)")),
+		mcp.WithString("window", mcp.Description("Sampling window for rates and ranges (default 5s, max 1m; 0s takes one scrape)")),
+	)
+	saveHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.GetString("name", "")
+		if !baselineNameRe.MatchString(name) {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid baseline name", map[string]any{"name": name}), nil
+		}
+		window, errResult := parseWindow(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		stats, err := collect(ctx, mr, window)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to read metrics", map[string]any{"error": err.Error()}), nil
+		}
+		b := Baseline{Name: name, SavedAt: time.Now().UTC(), Window: window.String(), Stats: *stats}
+		path := filepath.Join(dir, name+".json")
+		if err := saveBaseline(path, &b); err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to save baseline", map[string]any{"error": err.Error()}), nil
+		}
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"name":       name,
+			"path":       path,
+			"saved_at":   b.SavedAt.Format(time.RFC3339),
+			"window":     b.Window,
+			"counters":   len(b.Counters),
+			"gauges":     len(b.Gauges),
+			"histograms": len(b.Latency),
+			"values":     len(b.Values),
+		})
+	}
+	if err := s.AddTool(save, saveHandler); err != nil {
+		return fmt.Errorf("register metrics.baseline.save: %w", err)
+	}
+
+	compare := mcp.NewTool(
+		"metrics.compare",
+		mcp.WithDescription("Sample metrics now and compare them with a saved baseline: counters with unusual rates, gauges outside the baseline range and latency quantile regressions."),
+		mcp.WithString("baseline", mcp.Required(), mcp.Description("Name of a baseline saved with metrics.baseline.save")),
+		mcp.WithString("window", mcp.Description("Sampling window (default 5s, max 1m)")),
+		mcp.WithNumber("tolerance", mcp.Description("Relative change to flag, e.g. 0.25 for 25% (default 0.25)")),
+	)
+	compareHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.GetString("baseline", "")
+		if !baselineNameRe.MatchString(name) {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid baseline name", map[string]any{"baseline": name}), nil
+		}
+		tol := mcp.ParseFloat64(request, "tolerance", defaultTolerance)
+		if tol < 0 {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "tolerance must not be negative", map[string]any{"tolerance": tol}), nil
+		}
+		window, errResult := parseWindow(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		base, err := loadBaseline(filepath.Join(dir, name+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "baseline not found", map[string]any{"baseline": name, "available": listBaselines(dir)}), nil
+		}
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to load baseline", map[string]any{"error": err.Error()}), nil
+		}
+		cur, err := collect(ctx, mr, window)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to read metrics", map[string]any{"error": err.Error()}), nil
+		}
+		findings, newSeries, missing := compareStats(&base.Stats, cur, tol)
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"baseline":       name,
+			"saved_at":       base.SavedAt.Format(time.RFC3339),
+			"compared_at":    time.Now().UTC().Format(time.RFC3339),
+			"window":         window.String(),
+			"tolerance":      tol,
+			"findings":       findings,
+			"count":          len(findings),
+			"new_series":     newSeries,
+			"missing_series": missing,
+		})
+	}
+	if err := s.AddTool(compare, compareHandler); err != nil {
+		return fmt.Errorf("register metrics.compare: %w", err)
+	}
+	return nil
+}
+
+func parseWindow(request mcp.CallToolRequest) (time.Duration, *mcp.CallToolResult) {
+	raw := request.GetString("window", "")
+	if raw == "" {
+		return defaultWindow, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 || d > maxWindow {
+		return 0, internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "window must be a duration up to 1m", map[string]any{"window": raw})
+	}
+	return d, nil
+}
+
+// collect samples mr over window. Scrapers are read several times to get
+// counter rates, gauge ranges and histogram quantiles of the window's
+// observations; other readers contribute their numeric summary values.
+func collect(ctx context.Context, mr types.MetricsReader, window time.Duration) (*Stats, error) {
+	ms, ok := mr.(types.MetricsScraper)
+	if !ok {
+		summary, err := mr.Summary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]float64{}
+		flatten("", summary, values)
+		return &Stats{Values: values}, nil
+	}
+
+	n := baselineScrapes
+	if window == 0 {
+		n = 1
+	}
+	scrapes := make([]*types.MetricsScrape, 0, n)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(window / time.Duration(n-1)):
+			}
+		}
+		sc, err := ms.Scrape(ctx)
+		if err != nil {
+			return nil, err
+		}
+		scrapes = append(scrapes, sc)
+	}
+	return scrapeStats(scrapes), nil
+}
+
+func scrapeStats(scrapes []*types.MetricsScrape) *Stats {
+	first, last := scrapes[0], scrapes[len(scrapes)-1]
+	stats := &Stats{}
+	all := selector{}
+
+	if len(scrapes) > 1 {
+		elapsed := last.At.Sub(first.At).Seconds()
+		if elapsed > 0 {
+			for _, s := range increase(counterSeries(first), counterSeries(last)) {
+				stats.Counters = append(stats.Counters, CounterStat{Name: s.name, Labels: s.labels, Rate: s.value / elapsed})
+			}
+		}
+	}
+
+	gauges := map[string]*GaugeStat{}
+	var order []string
+	for _, sc := range scrapes {
+		for _, f := range sc.Families {
+			if f.Type != types.MetricGauge {
+				continue
+			}
+			for _, s := range f.Samples {
+				if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+					continue
+				}
+				k := seriesKey(s.Name, s.Labels)
+				g, ok := gauges[k]
+				if !ok {
+					g = &GaugeStat{Name: s.Name, Labels: s.Labels, Min: s.Value, Max: s.Value}
+					gauges[k] = g
+					order = append(order, k)
+				}
+				g.Min, g.Max, g.Last = math.Min(g.Min, s.Value), math.Max(g.Max, s.Value), s.Value
+			}
+		}
+	}
+	for _, k := range order {
+		stats.Gauges = append(stats.Gauges, *gauges[k])
+	}
+
+	// Quantiles of the window's observations when there were any, else of
+	// everything observed so far.
+	buckets := selectSeries(last, all, true, false)
+	if len(scrapes) > 1 {
+		if delta := increase(selectSeries(first, all, true, false), buckets); hasObservations(delta) {
+			buckets = delta
+		}
+	}
+	p50, p90, p99 := quantiles(0.5, buckets), quantiles(0.9, buckets), quantiles(0.99, buckets)
+	for i := range p50 {
+		stats.Latency = append(stats.Latency, LatencyStat{
+			Name:   p50[i].name,
+			Labels: p50[i].labels,
+			P50:    finite(p50[i].value),
+			P90:    finite(p90[i].value),
+			P99:    finite(p99[i].value),
+		})
+	}
+	return stats
+}
+
+// counterSeries returns counter samples, including untyped *_total ones.
+func counterSeries(sc *types.MetricsScrape) []series {
+	var out []series
+	for _, f := range sc.Families {
+		if f.Type != types.MetricCounter && (f.Type != types.MetricUntyped || !strings.HasSuffix(f.Name, "_total")) {
+			continue
+		}
+		for _, s := range f.Samples {
+			if !strings.HasSuffix(s.Name, "_created") {
+				out = append(out, series{name: s.Name, labels: s.Labels, value: s.Value})
+			}
+		}
+	}
+	return out
+}
+
+func hasObservations(buckets []series) bool {
+	for _, b := range buckets {
+		if b.value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func finite(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// flatten collects numeric leaves of a summary map under dotted paths.
+func flatten(prefix string, v any, out map[string]float64) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+			flatten(p, child, out)
+		}
+	case float64:
+		out[prefix] = t
+	case float32:
+		out[prefix] = float64(t)
+	case int:
+		out[prefix] = float64(t)
+	case int64:
+		out[prefix] = float64(t)
+	case uint64:
+		out[prefix] = float64(t)
+	case json.Number:
+		if f, err := t.Float64(); err == nil {
+			out[prefix] = f
+		}
+	}
+}
+
+// Finding kinds reported by metrics.compare.
+const (
+	findingCounterRate = "counter_rate"
+	findingGaugeRange  = "gauge_out_of_range"
+	findingLatency     = "latency_regression"
+	findingValue       = "value_change"
+)
+
+// compareStats flags differences beyond tol and counts series that exist
+// on only one side.
+func compareStats(base, cur *Stats, tol float64) (findings []map[string]any, newSeries, missing int) {
+	finding := func(kind, name string, labels map[string]string, b, c float64) map[string]any {
+		f := map[string]any{"kind": kind, "metric": name, "baseline": b, "current": c}
+		if len(labels) > 0 {
+			f["labels"] = labels
+		}
+		if b != 0 {
+			f["change_pct"] = math.Round((c-b)/math.Abs(b)*1000) / 10
+		}
+		return f
+	}
+	seen := map[string]bool{}
+	track := func(k string, ok bool) {
+		seen[k] = true
+		if !ok {
+			newSeries++
+		}
+	}
+
+	counters := map[string]CounterStat{}
+	for _, c := range base.Counters {
+		counters[seriesKey(c.Name, c.Labels)] = c
+	}
+	for _, c := range cur.Counters {
+		k := seriesKey(c.Name, c.Labels)
+		b, ok := counters[k]
+		track(k, ok)
+		if !ok || (b.Rate < minRate && c.Rate < minRate) {
+			continue
+		}
+		if b.Rate < minRate || math.Abs(c.Rate-b.Rate)/b.Rate > tol {
+			findings = append(findings, finding(findingCounterRate, c.Name, c.Labels, b.Rate, c.Rate))
+		}
+	}
+
+	gauges := map[string]GaugeStat{}
+	for _, g := range base.Gauges {
+		gauges[seriesKey(g.Name, g.Labels)] = g
+	}
+	for _, g := range cur.Gauges {
+		k := seriesKey(g.Name, g.Labels)
+		b, ok := gauges[k]
+		track(k, ok)
+		if !ok {
+			continue
+		}
+		lo, hi := b.Min-tol*math.Abs(b.Min), b.Max+tol*math.Abs(b.Max)
+		if g.Max > hi || g.Min < lo {
+			v := g.Max
+			if g.Min < lo && lo-g.Min > g.Max-hi {
+				v = g.Min
+			}
+			f := finding(findingGaugeRange, g.Name, g.Labels, b.Last, v)
+			f["baseline_range"] = []float64{b.Min, b.Max}
+			findings = append(findings, f)
+		}
+	}
+
+	latency := map[string]LatencyStat{}
+	for _, l := range base.Latency {
+		latency[seriesKey(l.Name, l.Labels)] = l
+	}
+	for _, l := range cur.Latency {
+		k := seriesKey(l.Name, l.Labels)
+		b, ok := latency[k]
+		track(k, ok)
+		if !ok {
+			continue
+		}
+		for _, q := range []struct {
+			name      string
+			base, cur *float64
+		}{{"p50", b.P50, l.P50}, {"p90", b.P90, l.P90}, {"p99", b.P99, l.P99}} {
+			if q.base == nil || q.cur == nil || *q.cur <= *q.base*(1+tol) {
+				continue
+			}
+			f := finding(findingLatency, l.Name, l.Labels, *q.base, *q.cur)
+			f["quantile"] = q.name
+			findings = append(findings, f)
+		}
+	}
+
+	for path, c := range cur.Values {
+		b, ok := base.Values[path]
+		track("value:"+path, ok)
+		if !ok {
+			continue
+		}
+		if (b == 0 && c != 0) || (b != 0 && math.Abs(c-b)/math.Abs(b) > tol) {
+			findings = append(findings, finding(findingValue, path, nil, b, c))
+		}
+	}
+
+	for _, k := range baseKeys(base) {
+		if !seen[k] {
+			missing++
+		}
+	}
+
+	rank := map[string]int{findingLatency: 0, findingCounterRate: 1, findingGaugeRange: 2, findingValue: 3}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if ka, kb := rank[a["kind"].(string)], rank[b["kind"].(string)]; ka != kb {
+			return ka < kb
+		}
+		ca, _ := a["change_pct"].(float64)
+		cb, _ := b["change_pct"].(float64)
+		if math.Abs(ca) != math.Abs(cb) {
+			return math.Abs(ca) > math.Abs(cb)
+		}
+		return a["metric"].(string) < b["metric"].(string)
+	})
+	if findings == nil {
+		findings = []map[string]any{}
+	}
+	return findings, newSeries, missing
+}
+
+func baseKeys(s *Stats) []string {
+	var keys []string
+	for _, c := range s.Counters {
+		keys = append(keys, seriesKey(c.Name, c.Labels))
+	}
+	for _, g := range s.Gauges {
+		keys = append(keys, seriesKey(g.Name, g.Labels))
+	}
+	for _, l := range s.Latency {
+		keys = append(keys, seriesKey(l.Name, l.Labels))
+	}
+	for path := range s.Values {
+		keys = append(keys, "value:"+path)
+	}
+	return keys
+}
+
+func saveBaseline(path string, b *Baseline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal baseline: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+func loadBaseline(path string) (*Baseline, error) {
+	// #nosec G304 -- the name is validated and the directory is configured by the host.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("decode baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+func listBaselines(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	names := []string{}
+	if err != nil {
+		return names
+	}
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
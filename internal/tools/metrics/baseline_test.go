@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// loadGen simulates a service: each scrape is one second later, the
+// request counter grows by rps and every request lands in slowBucket.
+type loadGen struct {
+	n          int
+	rps        float64
+	queue      float64
+	slowBucket int
+	requests   float64
+	buckets    [3]float64
+}
+
+func (g *loadGen) Summary(context.Context) (map[string]any, error) { return nil, nil }
+
+func (g *loadGen) Scrape(context.Context) (*types.MetricsScrape, error) {
+	at := time.Unix(1700000000+int64(g.n), 0)
+	g.n++
+	g.requests += g.rps
+	for i := g.slowBucket; i < 3; i++ {
+		g.buckets[i] += g.rps
+	}
+	les := []string{"0.1", "1", "+Inf"}
+	var buckets []types.MetricSample
+	for i, le := range les {
+		buckets = append(buckets, types.MetricSample{Name: "latency_seconds_bucket", Labels: map[string]string{"le": le}, Value: g.buckets[i]})
+	}
+	return &types.MetricsScrape{At: at, Families: []types.MetricFamily{
+		{Name: "requests_total", Type: types.MetricCounter, Samples: []types.MetricSample{{Name: "requests_total", Value: g.requests}}},
+		{Name: "queue_depth", Type: types.MetricGauge, Samples: []types.MetricSample{{Name: "queue_depth", Value: g.queue}}},
+		{Name: "latency_seconds", Type: types.MetricHistogram, Samples: buckets},
+	}}, nil
+}
+
+type summaryOnly map[string]any
+
+func (s summaryOnly) Summary(context.Context) (map[string]any, error) { return s, nil }
+
+type multiAdder struct {
+	handlers map[string]internal_mcp.ToolHandler
+}
+
+func (m *multiAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	if m.handlers == nil {
+		m.handlers = map[string]internal_mcp.ToolHandler{}
+	}
+	m.handlers[tool.Name] = handler
+	return nil
+}
+
+func (m *multiAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func (m *multiAdder) call(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handlers[name](context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestBaseline_SaveAndCompare(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".scg", "metrics")
+	gen := &loadGen{rps: 10, queue: 5}
+	s := &multiAdder{}
+	if err := RegisterBaseline(s, gen, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	res := s.call(t, "metrics.baseline.save", map[string]any{"name": "before", "window": "4ms"})
+	if res.IsError {
+		t.Fatalf("save: %v", res.Content)
+	}
+	saved := res.StructuredContent.(map[string]any)
+	if saved["counters"] != 1 || saved["gauges"] != 1 || saved["histograms"] != 1 {
+		t.Errorf("saved = %v", saved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "before.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged load: nothing to report.
+	res = s.call(t, "metrics.compare", map[string]any{"baseline": "before", "window": "4ms"})
+	if out := res.StructuredContent.(map[string]any); out["count"] != 0 {
+		t.Errorf("unchanged findings = %v", out["findings"])
+	}
+
+	// Triple the rate, grow the queue and push requests into the slow bucket.
+	gen.rps, gen.queue, gen.slowBucket = 30, 50, 1
+	res = s.call(t, "metrics.compare", map[string]any{"baseline": "before", "window": "4ms"})
+	out := res.StructuredContent.(map[string]any)
+	kinds := map[string]map[string]any{}
+	for _, f := range out["findings"].([]map[string]any) {
+		kinds[f["kind"].(string)+":"+f["metric"].(string)] = f
+	}
+	if f := kinds["counter_rate:requests_total"]; f == nil || f["baseline"] != 10.0 || f["current"] != 30.0 || f["change_pct"] != 200.0 {
+		t.Errorf("counter finding = %v", f)
+	}
+	if f := kinds["gauge_out_of_range:queue_depth"]; f == nil || f["current"] != 50.0 {
+		t.Errorf("gauge finding = %v", f)
+	}
+	if f := kinds["latency_regression:latency_seconds"]; f == nil || f["quantile"] == nil {
+		t.Errorf("latency finding = %v in %v", f, out["findings"])
+	}
+	if first := out["findings"].([]map[string]any)[0]; first["kind"] != findingLatency {
+		t.Errorf("first finding = %v, want latency regressions first", first)
+	}
+}
+
+func TestBaseline_SummaryFallback(t *testing.T) {
+	dir := t.TempDir()
+	mr := summaryOnly{"http": map[string]any{"rps": 100.0, "errors": 1}}
+	s := &multiAdder{}
+	if err := RegisterBaseline(s, mr, dir); err != nil {
+		t.Fatal(err)
+	}
+	if res := s.call(t, "metrics.baseline.save", map[string]any{"name": "v1"}); res.IsError {
+		t.Fatalf("save: %v", res.Content)
+	}
+	mr["http"].(map[string]any)["rps"] = 40.0
+	mr["http"].(map[string]any)["p99_ms"] = 12.0
+	out := s.call(t, "metrics.compare", map[string]any{"baseline": "v1"}).StructuredContent.(map[string]any)
+	findings := out["findings"].([]map[string]any)
+	if len(findings) != 1 || findings[0]["metric"] != "http.rps" || findings[0]["change_pct"] != -60.0 {
+		t.Errorf("findings = %v", findings)
+	}
+	if out["new_series"] != 1 || out["missing_series"] != 0 {
+		t.Errorf("new/missing = %v/%v", out["new_series"], out["missing_series"])
+	}
+}
+
+func TestBaseline_InvalidInput(t *testing.T) {
+	dir := t.TempDir()
+	s := &multiAdder{}
+	if err := RegisterBaseline(s, summaryOnly{}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if res := s.call(t, "metrics.baseline.save", map[string]any{"name": "../escape"}); !res.IsError {
+		t.Error("path traversal accepted")
+	}
+	if res := s.call(t, "metrics.compare", map[string]any{"baseline": "missing"}); !res.IsError {
+		t.Error("missing baseline accepted")
+	}
+
+	none := &multiAdder{}
+	if err := RegisterBaseline(none, summaryOnly{}, ""); err != nil || none.handlers != nil {
+		t.Error("registered without a directory")
+	}
+}
@@ -0,0 +1,318 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const (
+	defaultLimit  = 100
+	maxLimit      = 1000
+	defaultWindow = 5 * time.Second
+	maxWindow     = time.Minute
+)
+
+// series is a sample keyed by name and labels.
+type series struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func seriesKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("\xff" + k + "=" + labels[k])
+	}
+	return b.String()
+}
+
+// RegisterQuery registers the metrics.query tool for readers that
+// implement types.MetricsScraper.
+func RegisterQuery(s internal_mcp.ToolAdder, ms types.MetricsScraper) error {
+	if ms == nil {
+		return nil
+	}
+
+	tool := mcp.NewTool(
+		"metrics.query",
+		mcp.WithDescription("Query scraped Prometheus metrics: filter series with a selector, compute per-second rates between two scrapes and histogram quantiles from buckets. Example: p99 latency of /orders is selector http_request_duration_seconds{route=\"/orders\"}, quantile 0.99."),
+		mcp.WithString("selector", mcp.Required(), mcp.Description(`Metric name and optional label matchers (=, !=, =~, !~), e.g. http_requests_total{status=~"5.."}`)),
+		mcp.WithNumber("quantile", mcp.Description("Histogram quantile between 0 and 1, computed from _bucket series")),
+		mcp.WithBoolean("rate", mcp.Description("Report per-second rates (or quantiles of the increase) over a window between two scrapes; gauges are skipped")),
+		mcp.WithString("window", mcp.Description("Time between the two scrapes for rate (default 5s, max 1m)")),
+		mcp.WithString("by", mcp.Description("Comma-separated labels to sum by; other labels are aggregated away")),
+		mcp.WithNumber("limit", mcp.Description("Maximum series (default 100, max 1000)")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sel, err := parseSelector(request.GetString("selector", ""))
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid selector", map[string]any{"error": err.Error()}), nil
+		}
+		if sel.name == "" && len(sel.matchers) == 0 {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "selector is required", nil), nil
+		}
+		q := mcp.ParseFloat64(request, "quantile", -1)
+		_, hasQuantile := request.GetArguments()["quantile"]
+		if hasQuantile && (q < 0 || q > 1) {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "quantile must be between 0 and 1", map[string]any{"quantile": q}), nil
+		}
+		rate := request.GetBool("rate", false)
+		window := defaultWindow
+		if raw := request.GetString("window", ""); raw != "" {
+			window, err = time.ParseDuration(raw)
+			if err != nil || window <= 0 || window > maxWindow {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "window must be a positive duration up to 1m", map[string]any{"window": raw}), nil
+			}
+		}
+		by := argparse.List(request.GetString("by", ""))
+		limit := int(mcp.ParseFloat64(request, "limit", defaultLimit))
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+		limit = min(limit, maxLimit)
+
+		first, err := ms.Scrape(ctx)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to scrape metrics", map[string]any{"error": err.Error()}), nil
+		}
+		out := map[string]any{"selector": request.GetString("selector", ""), "scraped_at": first.At.Format(time.RFC3339)}
+		values := selectSeries(first, sel, hasQuantile, rate)
+		if rate {
+			select {
+			case <-ctx.Done():
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "canceled while waiting for the second scrape", nil), nil
+			case <-time.After(window):
+			}
+			second, err := ms.Scrape(ctx)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to scrape metrics", map[string]any{"error": err.Error()}), nil
+			}
+			elapsed := second.At.Sub(first.At).Seconds()
+			if elapsed <= 0 {
+				elapsed = window.Seconds()
+			}
+			values = increase(values, selectSeries(second, sel, hasQuantile, rate))
+			if !hasQuantile {
+				for i := range values {
+					values[i].value /= elapsed
+				}
+			}
+			out["scraped_at"] = second.At.Format(time.RFC3339)
+			out["window"] = window.String()
+		}
+		if by != nil {
+			values = sumBy(values, by, hasQuantile)
+		}
+
+		var rows []map[string]any
+		if hasQuantile {
+			out["mode"] = "quantile"
+			out["quantile"] = q
+			for _, g := range quantiles(q, values) {
+				rows = append(rows, map[string]any{"name": g.name, "labels": g.labels, "value": jsonFloat(g.value)})
+			}
+		} else {
+			out["mode"] = "value"
+			if rate {
+				out["mode"] = "rate"
+			}
+			sort.Slice(values, func(i, j int) bool {
+				return seriesKey(values[i].name, values[i].labels) < seriesKey(values[j].name, values[j].labels)
+			})
+			for _, v := range values {
+				rows = append(rows, map[string]any{"name": v.name, "labels": v.labels, "value": jsonFloat(v.value)})
+			}
+		}
+		if len(rows) > limit {
+			rows = rows[:limit]
+			out["truncated"] = true
+		}
+		if rows == nil {
+			rows = []map[string]any{}
+		}
+		out["series"] = rows
+		out["count"] = len(rows)
+		return internal_mcp.NewToolResultJSON(out)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register metrics.query: %w", err)
+	}
+	return nil
+}
+
+// selectSeries returns the samples matching sel. Quantile queries keep
+// only histogram buckets; rate queries skip gauges.
+func selectSeries(scrape *types.MetricsScrape, sel selector, buckets, rate bool) []series {
+	var out []series
+	for _, f := range scrape.Families {
+		if buckets && f.Type != types.MetricHistogram {
+			continue
+		}
+		if rate && f.Type == types.MetricGauge {
+			continue
+		}
+		for _, s := range f.Samples {
+			if buckets && s.Name != f.Name+"_bucket" {
+				continue
+			}
+			if strings.HasSuffix(s.Name, "_created") || !sel.match(f.Name, s.Name, s.Labels) {
+				continue
+			}
+			out = append(out, series{name: s.Name, labels: s.Labels, value: s.Value})
+		}
+	}
+	return out
+}
+
+// increase returns cur minus prev per series, treating a drop as a counter
+// reset. Series absent from prev are skipped.
+func increase(prev, cur []series) []series {
+	before := make(map[string]float64, len(prev))
+	for _, s := range prev {
+		before[seriesKey(s.name, s.labels)] = s.value
+	}
+	out := make([]series, 0, len(cur))
+	for _, s := range cur {
+		p, ok := before[seriesKey(s.name, s.labels)]
+		if !ok {
+			continue
+		}
+		d := s.value - p
+		if d < 0 {
+			d = s.value
+		}
+		out = append(out, series{name: s.name, labels: s.labels, value: d})
+	}
+	return out
+}
+
+// sumBy sums series sharing the name and the by labels; keepLE preserves
+// the bucket bound for quantiles.
+func sumBy(in []series, by []string, keepLE bool) []series {
+	index := map[string]int{}
+	var out []series
+	for _, s := range in {
+		labels := make(map[string]string, len(by)+1)
+		for _, l := range by {
+			if v, ok := s.labels[l]; ok {
+				labels[l] = v
+			}
+		}
+		if keepLE {
+			labels["le"] = s.labels["le"]
+		}
+		k := seriesKey(s.name, labels)
+		if i, ok := index[k]; ok {
+			out[i].value += s.value
+			continue
+		}
+		index[k] = len(out)
+		out = append(out, series{name: s.name, labels: labels, value: s.value})
+	}
+	return out
+}
+
+type bucket struct {
+	upper float64
+	count float64
+}
+
+// quantiles groups _bucket series by their labels without le and computes
+// the q quantile of each group, sorted by labels.
+func quantiles(q float64, buckets []series) []series {
+	groups := map[string]*series{}
+	bounds := map[string][]bucket{}
+	for _, s := range buckets {
+		upper, err := strconv.ParseFloat(s.labels["le"], 64)
+		if err != nil {
+			continue
+		}
+		labels := make(map[string]string, len(s.labels))
+		for k, v := range s.labels {
+			if k != "le" {
+				labels[k] = v
+			}
+		}
+		name := strings.TrimSuffix(s.name, "_bucket")
+		k := seriesKey(name, labels)
+		if groups[k] == nil {
+			groups[k] = &series{name: name, labels: labels}
+		}
+		bounds[k] = append(bounds[k], bucket{upper: upper, count: s.value})
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]series, 0, len(keys))
+	for _, k := range keys {
+		g := groups[k]
+		g.value = histogramQuantile(q, bounds[k])
+		out = append(out, *g)
+	}
+	return out
+}
+
+// histogramQuantile interpolates the q quantile from cumulative buckets
+// the way Prometheus' histogram_quantile does. It returns NaN without a
+// +Inf bucket or observations.
+func histogramQuantile(q float64, buckets []bucket) float64 {
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].upper < buckets[j].upper })
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upper, 1) {
+		return math.NaN()
+	}
+	// Buckets scraped mid-update can be slightly non-monotonic.
+	for i := 1; i < len(buckets); i++ {
+		buckets[i].count = math.Max(buckets[i].count, buckets[i-1].count)
+	}
+	total := buckets[len(buckets)-1].count
+	if total == 0 {
+		return math.NaN()
+	}
+	rank := q * total
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].count >= rank })
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].upper
+	}
+	if b == 0 && buckets[0].upper <= 0 {
+		return buckets[0].upper
+	}
+	start, end, count := 0.0, buckets[b].upper, buckets[b].count
+	if b > 0 {
+		start = buckets[b-1].upper
+		count -= buckets[b-1].count
+		rank -= buckets[b-1].count
+	}
+	if count == 0 {
+		return end
+	}
+	return start + (end-start)*(rank/count)
+}
+
+// jsonFloat returns nil for values JSON cannot represent.
+func jsonFloat(v float64) any {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+// scrapes returns its scrapes in order, repeating the last one.
+type scrapes []*types.MetricsScrape
+
+func (s *scrapes) Scrape(context.Context) (*types.MetricsScrape, error) {
+	next := (*s)[0]
+	if len(*s) > 1 {
+		*s = (*s)[1:]
+	}
+	return next, nil
+}
+
+func scrape(at time.Time, requests, resets float64, buckets [3]float64) *types.MetricsScrape {
+	bucket := func(route, le string, v float64) types.MetricSample {
+		return types.MetricSample{Name: "http_request_duration_seconds_bucket", Labels: map[string]string{"route": route, "le": le}, Value: v}
+	}
+	return &types.MetricsScrape{At: at, Families: []types.MetricFamily{
+		{Name: "http_requests_total", Type: types.MetricCounter, Samples: []types.MetricSample{
+			{Name: "http_requests_total", Labels: map[string]string{"route": "/orders", "status": "200"}, Value: requests},
+			{Name: "http_requests_total", Labels: map[string]string{"route": "/orders", "status": "500"}, Value: resets},
+			{Name: "http_requests_total", Labels: map[string]string{"route": "/users", "status": "200"}, Value: 1},
+		}},
+		{Name: "http_request_duration_seconds", Type: types.MetricHistogram, Samples: []types.MetricSample{
+			bucket("/orders", "0.1", buckets[0]),
+			bucket("/orders", "0.5", buckets[1]),
+			bucket("/orders", "+Inf", buckets[2]),
+			bucket("/users", "0.1", 0),
+			bucket("/users", "+Inf", 0),
+			{Name: "http_request_duration_seconds_count", Labels: map[string]string{"route": "/orders"}, Value: buckets[2]},
+		}},
+		{Name: "queue_depth", Type: types.MetricGauge, Samples: []types.MetricSample{{Name: "queue_depth", Value: 7}}},
+	}}
+}
+
+func query(t *testing.T, ms types.MetricsScraper, args map[string]any) map[string]any {
+	t.Helper()
+	s := &mockToolAdder{}
+	if err := RegisterQuery(s, ms); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "metrics.query", Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool error: %v", res.Content)
+	}
+	return res.StructuredContent.(map[string]any)
+}
+
+func values(out map[string]any) []any {
+	var vs []any
+	for _, row := range out["series"].([]map[string]any) {
+		vs = append(vs, row["value"])
+	}
+	return vs
+}
+
+func TestQuery_Selector(t *testing.T) {
+	now := time.Now()
+	ms := &scrapes{scrape(now, 1000, 10, [3]float64{80, 95, 100})}
+	out := query(t, ms, map[string]any{"selector": `http_requests_total{route="/orders",status=~"2.."}`})
+	if out["mode"] != "value" || out["count"] != 1 || values(out)[0] != 1000.0 {
+		t.Errorf("out = %v", out)
+	}
+
+	out = query(t, ms, map[string]any{"selector": `http_requests_total`, "by": "route"})
+	if out["count"] != 2 || values(out)[0] != 1010.0 {
+		t.Errorf("sum by route = %v", out["series"])
+	}
+
+	out = query(t, ms, map[string]any{"selector": `{__name__=~"queue_.*"}`})
+	if out["count"] != 1 || values(out)[0] != 7.0 {
+		t.Errorf("__name__ matcher = %v", out["series"])
+	}
+}
+
+func TestQuery_Quantile(t *testing.T) {
+	ms := &scrapes{scrape(time.Now(), 0, 0, [3]float64{80, 95, 100})}
+	cases := map[float64]any{0.5: 0.0625, 0.99: 0.5}
+	for q, want := range cases {
+		out := query(t, ms, map[string]any{"selector": `http_request_duration_seconds{route="/orders"}`, "quantile": q})
+		if out["count"] != 1 || values(out)[0] != want {
+			t.Errorf("p%v = %v, want %v", q*100, out["series"], want)
+		}
+	}
+
+	// No observations: value is null rather than NaN.
+	out := query(t, ms, map[string]any{"selector": `http_request_duration_seconds{route="/users"}`, "quantile": 0.9})
+	if vs := values(out); len(vs) != 1 || vs[0] != nil {
+		t.Errorf("empty histogram = %v", out["series"])
+	}
+}
+
+func TestQuery_Rate(t *testing.T) {
+	t0 := time.Now()
+	ms := &scrapes{
+		scrape(t0, 1000, 10, [3]float64{80, 95, 100}),
+		scrape(t0.Add(2*time.Second), 1010, 4, [3]float64{80, 95, 110}),
+	}
+	out := query(t, ms, map[string]any{"selector": `http_requests_total{route="/orders"}`, "rate": true, "window": "1ms"})
+	if out["mode"] != "rate" || out["window"] != "1ms" {
+		t.Errorf("out = %v", out)
+	}
+	// 10 new requests in 2s; the 500 counter reset to 4.
+	if vs := values(out); len(vs) != 2 || vs[0] != 5.0 || vs[1] != 2.0 {
+		t.Errorf("rates = %v", out["series"])
+	}
+
+	ms = &scrapes{
+		scrape(t0, 0, 0, [3]float64{80, 95, 100}),
+		scrape(t0.Add(time.Second), 0, 0, [3]float64{80, 95, 110}),
+	}
+	// All 10 new observations were above 0.5s.
+	out = query(t, ms, map[string]any{"selector": `http_request_duration_seconds{route="/orders"}`, "quantile": 0.5, "rate": true, "window": "1ms"})
+	if vs := values(out); len(vs) != 1 || vs[0] != 0.5 {
+		t.Errorf("windowed p50 = %v", out["series"])
+	}
+}
+
+func TestQuery_InvalidInput(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := RegisterQuery(s, &scrapes{scrape(time.Now(), 0, 0, [3]float64{})}); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range []map[string]any{
+		{"selector": ""},
+		{"selector": `m{a="b"`},
+		{"selector": `m{a=~"("}`},
+		{"selector": "m", "quantile": 1.5},
+		{"selector": "m", "rate": true, "window": "1h"},
+	} {
+		res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "metrics.query", Arguments: args}})
+		if err != nil || !res.IsError {
+			t.Errorf("args %v: err = %v, isError = %v", args, err, res != nil && res.IsError)
+		}
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	got := histogramQuantile(0.9, []bucket{{0.5, 95}, {0.1, 80}, {math.Inf(1), 100}})
+	if math.Abs(got-(0.1+0.4*10/15)) > 1e-9 {
+		t.Errorf("p90 = %v", got)
+	}
+	if !math.IsNaN(histogramQuantile(0.5, []bucket{{0.1, 1}, {0.5, 2}})) {
+		t.Error("missing +Inf bucket should be NaN")
+	}
+}
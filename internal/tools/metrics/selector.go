@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matcher is one label matcher of a selector; label __name__ matches the
+// sample name.
+type matcher struct {
+	label string
+	op    string // =, !=, =~ or !~
+	value string
+	re    *regexp.Regexp
+}
+
+func (m matcher) matches(v string) bool {
+	switch m.op {
+	case "=":
+		return v == m.value
+	case "!=":
+		return v != m.value
+	case "=~":
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
+
+// selector is a PromQL-style series selector such as
+// http_request_duration_seconds{route="/orders",method=~"GET|POST"}.
+type selector struct {
+	name     string
+	matchers []matcher
+}
+
+// match reports whether a sample of family matches. The metric name may
+// be the family name or the full sample name.
+func (s selector) match(family, sample string, labels map[string]string) bool {
+	if s.name != "" && s.name != family && s.name != sample {
+		return false
+	}
+	for _, m := range s.matchers {
+		v := labels[m.label]
+		if m.label == "__name__" {
+			v = sample
+		}
+		if !m.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSelector parses `name`, `name{matchers}` or `{matchers}`.
+func parseSelector(in string) (selector, error) {
+	var sel selector
+	in = strings.TrimSpace(in)
+	brace := strings.IndexByte(in, '{')
+	if brace < 0 {
+		sel.name = in
+		return sel, nil
+	}
+	sel.name = strings.TrimSpace(in[:brace])
+	body, ok := strings.CutSuffix(strings.TrimSpace(in[brace+1:]), "}")
+	if !ok {
+		return sel, fmt.Errorf("missing closing brace")
+	}
+	for {
+		body = strings.TrimLeft(body, " \t,")
+		if body == "" {
+			return sel, nil
+		}
+		op := strings.IndexAny(body, "=!")
+		if op <= 0 {
+			return sel, fmt.Errorf("malformed matcher %q", body)
+		}
+		m := matcher{label: strings.TrimSpace(body[:op])}
+		body = body[op:]
+		for _, o := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(body, o) {
+				m.op, body = o, strings.TrimLeft(body[len(o):], " \t")
+				break
+			}
+		}
+		if m.op == "" || !strings.HasPrefix(body, `"`) {
+			return sel, fmt.Errorf("matcher %s: expected an operator and a quoted value", m.label)
+		}
+		end := closingQuote(body[1:])
+		if end < 0 {
+			return sel, fmt.Errorf("matcher %s: unterminated value", m.label)
+		}
+		m.value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(body[1 : end+1])
+		body = body[end+2:]
+		if m.op == "=~" || m.op == "!~" {
+			re, err := regexp.Compile("^(?:" + m.value + ")$")
+			if err != nil {
+				return sel, fmt.Errorf("matcher %s: %w", m.label, err)
+			}
+			m.re = re
+		}
+		sel.matchers = append(sel.matchers, m)
+	}
+}
+
+// closingQuote returns the index of the first unescaped quote in s.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
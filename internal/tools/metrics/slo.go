@@ -0,0 +1,432 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSLOFile is the SLO definition file relative to the project root.
+const DefaultSLOFile = ".scg/slo.yaml"
+
+// SLO types.
+const (
+	SLOAvailability = "availability"
+	SLOLatency      = "latency"
+)
+
+// Burn rate thresholds: fast burn spends a 30-day budget in about two days.
+const (
+	fastBurnRate = 14.4
+	maxSLOSample = 10000
+)
+
+var defaultSLOWindows = []string{"5m", "1h", "6h"}
+
+// SLOFile is the schema of .scg/slo.yaml.
+//
+//	windows: [5m, 1h, 6h]
+//	slos:
+//	  - name: orders-availability
+//	    objective: 99.9
+//	    total: http_requests_total{route="/orders"}
+//	    errors: http_requests_total{route="/orders",status=~"5.."}
+//	  - name: orders-latency
+//	    type: latency
+//	    objective: 99
+//	    histogram: http_request_duration_seconds{route="/orders"}
+//	    threshold: 0.3
+type SLOFile struct {
+	Windows []string `yaml:"windows"`
+	SLOs    []SLO    `yaml:"slos"`
+}
+
+// SLO is one service level objective over metric selectors. Availability
+// SLOs count errors (or good events) against total; latency SLOs count
+// histogram observations at or below Threshold as good.
+type SLO struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Type        string  `yaml:"type"`
+	Objective   float64 `yaml:"objective"` // percent, e.g. 99.9
+	Total       string  `yaml:"total"`
+	Errors      string  `yaml:"errors"`
+	Good        string  `yaml:"good"`
+	Histogram   string  `yaml:"histogram"`
+	Threshold   float64 `yaml:"threshold"`
+}
+
+// LoadSLOs reads and validates an SLO file.
+func LoadSLOs(path string) (*SLOFile, error) {
+	// #nosec G304 -- the SLO path is configured by the host or CLI user.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f SLOFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(f.Windows) == 0 {
+		f.Windows = defaultSLOWindows
+	}
+	for _, w := range f.Windows {
+		if d, err := time.ParseDuration(w); err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s: invalid window %q", path, w)
+		}
+	}
+	// Shortest first: fast burn compares the two shortest windows.
+	sort.SliceStable(f.Windows, func(i, j int) bool {
+		a, _ := time.ParseDuration(f.Windows[i])
+		b, _ := time.ParseDuration(f.Windows[j])
+		return a < b
+	})
+	seen := map[string]bool{}
+	for i := range f.SLOs {
+		s := &f.SLOs[i]
+		if s.Type == "" {
+			s.Type = SLOAvailability
+			if s.Histogram != "" {
+				s.Type = SLOLatency
+			}
+		}
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("%s: slo %q: %w", path, s.Name, err)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("%s: duplicate slo %q", path, s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return &f, nil
+}
+
+func (s *SLO) validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.Objective <= 0 || s.Objective >= 100 {
+		return errors.New("objective must be a percentage between 0 and 100")
+	}
+	selectors := []string{s.Histogram}
+	switch s.Type {
+	case SLOAvailability:
+		if s.Total == "" || (s.Errors == "") == (s.Good == "") {
+			return errors.New("availability needs total and exactly one of errors or good")
+		}
+		selectors = []string{s.Total, s.Errors, s.Good}
+	case SLOLatency:
+		if s.Histogram == "" || s.Threshold <= 0 {
+			return errors.New("latency needs histogram and a positive threshold")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+	for _, sel := range selectors {
+		if sel == "" {
+			continue
+		}
+		if _, err := parseSelector(sel); err != nil {
+			return fmt.Errorf("selector %q: %w", sel, err)
+		}
+	}
+	return nil
+}
+
+// events counts good and total events of an SLO from one scrape. For
+// latency SLOs, note explains which bucket bound was used.
+func (s *SLO) events(sc *types.MetricsScrape) (good, total float64, note string) {
+	if s.Type == SLOLatency {
+		sel, _ := parseSelector(s.Histogram)
+		bound := math.Inf(-1)
+		byBound := map[float64]float64{}
+		for _, b := range selectSeries(sc, sel, true, false) {
+			le, err := strconv.ParseFloat(b.labels["le"], 64)
+			if err != nil {
+				continue
+			}
+			byBound[le] += b.value
+			if le <= s.Threshold && le > bound {
+				bound = le
+			}
+		}
+		total = byBound[math.Inf(1)]
+		if math.IsInf(bound, -1) {
+			return 0, total, "no bucket at or below the threshold"
+		}
+		if bound != s.Threshold {
+			note = fmt.Sprintf("threshold %g is not a bucket bound; using le=%g", s.Threshold, bound)
+		}
+		return byBound[bound], total, note
+	}
+
+	sum := func(raw string) float64 {
+		sel, _ := parseSelector(raw)
+		var v float64
+		for _, s := range selectSeries(sc, sel, false, false) {
+			v += s.value
+		}
+		return v
+	}
+	total = sum(s.Total)
+	if s.Good != "" {
+		return sum(s.Good), total, ""
+	}
+	return total - sum(s.Errors), total, ""
+}
+
+// sloPoint is the cumulative good and total count of one SLO.
+type sloPoint struct {
+	good, total float64
+	note        string
+}
+
+type sloSample struct {
+	at     time.Time
+	points map[string]sloPoint
+}
+
+// SLOTracker evaluates the SLO file against scrapes and keeps a bounded
+// history of event counts for windowed burn rates.
+type SLOTracker struct {
+	ms       types.MetricsScraper
+	path     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []sloSample
+}
+
+// NewSLOTracker returns a tracker for the SLOs in path. interval is the
+// background sampling period used by Run.
+func NewSLOTracker(ms types.MetricsScraper, path string, interval time.Duration) *SLOTracker {
+	return &SLOTracker{ms: ms, path: path, interval: interval}
+}
+
+// Run samples every interval until ctx is canceled. Errors are retried on
+// the next tick; slo.status reports them.
+func (t *SLOTracker) Run(ctx context.Context) {
+	tick := time.NewTicker(t.interval)
+	defer tick.Stop()
+	for {
+		_, _, _ = t.sample(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// sample scrapes once, records the event counts and returns them with the
+// loaded definitions.
+func (t *SLOTracker) sample(ctx context.Context) (*SLOFile, sloSample, error) {
+	f, err := LoadSLOs(t.path)
+	if err != nil {
+		return nil, sloSample{}, err
+	}
+	sc, err := t.ms.Scrape(ctx)
+	if err != nil {
+		return f, sloSample{}, err
+	}
+	cur := sloSample{at: sc.At, points: make(map[string]sloPoint, len(f.SLOs))}
+	for i := range f.SLOs {
+		good, total, note := f.SLOs[i].events(sc)
+		cur.points[f.SLOs[i].Name] = sloPoint{good: good, total: total, note: note}
+	}
+
+	longest := time.Duration(0)
+	for _, w := range f.Windows {
+		d, _ := time.ParseDuration(w)
+		longest = max(longest, d)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, cur)
+	// Keep one sample older than the longest window so it stays covered.
+	drop := 0
+	for drop+1 < len(t.samples) && !t.samples[drop+1].at.After(cur.at.Add(-longest)) {
+		drop++
+	}
+	if n := len(t.samples) - drop; n > maxSLOSample {
+		drop = len(t.samples) - maxSLOSample
+	}
+	t.samples = append([]sloSample(nil), t.samples[drop:]...)
+	return f, cur, nil
+}
+
+// window returns the event increase over d ending at the latest sample,
+// and how much of d the history covers.
+func (t *SLOTracker) window(name string, d time.Duration) (good, total float64, covered time.Duration, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.samples) < 2 {
+		return 0, 0, 0, false
+	}
+	last := t.samples[len(t.samples)-1]
+	start := sort.Search(len(t.samples), func(i int) bool { return !t.samples[i].at.Before(last.at.Add(-d)) })
+	if start > 0 {
+		start-- // the sample just before the window covers it fully
+	}
+	var prev *sloPoint
+	for _, s := range t.samples[start:] {
+		p, found := s.points[name]
+		if !found {
+			continue
+		}
+		if prev == nil {
+			covered = last.at.Sub(s.at)
+		} else {
+			// A drop means the counters were reset.
+			if p.total >= prev.total {
+				good, total = good+p.good-prev.good, total+p.total-prev.total
+			} else {
+				good, total = good+p.good, total+p.total
+			}
+		}
+		prev = &p
+	}
+	return good, total, min(covered, d), prev != nil && covered > 0
+}
+
+// RegisterSLO registers the slo.status tool.
+func RegisterSLO(s internal_mcp.ToolAdder, t *SLOTracker) error {
+	if t == nil {
+		return nil
+	}
+
+	tool := mcp.NewTool(
+		"slo.status",
+		mcp.WithDescription("Evaluate the service level objectives in .scg/slo.yaml against current metrics: SLI, error budget remaining and burn rates over several windows."),
+		mcp.WithString("name", mcp.Description("Only evaluate this SLO")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		f, cur, err := t.sample(ctx)
+		if f == nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "failed to load SLO definitions", map[string]any{"path": t.path, "error": err.Error()}), nil
+		}
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to scrape metrics", map[string]any{"error": err.Error()}), nil
+		}
+		only := request.GetString("name", "")
+		results := []map[string]any{}
+		for i := range f.SLOs {
+			slo := &f.SLOs[i]
+			if only != "" && slo.Name != only {
+				continue
+			}
+			results = append(results, t.evaluate(slo, f.Windows, cur.points[slo.Name]))
+		}
+		if only != "" && len(results) == 0 {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "unknown SLO", map[string]any{"name": only}), nil
+		}
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"evaluated_at": cur.at.Format(time.RFC3339),
+			"period":       "since counters started",
+			"slos":         results,
+		})
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register slo.status: %w", err)
+	}
+	return nil
+}
+
+// SLO evaluation statuses.
+const (
+	sloOK        = "ok"
+	sloNoData    = "no_data"
+	sloBurning   = "burning"
+	sloFastBurn  = "fast_burn"
+	sloExhausted = "exhausted"
+)
+
+func (t *SLOTracker) evaluate(slo *SLO, windows []string, p sloPoint) map[string]any {
+	budget := 1 - slo.Objective/100
+	out := map[string]any{
+		"name":      slo.Name,
+		"type":      slo.Type,
+		"objective": slo.Objective,
+		"good":      p.good,
+		"total":     p.total,
+	}
+	if slo.Description != "" {
+		out["description"] = slo.Description
+	}
+	if p.note != "" {
+		out["note"] = p.note
+	}
+	if p.total <= 0 {
+		out["status"] = sloNoData
+		return out
+	}
+	sli := p.good / p.total
+	remaining := 1 - (1-sli)/budget
+	out["sli"] = round(sli*100, 4)
+	out["error_budget_remaining_pct"] = round(remaining*100, 2)
+
+	var burns []map[string]any
+	var rates []float64
+	for _, w := range windows {
+		d, _ := time.ParseDuration(w)
+		good, total, covered, ok := t.window(slo.Name, d)
+		b := map[string]any{"window": w}
+		switch {
+		case !ok:
+			b["burn_rate"] = nil
+			b["note"] = "not enough samples yet"
+		case total == 0:
+			b["burn_rate"] = 0.0
+			rates = append(rates, 0)
+		default:
+			rate := (1 - good/total) / budget
+			b["burn_rate"] = round(rate, 2)
+			rates = append(rates, rate)
+		}
+		if ok && covered < d {
+			b["covered"] = covered.Round(time.Second).String()
+			b["partial"] = true
+		}
+		burns = append(burns, b)
+	}
+	out["burn_rates"] = burns
+
+	status := sloOK
+	switch {
+	case remaining <= 0:
+		status = sloExhausted
+	case len(rates) >= 2 && rates[0] > fastBurnRate && rates[1] > fastBurnRate:
+		status = sloFastBurn
+	case len(rates) > 0 && allAbove(rates, 1):
+		status = sloBurning
+	}
+	out["status"] = status
+	return out
+}
+
+func allAbove(vs []float64, limit float64) bool {
+	for _, v := range vs {
+		if v <= limit {
+			return false
+		}
+	}
+	return true
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
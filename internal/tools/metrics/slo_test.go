@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const sloYAML = `windows: [1h, 5m]
+slos:
+  - name: orders-availability
+    objective: 99
+    total: http_requests_total{route="/orders"}
+    errors: http_requests_total{route="/orders",status=~"5.."}
+  - name: orders-latency
+    objective: 90
+    histogram: http_request_duration_seconds{route="/orders"}
+    threshold: 0.25
+`
+
+// traffic produces scrapes one minute apart with cumulative counters.
+type traffic struct {
+	at               time.Time
+	ok, failed       float64
+	fast, slow       float64
+	okRate, failRate float64
+}
+
+func (tr *traffic) Scrape(context.Context) (*types.MetricsScrape, error) {
+	tr.at = tr.at.Add(time.Minute)
+	tr.ok += tr.okRate
+	tr.failed += tr.failRate
+	tr.fast += tr.okRate
+	tr.slow += tr.failRate
+	req := func(status string, v float64) types.MetricSample {
+		return types.MetricSample{Name: "http_requests_total", Labels: map[string]string{"route": "/orders", "status": status}, Value: v}
+	}
+	bucket := func(le string, v float64) types.MetricSample {
+		return types.MetricSample{Name: "http_request_duration_seconds_bucket", Labels: map[string]string{"route": "/orders", "le": le}, Value: v}
+	}
+	return &types.MetricsScrape{At: tr.at, Families: []types.MetricFamily{
+		{Name: "http_requests_total", Type: types.MetricCounter, Samples: []types.MetricSample{req("200", tr.ok), req("503", tr.failed)}},
+		{Name: "http_request_duration_seconds", Type: types.MetricHistogram, Samples: []types.MetricSample{
+			bucket("0.1", tr.fast), bucket("0.25", tr.fast), bucket("+Inf", tr.fast+tr.slow),
+		}},
+	}}, nil
+}
+
+func writeSLOs(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "slo.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sloStatus(t *testing.T, tr *SLOTracker) map[string]map[string]any {
+	t.Helper()
+	s := &mockToolAdder{}
+	if err := RegisterSLO(s, tr); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "slo.status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool error: %v", res.Content)
+	}
+	out := map[string]map[string]any{}
+	for _, slo := range res.StructuredContent.(map[string]any)["slos"].([]map[string]any) {
+		out[slo["name"].(string)] = slo
+	}
+	return out
+}
+
+func TestSLOStatus(t *testing.T) {
+	tr := &traffic{at: time.Unix(1700000000, 0), okRate: 1000}
+	tracker := NewSLOTracker(tr, writeSLOs(t, sloYAML), time.Minute)
+
+	// Two hours of clean traffic, then twenty minutes at 5% errors.
+	for i := 0; i < 120; i++ {
+		if _, _, err := tracker.sample(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr.okRate, tr.failRate = 950, 50
+	for i := 0; i < 19; i++ {
+		if _, _, err := tracker.sample(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := sloStatus(t, tracker)
+
+	avail := out["orders-availability"]
+	// 1,000 failures out of 140,000 requests.
+	if avail["sli"] != round((1-1000.0/140000)*100, 4) {
+		t.Errorf("sli = %v", avail["sli"])
+	}
+	if avail["error_budget_remaining_pct"] != round((1-(1000.0/140000)/0.01)*100, 2) {
+		t.Errorf("budget = %v", avail["error_budget_remaining_pct"])
+	}
+	burns := avail["burn_rates"].([]map[string]any)
+	if burns[0]["window"] != "5m" || burns[0]["burn_rate"] != 5.0 {
+		t.Errorf("5m burn = %v", burns[0])
+	}
+	if burns[1]["window"] != "1h" || burns[1]["burn_rate"] != round(1000.0/60000/0.01, 2) {
+		t.Errorf("1h burn = %v", burns[1])
+	}
+	if avail["status"] != sloBurning {
+		t.Errorf("status = %v, want burning", avail["status"])
+	}
+
+	// 5% of requests are slow against a 10% budget.
+	latency := out["orders-latency"]
+	if latency["type"] != SLOLatency || latency["status"] != sloOK || latency["burn_rates"].([]map[string]any)[0]["burn_rate"] != 0.5 {
+		t.Errorf("latency = %v", latency)
+	}
+}
+
+func TestSLOStatus_NotEnoughHistory(t *testing.T) {
+	tr := &traffic{at: time.Unix(1700000000, 0), okRate: 10}
+	tracker := NewSLOTracker(tr, writeSLOs(t, sloYAML), time.Minute)
+	out := sloStatus(t, tracker)
+	avail := out["orders-availability"]
+	if avail["sli"] != 100.0 || avail["burn_rates"].([]map[string]any)[0]["burn_rate"] != nil {
+		t.Errorf("first sample = %v", avail)
+	}
+	out = sloStatus(t, tracker)
+	b := out["orders-availability"]["burn_rates"].([]map[string]any)[0]
+	if b["partial"] != true || b["covered"] != "1m0s" || b["burn_rate"] != 0.0 {
+		t.Errorf("second sample 5m burn = %v", b)
+	}
+}
+
+func TestLoadSLOs_Invalid(t *testing.T) {
+	for name, body := range map[string]string{
+		"objective": "slos:\n  - name: a\n    objective: 100\n    total: x\n    errors: y\n",
+		"both":      "slos:\n  - name: a\n    objective: 99\n    total: x\n    errors: y\n    good: z\n",
+		"threshold": "slos:\n  - name: a\n    objective: 99\n    histogram: h\n",
+		"selector":  "slos:\n  - name: a\n    objective: 99\n    total: x{a=\n    errors: y\n",
+		"duplicate": "slos:\n  - {name: a, objective: 99, total: x, errors: y}\n  - {name: a, objective: 99, total: x, errors: y}\n",
+		"window":    "windows: [soon]\nslos: []\n",
+		"not yaml":  "slos: [",
+	} {
+		if _, err := LoadSLOs(writeSLOs(t, body)); err == nil {
+			t.Errorf("%s: accepted", name)
+		} else if !strings.Contains(err.Error(), "slo.yaml") {
+			t.Errorf("%s: error %q does not name the file", name, err)
+		}
+	}
+}
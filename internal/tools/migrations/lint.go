@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/internal/miglint"
+)
+
+// RegisterLint registers the migrations.lint tool, which flags dangerous
+// operations in the migration files under dir.
+func RegisterLint(s internal_mcp.ToolAdder, dir string) error {
+	if dir == "" {
+		return nil // Tool not registered if no migrations dir
+	}
+
+	tool := mcp.NewTool(
+		"migrations.lint",
+		mcp.WithDescription("Lint SQL migration files for unsafe operations: blocking index builds, NOT NULL columns without defaults, drops, renames, table rewrites and missing down migrations."),
+		mcp.WithString("min_severity", mcp.Enum(miglint.SeverityInfo, miglint.SeverityWarning, miglint.SeverityError), mcp.Description("Only report findings at or above this severity (default: info)")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		findings, err := miglint.LintDir(dir)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to lint migrations", map[string]any{"error": err.Error()}), nil
+		}
+		findings = miglint.AtLeast(findings, request.GetString("min_severity", miglint.SeverityInfo))
+		miglint.Sort(findings)
+		if findings == nil {
+			findings = []miglint.Finding{}
+		}
+
+		summary := miglint.Summary(findings)
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"dir":      dir,
+			"safe":     summary[miglint.SeverityError] == 0,
+			"summary":  summary,
+			"findings": findings,
+		})
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register migrations.lint: %w", err)
+	}
+	return nil
+}
@@ -27,19 +27,39 @@ func Register(s internal_mcp.ToolAdder, mr types.MigrationReader) error {
 
 		pending := 0
 		applied := 0
+		dirty := 0
+		mismatched := 0
+		outOfOrder := 0
+		missing := 0
 		for _, st := range statuses {
 			if st.Applied {
 				applied++
 			} else {
 				pending++
 			}
+			if st.Dirty {
+				dirty++
+			}
+			if st.ChecksumMismatch {
+				mismatched++
+			}
+			if st.OutOfOrder {
+				outOfOrder++
+			}
+			if st.Missing {
+				missing++
+			}
 		}
 
 		result := map[string]any{
-			"total":      len(statuses),
-			"applied":    applied,
-			"pending":    pending,
-			"migrations": statuses,
+			"total":               len(statuses),
+			"applied":             applied,
+			"pending":             pending,
+			"dirty":               dirty,
+			"checksum_mismatches": mismatched,
+			"out_of_order":        outOfOrder,
+			"missing_files":       missing,
+			"migrations":          statuses,
 		}
 
 		return internal_mcp.NewToolResultJSON(result)
@@ -0,0 +1,181 @@
+package queues
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Queue statuses.
+const (
+	StatusOK       = "ok"
+	StatusWarn     = "warn"
+	StatusCritical = "critical"
+)
+
+const (
+	defaultMaxLag = 1000
+	defaultMaxAge = 5 * time.Minute
+)
+
+// Register registers the queues.status tool.
+func Register(s internal_mcp.ToolAdder, qi types.QueueInspector) error {
+	if qi == nil {
+		return nil // Tool not registered if no queue inspector
+	}
+
+	tool := mcp.NewTool(
+		"queues.status",
+		mcp.WithDescription("Get broker queues and topics with depth, consumer count, lag per consumer group, dead letter queue depth and oldest message age, flagging queues that need attention."),
+		mcp.WithString("queue", mcp.Description("Comma-separated queue names or glob patterns such as orders.*")),
+		mcp.WithNumber("max_lag", mcp.Description("Consumer group lag above which a queue is flagged (default 1000)")),
+		mcp.WithString("max_age", mcp.Description("Oldest message age above which a queue is flagged (default 5m)")),
+		mcp.WithBoolean("problems_only", mcp.Description("Only return queues with issues")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		maxLag := int64(mcp.ParseFloat64(request, "max_lag", defaultMaxLag))
+		maxAge := defaultMaxAge
+		if v := request.GetString("max_age", ""); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid max_age", map[string]any{"max_age": v}), nil
+			}
+			maxAge = d
+		}
+		patterns := argparse.List(request.GetString("queue", ""))
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid queue pattern", map[string]any{"queue": p, "error": err.Error()}), nil
+			}
+		}
+		problemsOnly := request.GetBool("problems_only", false)
+
+		list, err := qi.Queues(ctx)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to inspect queues", map[string]any{"error": err.Error()}), nil
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		now := time.Now()
+		overall := StatusOK
+		queues := []map[string]any{}
+		var depth, deadLetters, lag int64
+		for _, q := range list {
+			if !matches(patterns, q.Name) {
+				continue
+			}
+			status, issues := assess(q, maxLag, maxAge, now)
+			overall = worse(overall, status)
+			depth += q.Depth
+			deadLetters += q.DeadLetterDepth
+			for _, g := range q.ConsumerGroups {
+				lag = max(lag, g.Lag)
+			}
+			if problemsOnly && status == StatusOK {
+				continue
+			}
+			queues = append(queues, queueJSON(q, status, issues, now))
+		}
+
+		return internal_mcp.NewToolResultJSON(map[string]any{
+			"status":                  overall,
+			"queues":                  queues,
+			"count":                   len(queues),
+			"total_depth":             depth,
+			"total_dead_letter_depth": deadLetters,
+			"max_lag":                 lag,
+		})
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register queues.status: %w", err)
+	}
+	return nil
+}
+
+// assess returns the status of q and why it is not ok.
+func assess(q types.QueueStatus, maxLag int64, maxAge time.Duration, now time.Time) (string, []string) {
+	status := StatusOK
+	issues := []string{}
+	flag := func(s, format string, args ...any) {
+		status = worse(status, s)
+		issues = append(issues, fmt.Sprintf(format, args...))
+	}
+	if q.Depth > 0 && q.Consumers == 0 {
+		flag(StatusCritical, "%d messages and no consumers", q.Depth)
+	}
+	if q.DeadLetterDepth > 0 {
+		flag(StatusWarn, "%d messages in the dead letter queue", q.DeadLetterDepth)
+	}
+	for _, g := range q.ConsumerGroups {
+		switch {
+		case g.Lag > 0 && g.Consumers == 0:
+			flag(StatusCritical, "group %s has lag %d and no consumers", g.Name, g.Lag)
+		case g.Lag > maxLag:
+			flag(StatusWarn, "group %s lag %d exceeds %d", g.Name, g.Lag, maxLag)
+		}
+	}
+	if !q.OldestMessage.IsZero() {
+		if age := now.Sub(q.OldestMessage); age > maxAge {
+			flag(StatusWarn, "oldest message is %s old", age.Round(time.Second))
+		}
+	}
+	return status, issues
+}
+
+func queueJSON(q types.QueueStatus, status string, issues []string, now time.Time) map[string]any {
+	out := map[string]any{
+		"name":              q.Name,
+		"status":            status,
+		"issues":            issues,
+		"depth":             q.Depth,
+		"consumers":         q.Consumers,
+		"dead_letter_depth": q.DeadLetterDepth,
+	}
+	if q.Kind != "" {
+		out["kind"] = q.Kind
+	}
+	if q.DeadLetterQueue != "" {
+		out["dead_letter_queue"] = q.DeadLetterQueue
+	}
+	if len(q.ConsumerGroups) > 0 {
+		groups := make([]types.ConsumerGroup, len(q.ConsumerGroups))
+		copy(groups, q.ConsumerGroups)
+		sort.Slice(groups, func(i, j int) bool { return groups[i].Lag > groups[j].Lag })
+		out["consumer_groups"] = groups
+	}
+	if !q.OldestMessage.IsZero() {
+		out["oldest_message"] = q.OldestMessage.Format(time.RFC3339)
+		out["oldest_message_age"] = now.Sub(q.OldestMessage).Round(time.Second).String()
+	}
+	return out
+}
+
+var severity = map[string]int{StatusOK: 0, StatusWarn: 1, StatusCritical: 2}
+
+func worse(a, b string) string {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+func matches(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
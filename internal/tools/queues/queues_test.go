@@ -0,0 +1,87 @@
+package queues
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/adapters/memqueue"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func (m *mockToolAdder) call(t *testing.T, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "queues.status", Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestQueuesStatus(t *testing.T) {
+	in := memqueue.New(
+		types.QueueStatus{Name: "orders.created", Kind: "topic", Depth: 5000, Consumers: 2, DeadLetterQueue: "orders.created.dlq", DeadLetterDepth: 3,
+			ConsumerGroups: []types.ConsumerGroup{{Name: "billing", Consumers: 2, Lag: 10}, {Name: "shipping", Consumers: 0, Lag: 4990}}},
+		types.QueueStatus{Name: "orders.shipped", Depth: 10, Consumers: 1, OldestMessage: time.Now().Add(-10 * time.Minute)},
+		types.QueueStatus{Name: "emails", Depth: 0, Consumers: 1},
+	)
+	s := &mockToolAdder{}
+	if err := Register(s, in); err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.call(t, nil).StructuredContent.(map[string]any)
+	if out["status"] != StatusCritical || out["count"] != 3 || out["total_depth"] != int64(5010) || out["max_lag"] != int64(4990) {
+		t.Fatalf("out = %v", out)
+	}
+	queues := out["queues"].([]map[string]any)
+	if queues[0]["name"] != "emails" || queues[0]["status"] != StatusOK {
+		t.Errorf("emails = %v", queues[0])
+	}
+	created := queues[1]
+	issues := strings.Join(created["issues"].([]string), "; ")
+	if created["status"] != StatusCritical || !strings.Contains(issues, "group shipping has lag 4990 and no consumers") || !strings.Contains(issues, "3 messages in the dead letter queue") {
+		t.Errorf("orders.created = %v", created)
+	}
+	if groups := created["consumer_groups"].([]types.ConsumerGroup); groups[0].Name != "shipping" {
+		t.Errorf("groups not sorted by lag: %v", groups)
+	}
+	if shipped := queues[2]; shipped["status"] != StatusWarn || shipped["oldest_message_age"] == nil {
+		t.Errorf("orders.shipped = %v", shipped)
+	}
+
+	out = s.call(t, map[string]any{"queue": "orders.*", "max_age": "1h", "problems_only": true}).StructuredContent.(map[string]any)
+	if queues := out["queues"].([]map[string]any); len(queues) != 1 || queues[0]["name"] != "orders.created" {
+		t.Errorf("filtered = %v", queues)
+	}
+
+	if res := s.call(t, map[string]any{"max_age": "soon"}); !res.IsError {
+		t.Error("invalid max_age accepted")
+	}
+	in.SetError(errors.New("broker down"))
+	if res := s.call(t, nil); !res.IsError {
+		t.Error("inspector error not reported")
+	}
+
+	none := &mockToolAdder{}
+	if err := Register(none, nil); err != nil || none.handler != nil {
+		t.Errorf("nil inspector: err = %v, registered = %v", err, none.handler != nil)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// Diagram formats.
+const (
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+)
+
+// load returns the typed topology, from types.TopologyGrapher when tp
+// implements it and otherwise decoded from the snapshot's "nodes" and
+// "edges". snapshot is the legacy map and is nil for graphers; topo is nil
+// when the snapshot holds no graph.
+func load(ctx context.Context, tp types.TopologyProvider) (topo *types.Topology, snapshot map[string]any, err error) {
+	if g, ok := tp.(types.TopologyGrapher); ok {
+		topo, err = g.Topology(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if topo == nil {
+			topo = &types.Topology{}
+		}
+		return normalize(topo), nil, nil
+	}
+	snapshot, err = tp.Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := snapshot["nodes"]; !ok {
+		if _, ok := snapshot["edges"]; !ok {
+			return nil, snapshot, nil
+		}
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, snapshot, nil
+	}
+	var t types.Topology
+	if json.Unmarshal(b, &t) != nil || (len(t.Nodes) == 0 && len(t.Edges) == 0) {
+		return nil, snapshot, nil
+	}
+	return normalize(&t), snapshot, nil
+}
+
+// normalize returns a copy of t with nodes for every edge endpoint, derived
+// edge directions and a stable order.
+func normalize(t *types.Topology) *types.Topology {
+	out := &types.Topology{Service: t.Service}
+	seen := map[string]bool{}
+	for _, n := range t.Nodes {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, e := range t.Edges {
+		for _, id := range []string{e.From, e.To} {
+			if !seen[id] {
+				seen[id] = true
+				out.Nodes = append(out.Nodes, types.TopologyNode{ID: id})
+			}
+		}
+		if e.Direction == "" && out.Service != "" {
+			switch out.Service {
+			case e.To:
+				e.Direction = types.TopologyUpstream
+			case e.From:
+				e.Direction = types.TopologyDownstream
+			}
+		}
+		out.Edges = append(out.Edges, e)
+	}
+	sort.SliceStable(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	sort.SliceStable(out.Edges, func(i, j int) bool {
+		if out.Edges[i].From != out.Edges[j].From {
+			return out.Edges[i].From < out.Edges[j].From
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	if out.Nodes == nil {
+		out.Nodes = []types.TopologyNode{}
+	}
+	if out.Edges == nil {
+		out.Edges = []types.TopologyEdge{}
+	}
+	return out
+}
+
+// neighbours lists the callers (upstream) or dependencies (downstream) of
+// the service with the protocol of the connecting edge.
+func neighbours(t *types.Topology, direction string) []map[string]any {
+	nodes := make(map[string]types.TopologyNode, len(t.Nodes))
+	for _, n := range t.Nodes {
+		nodes[n.ID] = n
+	}
+	out := []map[string]any{}
+	for _, e := range t.Edges {
+		if e.Direction != direction {
+			continue
+		}
+		id := e.To
+		if direction == types.TopologyUpstream {
+			id = e.From
+		}
+		n := nodes[id]
+		entry := map[string]any{"id": id}
+		for k, v := range map[string]string{"name": n.Name, "kind": n.Kind, "health": n.Health, "protocol": e.Protocol} {
+			if v != "" {
+				entry[k] = v
+			}
+		}
+		if e.Async {
+			entry["async"] = true
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// Render renders t in the given format.
+func Render(t *types.Topology, format string) (string, error) {
+	switch format {
+	case FormatMermaid, "":
+		return Mermaid(t), nil
+	case FormatDOT:
+		return DOT(t), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format %q (want %s or %s)", format, FormatMermaid, FormatDOT)
+	}
+}
+
+var nonWordRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Mermaid renders a left-to-right Mermaid flowchart. Node shapes follow the
+// kind, unhealthy nodes are coloured and async edges are dotted.
+func Mermaid(t *types.Topology) string {
+	ids := mermaidIDs(t.Nodes)
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range t.Nodes {
+		label := strings.ReplaceAll(nodeLabel(n, "<br/>"), `"`, "#quot;")
+		open, closing := "[", "]"
+		switch n.Kind {
+		case types.TopologyNodeDatabase, types.TopologyNodeCache:
+			open, closing = "[(", ")]"
+		case types.TopologyNodeQueue:
+			open, closing = "[[", "]]"
+		case types.TopologyNodeExternal:
+			open, closing = "([", "])"
+		}
+		fmt.Fprintf(&b, "    %s%s\"%s\"%s", ids[n.ID], open, label, closing)
+		switch {
+		case n.ID == t.Service:
+			b.WriteString(":::self")
+		case n.Health == types.HealthStatusDegraded || n.Health == types.HealthStatusDown:
+			b.WriteString(":::" + n.Health)
+		}
+		b.WriteString("\n")
+	}
+	for _, e := range t.Edges {
+		arrow := "-->"
+		if e.Async {
+			arrow = "-.->"
+		}
+		if e.Protocol != "" {
+			fmt.Fprintf(&b, "    %s %s|\"%s\"| %s\n", ids[e.From], arrow, strings.ReplaceAll(e.Protocol, `"`, "#quot;"), ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "    %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		}
+	}
+	b.WriteString("    classDef self stroke-width:3px\n")
+	b.WriteString("    classDef degraded fill:#fff3cd,stroke:#d39e00\n")
+	b.WriteString("    classDef down fill:#f8d7da,stroke:#c82333\n")
+	return b.String()
+}
+
+// DOT renders a Graphviz digraph.
+func DOT(t *types.Topology) string {
+	var b strings.Builder
+	b.WriteString("digraph topology {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range t.Nodes {
+		attrs := []string{fmt.Sprintf("label=%q", nodeLabel(n, "\n"))}
+		switch n.Kind {
+		case types.TopologyNodeDatabase, types.TopologyNodeCache:
+			attrs = append(attrs, "shape=cylinder")
+		case types.TopologyNodeQueue:
+			attrs = append(attrs, "shape=box3d")
+		case types.TopologyNodeExternal:
+			attrs = append(attrs, "shape=box", "style=rounded")
+		}
+		switch n.Health {
+		case types.HealthStatusDegraded:
+			attrs = append(attrs, "color=orange")
+		case types.HealthStatusDown:
+			attrs = append(attrs, "color=red")
+		}
+		if n.ID == t.Service {
+			attrs = append(attrs, "penwidth=2")
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", n.ID, strings.Join(attrs, ", "))
+	}
+	for _, e := range t.Edges {
+		var attrs []string
+		if e.Protocol != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", e.Protocol))
+		}
+		if e.Async {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nodeLabel is the name, then kind, version and health joined by sep.
+func nodeLabel(n types.TopologyNode, sep string) string {
+	name := n.Name
+	if name == "" {
+		name = n.ID
+	}
+	var detail []string
+	for _, s := range []string{n.Kind, n.Version} {
+		if s != "" {
+			detail = append(detail, s)
+		}
+	}
+	if n.Health != "" {
+		detail = append(detail, "("+n.Health+")")
+	}
+	if len(detail) == 0 {
+		return name
+	}
+	return name + sep + strings.Join(detail, " ")
+}
+
+// mermaidIDs maps node IDs to unique Mermaid identifiers.
+func mermaidIDs(nodes []types.TopologyNode) map[string]string {
+	out := make(map[string]string, len(nodes))
+	used := map[string]bool{}
+	for _, n := range nodes {
+		id := mermaidIdent(n.ID)
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", mermaidIdent(n.ID), i)
+		}
+		used[id] = true
+		out[n.ID] = id
+	}
+	return out
+}
+
+// mermaidIdent replaces characters Mermaid does not allow in node IDs. The
+// keyword "end" would close the flowchart, so it is suffixed.
+func mermaidIdent(s string) string {
+	s = nonWordRe.ReplaceAllString(s, "_")
+	if s == "" || strings.EqualFold(s, "end") {
+		return s + "_"
+	}
+	return s
+}
@@ -2,13 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
 	"github.com/next-trace/scg-boost/types"
 )
 
-// Register registers the service.topology tool.
+// Topology resource URIs.
+const (
+	TopologyResourceURI    = "scg://service/topology.mmd"
+	TopologyDOTResourceURI = "scg://service/topology.dot"
+)
+
+// errNoGraph is returned when diagrams are requested from a provider whose
+// snapshot holds no nodes or edges.
+var errNoGraph = errors.New("topology provider returned no nodes or edges; implement types.TopologyGrapher or return nodes and edges from Snapshot")
+
+// Register registers the service.topology tool and the
+// scg://service/topology.mmd (Mermaid) and scg://service/topology.dot
+// (Graphviz) resources.
 func Register(s internal_mcp.ToolAdder, tp types.TopologyProvider) error {
 	if tp == nil {
 		return nil // Tool not registered if no topology provider
@@ -16,16 +30,73 @@ func Register(s internal_mcp.ToolAdder, tp types.TopologyProvider) error {
 
 	tool := mcp.NewTool(
 		"service.topology",
-		mcp.WithDescription("Get a snapshot of the service topology."),
+		mcp.WithDescription("Get a snapshot of the service topology: nodes with kind, version and health, edges with protocol and direction, and the upstream callers and downstream dependencies of the service. Optionally render it as a Mermaid or Graphviz DOT diagram."),
+		mcp.WithString("format", mcp.Enum("json", FormatMermaid, FormatDOT), mcp.Description("Also return a diagram in this format (default: json only)")),
 	)
 
 	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		topology, err := tp.Snapshot(ctx)
+		format := request.GetString("format", "json")
+		topo, snapshot, err := load(ctx, tp)
 		if err != nil {
-			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to get topology", nil), nil
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to get topology", map[string]any{"error": err.Error()}), nil
+		}
+
+		// Snapshot keys are kept so existing consumers see the same fields.
+		result := make(map[string]any, len(snapshot)+4)
+		for k, v := range snapshot {
+			result[k] = v
+		}
+		if topo == nil {
+			if format != "json" {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, errNoGraph.Error(), nil), nil
+			}
+			return internal_mcp.NewToolResultJSON(result)
 		}
-		return internal_mcp.NewToolResultJSON(topology)
+		result["topology"] = topo
+		result["upstream"] = neighbours(topo, types.TopologyUpstream)
+		result["downstream"] = neighbours(topo, types.TopologyDownstream)
+		if format != "json" {
+			diagram, err := Render(topo, format)
+			if err != nil {
+				return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, err.Error(), nil), nil
+			}
+			result["format"] = format
+			result["diagram"] = diagram
+		}
+		return internal_mcp.NewToolResultJSON(result)
+	}
+
+	if err := s.AddTool(tool, handler); err != nil {
+		return fmt.Errorf("register service.topology: %w", err)
 	}
 
-	return s.AddTool(tool, handler)
+	resources := []struct {
+		uri, format, mime string
+	}{
+		{TopologyResourceURI, FormatMermaid, "text/vnd.mermaid"},
+		{TopologyDOTResourceURI, FormatDOT, "text/vnd.graphviz"},
+	}
+	for _, r := range resources {
+		res := mcp.NewResource(r.uri, r.uri,
+			mcp.WithResourceDescription("Service topology with upstream and downstream dependencies ("+r.format+")"),
+			mcp.WithMIMEType(r.mime),
+		)
+		if err := s.AddResource(res, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			topo, _, err := load(ctx, tp)
+			if err != nil {
+				return nil, err
+			}
+			if topo == nil {
+				return nil, errNoGraph
+			}
+			diagram, err := Render(topo, r.format)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: r.mime, Text: diagram}}, nil
+		}); err != nil {
+			return fmt.Errorf("register %s: %w", r.uri, err)
+		}
+	}
+	return nil
 }
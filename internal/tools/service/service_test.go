@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handler   internal_mcp.ToolHandler
+	resources map[string]internal_mcp.ResourceHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	m.handler = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	if m.resources == nil {
+		m.resources = map[string]internal_mcp.ResourceHandler{}
+	}
+	m.resources[resource.URI] = handler
+	return nil
+}
+
+func (m *mockToolAdder) call(t *testing.T, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "service.topology", Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func (m *mockToolAdder) read(t *testing.T, uri string) (string, error) {
+	t.Helper()
+	contents, err := m.resources[uri](context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		return "", err
+	}
+	return contents[0].(mcp.TextResourceContents).Text, nil
+}
+
+type snapshot struct {
+	m   map[string]any
+	err error
+}
+
+func (s snapshot) Snapshot(context.Context) (map[string]any, error) { return s.m, s.err }
+
+type grapher struct {
+	snapshot
+	topo *types.Topology
+}
+
+func (g grapher) Topology(context.Context) (*types.Topology, error) { return g.topo, nil }
+
+func testTopology() *types.Topology {
+	return &types.Topology{
+		Service: "orders",
+		Nodes: []types.TopologyNode{
+			{ID: "orders", Name: "orders-api", Kind: types.TopologyNodeService, Version: "1.4.0", Health: types.HealthStatusUp},
+			{ID: "gateway", Kind: types.TopologyNodeService},
+			{ID: "orders-db", Kind: types.TopologyNodeDatabase, Health: types.HealthStatusDegraded},
+			{ID: "events", Kind: types.TopologyNodeQueue},
+		},
+		Edges: []types.TopologyEdge{
+			{From: "gateway", To: "orders", Protocol: "http"},
+			{From: "orders", To: "orders-db", Protocol: "sql"},
+			{From: "orders", To: "events", Protocol: "kafka", Async: true},
+			{From: "orders", To: "stripe", Protocol: "https", Direction: types.TopologyDownstream},
+		},
+	}
+}
+
+func TestTopology_Typed(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, grapher{topo: testTopology()}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.call(t, map[string]any{"format": FormatMermaid}).StructuredContent.(map[string]any)
+	topo := out["topology"].(*types.Topology)
+	if len(topo.Nodes) != 5 || topo.Nodes[4].ID != "stripe" {
+		t.Errorf("nodes = %+v, want the edge-only stripe node added", topo.Nodes)
+	}
+	up := out["upstream"].([]map[string]any)
+	down := out["downstream"].([]map[string]any)
+	if len(up) != 1 || up[0]["id"] != "gateway" || up[0]["protocol"] != "http" {
+		t.Errorf("upstream = %v", up)
+	}
+	if len(down) != 3 || down[0]["id"] != "events" || down[0]["async"] != true || down[1]["health"] != types.HealthStatusDegraded {
+		t.Errorf("downstream = %v", down)
+	}
+	diagram := out["diagram"].(string)
+	for _, want := range []string{
+		"flowchart LR",
+		`orders["orders-api<br/>service 1.4.0 (up)"]:::self`,
+		`orders_db[("orders-db<br/>database (degraded)")]:::degraded`,
+		`events[["events<br/>queue"]]`,
+		`orders -.->|"kafka"| events`,
+		`gateway -->|"http"| orders`,
+	} {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("mermaid missing %q:\n%s", want, diagram)
+		}
+	}
+
+	dot, err := s.read(t, TopologyDOTResourceURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"orders-db" [label="orders-db\ndatabase (degraded)", shape=cylinder, color=orange];`, `"orders" -> "events" [label="kafka", style=dashed];`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot missing %q:\n%s", want, dot)
+		}
+	}
+	if mmd, err := s.read(t, TopologyResourceURI); err != nil || mmd != diagram {
+		t.Errorf("mermaid resource differs from the tool diagram: %v", err)
+	}
+}
+
+func TestTopology_Snapshot(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, snapshot{m: map[string]any{"name": "orders"}}); err != nil {
+		t.Fatal(err)
+	}
+	out := s.call(t, nil).StructuredContent.(map[string]any)
+	if out["name"] != "orders" || out["topology"] != nil {
+		t.Errorf("untyped snapshot = %v", out)
+	}
+	if res := s.call(t, map[string]any{"format": FormatDOT}); !res.IsError {
+		t.Error("diagram rendered without a graph")
+	}
+	if _, err := s.read(t, TopologyResourceURI); err == nil {
+		t.Error("resource rendered without a graph")
+	}
+
+	// Snapshots shaped like types.Topology are decoded.
+	s = &mockToolAdder{}
+	m := map[string]any{
+		"service": "orders",
+		"nodes":   []any{map[string]any{"id": "orders", "kind": "service"}},
+		"edges":   []any{map[string]any{"from": "orders", "to": "end", "protocol": "grpc"}},
+	}
+	if err := Register(s, snapshot{m: m}); err != nil {
+		t.Fatal(err)
+	}
+	out = s.call(t, nil).StructuredContent.(map[string]any)
+	if down := out["downstream"].([]map[string]any); len(down) != 1 || down[0]["id"] != "end" {
+		t.Errorf("downstream = %v", down)
+	}
+	if mmd, _ := s.read(t, TopologyResourceURI); !strings.Contains(mmd, `orders -->|"grpc"| end_`) {
+		t.Errorf("mermaid = %s", mmd)
+	}
+}
+
+func TestTopology_Error(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := Register(s, snapshot{err: errors.New("registry unreachable")}); err != nil {
+		t.Fatal(err)
+	}
+	res := s.call(t, nil)
+	if !res.IsError || res.Content[0].(mcp.TextContent).Text != "failed to get topology" {
+		t.Errorf("provider error = %v, want the fixed message", res.Content)
+	}
+
+	none := &mockToolAdder{}
+	if err := Register(none, nil); err != nil || none.handler != nil {
+		t.Errorf("nil provider: err = %v, registered = %v", err, none.handler != nil)
+	}
+}
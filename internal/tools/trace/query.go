@@ -0,0 +1,172 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/next-trace/scg-boost/internal/argparse"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 200
+)
+
+// RegisterQuery registers the trace.get and trace.search tools.
+func RegisterQuery(s internal_mcp.ToolAdder, tq types.TraceQuerier) error {
+	if tq == nil {
+		return nil // Tools not registered if no trace querier
+	}
+
+	getTool := mcp.NewTool(
+		"trace.get",
+		mcp.WithDescription("Get a trace by ID as a span tree with timings, status, attributes and events. Spans on the critical path (the chain that determines the trace's end) are marked."),
+		mcp.WithString("trace_id", mcp.Required(), mcp.Description("Trace ID")),
+	)
+	getHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := strings.TrimSpace(request.GetString("trace_id", ""))
+		if id == "" {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "missing trace_id", nil), nil
+		}
+		t, err := tq.GetTrace(ctx, id)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to get trace", map[string]any{"error": err.Error()}), nil
+		}
+		if t == nil || len(t.Spans) == 0 {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "trace not found", map[string]any{"trace_id": id}), nil
+		}
+		return internal_mcp.NewToolResultJSON(traceResult(t))
+	}
+	if err := s.AddTool(getTool, getHandler); err != nil {
+		return fmt.Errorf("register trace.get: %w", err)
+	}
+
+	searchTool := mcp.NewTool(
+		"trace.search",
+		mcp.WithDescription("Search recent traces by service, operation, minimum duration or error status, most recent first."),
+		mcp.WithString("service", mcp.Description("Service with a span in the trace")),
+		mcp.WithString("operation", mcp.Description("Span name, e.g. \"GET /orders\"")),
+		mcp.WithString("min_duration", mcp.Description("Minimum trace duration, e.g. \"250ms\" (a bare number is milliseconds)")),
+		mcp.WithBoolean("error", mcp.Description("Only traces with an error span")),
+		mcp.WithString("since", mcp.Description("Only traces starting at or after this time: RFC 3339 or a duration ago such as \"15m\"")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum traces (default %d, max %d)", defaultSearchLimit, maxSearchLimit))),
+	)
+	searchHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := types.TraceQuery{
+			Service:    strings.TrimSpace(request.GetString("service", "")),
+			Operation:  strings.TrimSpace(request.GetString("operation", "")),
+			ErrorsOnly: request.GetBool("error", false),
+		}
+		var err error
+		if q.MinDuration, err = parseDuration(request.GetString("min_duration", "")); err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid min_duration", map[string]any{"error": err.Error()}), nil
+		}
+		if q.Since, err = argparse.Time(request.GetString("since", ""), time.Now()); err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInvalidInput, "invalid since", map[string]any{"error": err.Error()}), nil
+		}
+		q.Limit = int(mcp.ParseFloat64(request, "limit", defaultSearchLimit))
+		if q.Limit <= 0 {
+			q.Limit = defaultSearchLimit
+		}
+		q.Limit = min(q.Limit, maxSearchLimit)
+
+		sums, err := tq.SearchTraces(ctx, q)
+		if err != nil {
+			return internal_mcp.ToolError(internal_mcp.ErrCodeInternal, "failed to search traces", map[string]any{"error": err.Error()}), nil
+		}
+		if len(sums) > q.Limit {
+			sums = sums[:q.Limit]
+		}
+		out := make([]map[string]any, len(sums))
+		for i, sum := range sums {
+			out[i] = map[string]any{
+				"trace_id":     sum.TraceID,
+				"root_service": sum.RootService,
+				"root_name":    sum.RootName,
+				"start":        sum.Start.Format(time.RFC3339Nano),
+				"duration_ms":  millis(sum.Duration),
+				"span_count":   sum.SpanCount,
+				"error_count":  sum.ErrorCount,
+				"services":     sum.Services,
+			}
+		}
+		return internal_mcp.NewToolResultJSON(map[string]any{"traces": out, "count": len(out)})
+	}
+	if err := s.AddTool(searchTool, searchHandler); err != nil {
+		return fmt.Errorf("register trace.search: %w", err)
+	}
+	return nil
+}
+
+// traceResult renders t as a span tree, its critical path and a text view.
+func traceResult(t *types.Trace) map[string]any {
+	sum := t.Summary()
+	roots := buildTree(t.Spans)
+	path := criticalPath(roots)
+
+	budget := maxTreeSpans
+	var tree []map[string]any
+	for _, r := range roots {
+		if budget <= 0 {
+			break
+		}
+		tree = append(tree, renderNode(r, sum.Start, &budget))
+	}
+
+	var text strings.Builder
+	budget = maxTreeSpans
+	for _, r := range roots {
+		if budget <= 0 {
+			break
+		}
+		renderText(&text, r, sum.Start, "", "", &budget)
+	}
+
+	critical := make([]map[string]any, len(path))
+	for i, n := range path {
+		self := n.span.Duration
+		if i+1 < len(path) {
+			self -= path[i+1].span.Duration
+		}
+		critical[i] = map[string]any{
+			"span_id":     n.span.SpanID,
+			"service":     n.span.Service,
+			"name":        n.span.Name,
+			"duration_ms": millis(n.span.Duration),
+			"self_ms":     millis(max(self, 0)),
+		}
+	}
+
+	return map[string]any{
+		"trace_id":      t.TraceID,
+		"root_service":  sum.RootService,
+		"root_name":     sum.RootName,
+		"start":         sum.Start.Format(time.RFC3339Nano),
+		"duration_ms":   millis(sum.Duration),
+		"span_count":    sum.SpanCount,
+		"error_count":   sum.ErrorCount,
+		"services":      sum.Services,
+		"truncated":     sum.SpanCount > maxTreeSpans,
+		"spans":         tree,
+		"critical_path": critical,
+		"tree":          text.String(),
+	}
+}
+
+// parseDuration accepts Go durations ("250ms") or bare milliseconds.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if ms, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(ms * float64(time.Millisecond)), nil
+	}
+	return time.ParseDuration(s)
+}
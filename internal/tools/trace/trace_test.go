@@ -0,0 +1,162 @@
+package trace
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	internal_mcp "github.com/next-trace/scg-boost/internal/mcp"
+	"github.com/next-trace/scg-boost/types"
+)
+
+type mockToolAdder struct {
+	handlers map[string]internal_mcp.ToolHandler
+}
+
+func (m *mockToolAdder) AddTool(tool mcp.Tool, handler internal_mcp.ToolHandler) error {
+	if m.handlers == nil {
+		m.handlers = make(map[string]internal_mcp.ToolHandler)
+	}
+	m.handlers[tool.Name] = handler
+	return nil
+}
+
+func (m *mockToolAdder) AddResource(resource mcp.Resource, handler internal_mcp.ResourceHandler) error {
+	return nil
+}
+
+func (m *mockToolAdder) call(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := m.handlers[name](context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatalf("%s: handler error = %v", name, err)
+	}
+	return res
+}
+
+type memTraces struct {
+	traces []types.Trace
+	last   types.TraceQuery
+}
+
+func (m *memTraces) GetTrace(ctx context.Context, id string) (*types.Trace, error) {
+	for i := range m.traces {
+		if m.traces[i].TraceID == id {
+			return &m.traces[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memTraces) SearchTraces(ctx context.Context, q types.TraceQuery) ([]types.TraceSummary, error) {
+	m.last = q
+	var out []types.TraceSummary
+	for _, t := range m.traces {
+		if q.Match(t) {
+			out = append(out, t.Summary())
+		}
+	}
+	return out, nil
+}
+
+func testTrace() types.Trace {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	span := func(id, parent, svc, name string, startMs, durMs int, status string) types.Span {
+		return types.Span{
+			TraceID: "t1", SpanID: id, ParentSpanID: parent, Service: svc, Name: name,
+			Start: t0.Add(time.Duration(startMs) * time.Millisecond), Duration: time.Duration(durMs) * time.Millisecond,
+			Status: status,
+		}
+	}
+	spans := []types.Span{
+		span("a", "", "api", "GET /orders", 0, 120, types.SpanStatusOK),
+		span("b", "a", "api", "auth", 2, 10, types.SpanStatusOK),
+		span("c", "a", "orders", "ListOrders", 15, 100, types.SpanStatusError),
+		span("d", "c", "orders", "SELECT orders", 20, 90, types.SpanStatusError),
+		span("e", "c", "orders", "cache.get", 16, 3, types.SpanStatusOK),
+	}
+	spans[3].StatusMessage = "statement timeout"
+	spans[3].Events = []types.SpanEvent{{Name: "exception", Time: t0.Add(110 * time.Millisecond), Attributes: map[string]any{"exception.type": "pq.Error"}}}
+	return types.Trace{TraceID: "t1", Spans: spans}
+}
+
+func TestTraceGet(t *testing.T) {
+	s := &mockToolAdder{}
+	if err := RegisterQuery(s, &memTraces{traces: []types.Trace{testTrace()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.call(t, "trace.get", map[string]any{"trace_id": "t1"}).StructuredContent.(map[string]any)
+	if out["duration_ms"] != 120.0 || out["span_count"] != 5 || out["error_count"] != 2 || out["root_name"] != "GET /orders" {
+		t.Errorf("summary = %v", out)
+	}
+
+	var path []string
+	for _, c := range out["critical_path"].([]map[string]any) {
+		path = append(path, c["span_id"].(string))
+	}
+	if strings.Join(path, ",") != "a,c,d" {
+		t.Errorf("critical path = %v, want a,c,d", path)
+	}
+
+	root := out["spans"].([]map[string]any)[0]
+	children := root["children"].([]map[string]any)
+	if len(children) != 2 || children[0]["span_id"] != "b" || children[1]["critical"] != true {
+		t.Errorf("children = %v", children)
+	}
+	leaf := children[1]["children"].([]map[string]any)[1]
+	if leaf["span_id"] != "d" || leaf["offset_ms"] != 20.0 || leaf["events"].([]map[string]any)[0]["offset_ms"] != 110.0 {
+		t.Errorf("leaf = %v", leaf)
+	}
+
+	wantTree := `api: GET /orders +0ms 120ms *
+├─ api: auth +2ms 10ms
+└─ orders: ListOrders +15ms 100ms ERROR *
+   ├─ orders: cache.get +16ms 3ms
+   └─ orders: SELECT orders +20ms 90ms ERROR statement timeout *
+`
+	if out["tree"] != wantTree {
+		t.Errorf("tree =\n%s\nwant\n%s", out["tree"], wantTree)
+	}
+
+	if res := s.call(t, "trace.get", map[string]any{"trace_id": "nope"}); !res.IsError {
+		t.Error("unknown trace: want tool error")
+	}
+}
+
+func TestTraceSearch(t *testing.T) {
+	tr := testTrace()
+	fast := types.Trace{TraceID: "t2", Spans: []types.Span{{TraceID: "t2", SpanID: "x", Service: "api", Name: "GET /health", Start: tr.Spans[0].Start, Duration: time.Millisecond, Status: types.SpanStatusOK}}}
+	q := &memTraces{traces: []types.Trace{tr, fast}}
+	s := &mockToolAdder{}
+	if err := RegisterQuery(s, q); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"service": "api"}, "t1,t2"},
+		{map[string]any{"service": "orders", "operation": "select orders"}, "t1"},
+		{map[string]any{"min_duration": "100ms"}, "t1"},
+		{map[string]any{"min_duration": "200"}, ""},
+		{map[string]any{"error": true}, "t1"},
+	}
+	for _, tt := range tests {
+		out := s.call(t, "trace.search", tt.args).StructuredContent.(map[string]any)
+		var ids []string
+		for _, tr := range out["traces"].([]map[string]any) {
+			ids = append(ids, tr["trace_id"].(string))
+		}
+		if strings.Join(ids, ",") != tt.want {
+			t.Errorf("search %v = %v, want %s", tt.args, ids, tt.want)
+		}
+	}
+
+	if res := s.call(t, "trace.search", map[string]any{"min_duration": "soon"}); !res.IsError {
+		t.Error("invalid min_duration: want tool error")
+	}
+}
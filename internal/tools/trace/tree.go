@@ -0,0 +1,161 @@
+package trace
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-boost/types"
+)
+
+// maxTreeSpans bounds the spans rendered by trace.get.
+const maxTreeSpans = 1000
+
+type node struct {
+	span     *types.Span
+	children []*node
+	critical bool
+}
+
+// buildTree links spans to their parents. Spans whose parent is missing
+// become roots. Roots and children are ordered by start time.
+func buildTree(spans []types.Span) []*node {
+	nodes := make(map[string]*node, len(spans))
+	for i := range spans {
+		nodes[spans[i].SpanID] = &node{span: &spans[i]}
+	}
+	var roots []*node
+	for i := range spans {
+		n := nodes[spans[i].SpanID]
+		if p, ok := nodes[spans[i].ParentSpanID]; ok && spans[i].ParentSpanID != "" && p != n {
+			p.children = append(p.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	byStart := func(ns []*node) {
+		sort.SliceStable(ns, func(i, j int) bool { return ns[i].span.Start.Before(ns[j].span.Start) })
+	}
+	byStart(roots)
+	for _, n := range nodes {
+		byStart(n.children)
+	}
+	return roots
+}
+
+// criticalPath marks and returns the chain of spans that determines the
+// trace's end: starting from the longest root, it repeatedly follows the
+// child that finishes last.
+func criticalPath(roots []*node) []*node {
+	var cur *node
+	for _, r := range roots {
+		if cur == nil || r.span.End().After(cur.span.End()) {
+			cur = r
+		}
+	}
+	var path []*node
+	for cur != nil {
+		cur.critical = true
+		path = append(path, cur)
+		var next *node
+		for _, c := range cur.children {
+			if !c.critical && (next == nil || c.span.End().After(next.span.End())) {
+				next = c
+			}
+		}
+		cur = next
+	}
+	return path
+}
+
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+// renderNode converts n and its children to the tool's JSON shape. budget
+// counts down the remaining spans to include.
+func renderNode(n *node, start time.Time, budget *int) map[string]any {
+	*budget--
+	sp := n.span
+	out := map[string]any{
+		"span_id":     sp.SpanID,
+		"service":     sp.Service,
+		"name":        sp.Name,
+		"offset_ms":   millis(sp.Start.Sub(start)),
+		"duration_ms": millis(sp.Duration),
+		"status":      sp.Status,
+	}
+	if sp.Kind != "" {
+		out["kind"] = sp.Kind
+	}
+	if sp.StatusMessage != "" {
+		out["status_message"] = sp.StatusMessage
+	}
+	if n.critical {
+		out["critical"] = true
+	}
+	if len(sp.Attributes) > 0 {
+		out["attributes"] = sp.Attributes
+	}
+	if len(sp.Events) > 0 {
+		events := make([]map[string]any, len(sp.Events))
+		for i, e := range sp.Events {
+			ev := map[string]any{"name": e.Name, "offset_ms": millis(e.Time.Sub(start))}
+			if len(e.Attributes) > 0 {
+				ev["attributes"] = e.Attributes
+			}
+			events[i] = ev
+		}
+		out["events"] = events
+	}
+	var children []map[string]any
+	for _, c := range n.children {
+		if *budget <= 0 {
+			break
+		}
+		children = append(children, renderNode(c, start, budget))
+	}
+	if len(children) > 0 {
+		out["children"] = children
+	}
+	return out
+}
+
+// renderText draws the tree with box-drawing connectors. Critical path spans
+// are marked with "*".
+func renderText(b *strings.Builder, n *node, start time.Time, prefix, connector string, budget *int) {
+	*budget--
+	sp := n.span
+	fmt.Fprintf(b, "%s%s%s: %s +%gms %gms", prefix, connector, sp.Service, sp.Name, millis(sp.Start.Sub(start)), millis(sp.Duration))
+	if sp.Status == types.SpanStatusError {
+		b.WriteString(" ERROR")
+		if sp.StatusMessage != "" {
+			b.WriteString(" " + sp.StatusMessage)
+		}
+	}
+	if n.critical {
+		b.WriteString(" *")
+	}
+	b.WriteString("\n")
+
+	childPrefix := prefix
+	switch connector {
+	case "├─ ":
+		childPrefix += "│  "
+	case "└─ ":
+		childPrefix += "   "
+	}
+	for i, c := range n.children {
+		if *budget <= 0 {
+			fmt.Fprintf(b, "%s└─ … (truncated)\n", childPrefix)
+			return
+		}
+		conn := "├─ "
+		if i == len(n.children)-1 {
+			conn = "└─ "
+		}
+		renderText(b, c, start, childPrefix, conn, budget)
+	}
+}
@@ -143,10 +143,19 @@ fn_lint() {
   info "Lint passed ✓"
 }
 
+# fn_nested_modules lists adapter directories that are their own Go module,
+# so their framework dependencies stay out of the root go.mod.
+fn_nested_modules() {
+  find adapters -mindepth 2 -maxdepth 2 -name go.mod -exec dirname {} \; | sort
+}
+
 fn_vet() {
   section "Running go vet"
 
   go vet ./...
+  for mod in $(fn_nested_modules); do
+    (cd "${mod}" && go vet ./...)
+  done
 
   info "Vet passed ✓"
 }
@@ -156,6 +165,9 @@ fn_build() {
 
   info "Running go build..."
   go build ./...
+  for mod in $(fn_nested_modules); do
+    (cd "${mod}" && go build ./...)
+  done
 
   info "Build successful ✓"
 }
@@ -191,6 +203,9 @@ fn_test() {
 
   info "Running tests with race detector..."
   go test -race -v ./...
+  for mod in $(fn_nested_modules); do
+    (cd "${mod}" && go test -race -v ./...)
+  done
 
   info "All tests passed ✓"
 }
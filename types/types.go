@@ -2,6 +2,11 @@ package types
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -37,6 +42,101 @@ type DBConn interface {
 	Columns(ctx context.Context, schema, table string) ([]map[string]any, error)
 }
 
+// IndexInfo describes a single table index.
+type IndexInfo struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	Unique     bool     `json:"unique"`
+	Definition string   `json:"definition,omitempty"`
+}
+
+// IndexLister is an optional DBConn extension that exposes table indexes.
+// Used by schema snapshots and drift detection; indexes are omitted when the
+// connection does not implement it.
+type IndexLister interface {
+	Indexes(ctx context.Context, schema, table string) ([]IndexInfo, error)
+}
+
+// ForeignKeyInfo describes a foreign key constraint. Columns and RefColumns
+// are listed in constraint order.
+type ForeignKeyInfo struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefSchema  string   `json:"ref_schema"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns"`
+}
+
+// ForeignKeyLister is an optional DBConn extension that exposes foreign keys.
+// Used by schema snapshots and ER diagrams; relationships are omitted when the
+// connection does not implement it.
+type ForeignKeyLister interface {
+	ForeignKeys(ctx context.Context, schema, table string) ([]ForeignKeyInfo, error)
+}
+
+// ColumnInfo describes a query result column.
+type ColumnInfo struct {
+	Name string `json:"name"`
+	// Type is the database type name, e.g. "INT8", "TEXT", "JSONB".
+	Type string `json:"type"`
+}
+
+// QueryResult is a query result with ordered columns. Each row holds one
+// value per column, in column order.
+type QueryResult struct {
+	Columns []ColumnInfo `json:"columns"`
+	Rows    [][]any      `json:"rows"`
+}
+
+// TypedQuerier is an optional DBConn extension that preserves column order and
+// types. Values are normalized to JSON-friendly forms: timestamps as RFC 3339
+// strings, numerics as json.Number, UUIDs and text as strings, JSON as decoded
+// values and binary data as "\x"-prefixed hex. dbquery.run uses it when
+// available and falls back to QueryJSON otherwise.
+type TypedQuerier interface {
+	QueryTyped(ctx context.Context, query string, params map[string]any) (*QueryResult, error)
+}
+
+// SessionVar configures a Postgres setting (e.g. "app.tenant_id") applied with
+// SET LOCAL before each DB tool call, typically read by row-level security
+// policies through current_setting().
+type SessionVar struct {
+	// Name is the setting name, e.g. "app.tenant_id".
+	Name string
+	// Arg, when set, is a tool argument that may supply the value if the
+	// SessionVarsProvider does not. Values from the provider always win.
+	Arg string
+	// Required refuses the call when no value is resolved.
+	Required bool
+}
+
+// SessionVarsProvider resolves session variables for the calling principal,
+// e.g. from authentication data the host stored in ctx.
+type SessionVarsProvider interface {
+	SessionVars(ctx context.Context) (map[string]string, error)
+}
+
+// SessionVarsApplier is implemented by DBConn adapters that apply the session
+// variables carried by ctx (see ContextWithSessionVars) with SET LOCAL. DB
+// tools are not registered when session variables are configured and the
+// DBConn does not implement it.
+type SessionVarsApplier interface {
+	AppliesSessionVars() bool
+}
+
+type sessionVarsKey struct{}
+
+// ContextWithSessionVars returns a copy of ctx carrying session variables.
+func ContextWithSessionVars(ctx context.Context, vars map[string]string) context.Context {
+	return context.WithValue(ctx, sessionVarsKey{}, vars)
+}
+
+// SessionVarsFromContext returns the session variables carried by ctx.
+func SessionVarsFromContext(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(sessionVarsKey{}).(map[string]string)
+	return vars
+}
+
 // Authorizer is an interface for checking if a tool can be executed.
 type Authorizer interface {
 	HasScope(ctx context.Context, tool string) bool
@@ -44,9 +144,10 @@ type Authorizer interface {
 
 // LogEntry represents a log entry.
 type LogEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Level     string    `json:"level"`
-	Message   string    `json:"message"`
+	Timestamp time.Time      `json:"timestamp"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Fields    map[string]any `json:"fields,omitempty"`
 }
 
 // LogReader is an interface for retrieving logs.
@@ -59,27 +160,427 @@ type LogStore interface {
 	LastError(ctx context.Context) (ts string, msg string, fields map[string]any, err error)
 }
 
+// LogQuery filters log entries. Zero values match everything.
+type LogQuery struct {
+	// Levels matches entries whose level is one of these (case-insensitive).
+	Levels []string
+	// Since and Until bound the entry timestamp (inclusive).
+	Since time.Time
+	Until time.Time
+	// Contains matches a case-insensitive substring of the message.
+	Contains string
+	// Pattern matches the message against a regular expression.
+	Pattern *regexp.Regexp
+	// Redact, when set, rewrites the message before Contains and Pattern
+	// are matched, so filters cannot probe values the caller is shown
+	// masked. Queriers that filter without Match must apply it too.
+	Redact func(msg string) string
+	// Fields matches entries whose fields equal these values, compared as
+	// strings (e.g. {"request_id": "abc"}).
+	Fields map[string]string
+	// AnyFields matches entries where at least one of these fields equals
+	// its value, e.g. an ID logged as "trace_id" or "traceId".
+	AnyFields map[string]string
+	// Limit caps the number of entries returned.
+	Limit int
+	// Cursor continues a previous search from its LogPage.NextCursor.
+	Cursor string
+}
+
+// Match reports whether e satisfies every filter except Limit and Cursor.
+func (q LogQuery) Match(e LogEntry) bool {
+	if len(q.Levels) > 0 {
+		ok := false
+		for _, l := range q.Levels {
+			if strings.EqualFold(l, e.Level) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Timestamp.After(q.Until) {
+		return false
+	}
+	msg := e.Message
+	if q.Redact != nil && (q.Contains != "" || q.Pattern != nil) {
+		msg = q.Redact(msg)
+	}
+	if q.Contains != "" && !strings.Contains(strings.ToLower(msg), strings.ToLower(q.Contains)) {
+		return false
+	}
+	if q.Pattern != nil && !q.Pattern.MatchString(msg) {
+		return false
+	}
+	for k, want := range q.Fields {
+		v, ok := e.Fields[k]
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	if len(q.AnyFields) > 0 {
+		for k, want := range q.AnyFields {
+			if v, ok := e.Fields[k]; ok && fmt.Sprint(v) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// LogPage is one page of log search results, newest first.
+type LogPage struct {
+	Entries []LogEntry
+	// NextCursor continues with older entries; empty when there are none.
+	NextCursor string
+}
+
+// LogQuerier searches structured logs. It is optional; when the configured
+// LogStore also implements it, logs.search and logs.tail are available.
+type LogQuerier interface {
+	// SearchLogs returns entries matching q, newest first, up to q.Limit.
+	SearchLogs(ctx context.Context, q LogQuery) (*LogPage, error)
+}
+
 // HealthProbe is an interface for checking the liveness and readiness of the service.
 type HealthProbe interface {
 	Liveness(ctx context.Context) error
 	Readiness(ctx context.Context) error
 }
 
+// Health check statuses.
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDown     = "down"
+)
+
+// HealthCheck is the state of one dependency or component, such as the
+// database, a cache, a broker or a downstream API.
+type HealthCheck struct {
+	Name string `json:"name"`
+	// Status is HealthStatusUp, HealthStatusDegraded or HealthStatusDown.
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"latency"`
+	Message string        `json:"message,omitempty"`
+	// LastChange is when Status last changed; zero when unknown.
+	LastChange time.Time      `json:"last_change"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// HealthChecker reports named component checks. It is optional and may be
+// provided alongside, or implemented by, the HealthProbe.
+type HealthChecker interface {
+	Checks(ctx context.Context) ([]HealthCheck, error)
+}
+
 // OutboxReader is an interface for peeking into an outbox of events.
 type OutboxReader interface {
 	Peek(ctx context.Context, limit int) ([]map[string]any, error)
 }
 
+// Outbox event statuses.
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusPublished  = "published"
+	OutboxStatusFailed     = "failed"
+	OutboxStatusDeadLetter = "dead_letter"
+)
+
+// OutboxEvent is one event in a transactional outbox.
+type OutboxEvent struct {
+	ID            string `json:"id"`
+	AggregateType string `json:"aggregate_type,omitempty"`
+	AggregateID   string `json:"aggregate_id,omitempty"`
+	Type          string `json:"type"`
+	// Status is one of the OutboxStatus* values.
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// LastError is the most recent publish failure.
+	LastError   string            `json:"last_error,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	PublishedAt time.Time         `json:"published_at,omitzero"`
+	Payload     any               `json:"payload,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// OutboxQuery filters outbox events. Zero fields match everything.
+type OutboxQuery struct {
+	Statuses    []string
+	Types       []string
+	AggregateID string
+	// Since and Until bound CreatedAt (inclusive).
+	Since time.Time
+	Until time.Time
+	Limit int
+	// Cursor continues a previous query from its OutboxPage.NextCursor.
+	Cursor string
+}
+
+// Match reports whether e satisfies every filter except Limit and Cursor.
+func (q OutboxQuery) Match(e OutboxEvent) bool {
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, e.Status) {
+		return false
+	}
+	if len(q.Types) > 0 && !slices.Contains(q.Types, e.Type) {
+		return false
+	}
+	if q.AggregateID != "" && e.AggregateID != q.AggregateID {
+		return false
+	}
+	if !q.Since.IsZero() && e.CreatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.CreatedAt.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// OutboxPage is one page of outbox events, newest first.
+type OutboxPage struct {
+	Events []OutboxEvent
+	// NextCursor continues with older events; empty when there are none.
+	NextCursor string
+}
+
+// OutboxStats summarizes the outbox. Published and Failed count events
+// created since the requested time.
+type OutboxStats struct {
+	Pending       int       `json:"pending"`
+	OldestPending time.Time `json:"oldest_pending,omitzero"`
+	Published     int       `json:"published"`
+	Failed        int       `json:"failed"`
+	DeadLetters   int       `json:"dead_letters"`
+}
+
+// OutboxQuerier queries typed outbox events. It is optional; when the
+// configured OutboxReader also implements it, events.outbox.peek accepts
+// filters and reports stats, and events.deadletter.peek is available.
+type OutboxQuerier interface {
+	// QueryOutbox returns events matching q, newest first, up to q.Limit.
+	QueryOutbox(ctx context.Context, q OutboxQuery) (*OutboxPage, error)
+	// OutboxStats returns counts for events created since the given time.
+	OutboxStats(ctx context.Context, since time.Time) (*OutboxStats, error)
+}
+
 // TraceReader is an interface for looking up recent traces.
 type TraceReader interface {
 	Lookup(ctx context.Context, lastN int) ([]map[string]any, error)
 }
 
+// Span status values.
+const (
+	SpanStatusUnset = "unset"
+	SpanStatusOK    = "ok"
+	SpanStatusError = "error"
+)
+
+// SpanEvent is a timestamped annotation on a span, such as an exception.
+type SpanEvent struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Span is one timed operation of a trace.
+type Span struct {
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Service       string         `json:"service"`
+	Name          string         `json:"name"`
+	Kind          string         `json:"kind,omitempty"`
+	Start         time.Time      `json:"start"`
+	Duration      time.Duration  `json:"duration"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Events        []SpanEvent    `json:"events,omitempty"`
+}
+
+// End returns the span's end time.
+func (s Span) End() time.Time { return s.Start.Add(s.Duration) }
+
+// Trace is all spans sharing a trace ID.
+type Trace struct {
+	TraceID string `json:"trace_id"`
+	Spans   []Span `json:"spans"`
+}
+
+// TraceSummary describes a trace without its spans.
+type TraceSummary struct {
+	TraceID     string        `json:"trace_id"`
+	RootService string        `json:"root_service"`
+	RootName    string        `json:"root_name"`
+	Start       time.Time     `json:"start"`
+	Duration    time.Duration `json:"duration"`
+	SpanCount   int           `json:"span_count"`
+	ErrorCount  int           `json:"error_count"`
+	Services    []string      `json:"services"`
+}
+
+// Summary computes the trace's summary. The root is the earliest span
+// without a parent in the trace; the duration spans all spans.
+func (t Trace) Summary() TraceSummary {
+	sum := TraceSummary{TraceID: t.TraceID, SpanCount: len(t.Spans)}
+	ids := make(map[string]bool, len(t.Spans))
+	for _, sp := range t.Spans {
+		ids[sp.SpanID] = true
+	}
+	services := make(map[string]bool)
+	var start, end time.Time
+	var root *Span
+	for i := range t.Spans {
+		sp := &t.Spans[i]
+		if start.IsZero() || sp.Start.Before(start) {
+			start = sp.Start
+		}
+		if sp.End().After(end) {
+			end = sp.End()
+		}
+		if sp.Status == SpanStatusError {
+			sum.ErrorCount++
+		}
+		if sp.Service != "" && !services[sp.Service] {
+			services[sp.Service] = true
+			sum.Services = append(sum.Services, sp.Service)
+		}
+		if (sp.ParentSpanID == "" || !ids[sp.ParentSpanID]) && (root == nil || sp.Start.Before(root.Start)) {
+			root = sp
+		}
+	}
+	sort.Strings(sum.Services)
+	sum.Start = start
+	sum.Duration = end.Sub(start)
+	if root != nil {
+		sum.RootService, sum.RootName = root.Service, root.Name
+	}
+	return sum
+}
+
+// TraceQuery filters traces. Zero values match everything.
+type TraceQuery struct {
+	// Service and Operation match traces with a span of that service and/or
+	// name (case-insensitive).
+	Service   string
+	Operation string
+	// MinDuration matches traces at least this long.
+	MinDuration time.Duration
+	// ErrorsOnly matches traces with at least one error span.
+	ErrorsOnly bool
+	// Since and Until bound the trace start time (inclusive).
+	Since time.Time
+	Until time.Time
+	// Limit caps the number of traces returned.
+	Limit int
+}
+
+// Match reports whether t satisfies every filter except Limit.
+func (q TraceQuery) Match(t Trace) bool {
+	sum := t.Summary()
+	if q.MinDuration > 0 && sum.Duration < q.MinDuration {
+		return false
+	}
+	if q.ErrorsOnly && sum.ErrorCount == 0 {
+		return false
+	}
+	if !q.Since.IsZero() && sum.Start.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && sum.Start.After(q.Until) {
+		return false
+	}
+	if q.Service == "" && q.Operation == "" {
+		return true
+	}
+	for _, sp := range t.Spans {
+		if (q.Service == "" || strings.EqualFold(sp.Service, q.Service)) &&
+			(q.Operation == "" || strings.EqualFold(sp.Name, q.Operation)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TraceQuerier looks up traces by ID and searches them. It is optional; when
+// the configured TraceReader also implements it, trace.get and trace.search
+// are available.
+type TraceQuerier interface {
+	// GetTrace returns the trace, or nil when it is unknown.
+	GetTrace(ctx context.Context, traceID string) (*Trace, error)
+	// SearchTraces returns matching traces, most recent first, up to q.Limit.
+	SearchTraces(ctx context.Context, q TraceQuery) ([]TraceSummary, error)
+}
+
 // TopologyProvider is an interface for getting a snapshot of the service topology.
 type TopologyProvider interface {
 	Snapshot(ctx context.Context) (map[string]any, error)
 }
 
+// Topology node kinds.
+const (
+	TopologyNodeService  = "service"
+	TopologyNodeDatabase = "database"
+	TopologyNodeCache    = "cache"
+	TopologyNodeQueue    = "queue"
+	TopologyNodeExternal = "external"
+)
+
+// Topology edge directions, relative to the described service.
+const (
+	// TopologyUpstream edges come from callers of the service.
+	TopologyUpstream = "upstream"
+	// TopologyDownstream edges go to dependencies of the service.
+	TopologyDownstream = "downstream"
+)
+
+// TopologyNode is a service or dependency in the topology graph.
+type TopologyNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Kind is one of the TopologyNode* values or a custom kind.
+	Kind    string `json:"kind"`
+	Version string `json:"version,omitempty"`
+	// Health is HealthStatusUp, HealthStatusDegraded, HealthStatusDown or
+	// empty when unknown.
+	Health   string         `json:"health,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// TopologyEdge is a call or data flow from one node to another.
+type TopologyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Protocol is how the nodes talk, such as http, grpc, sql or kafka.
+	Protocol string `json:"protocol,omitempty"`
+	// Direction is TopologyUpstream or TopologyDownstream; when empty it is
+	// derived from whether the edge ends or starts at Topology.Service.
+	Direction string `json:"direction,omitempty"`
+	Async     bool   `json:"async,omitempty"`
+}
+
+// Topology is a typed graph of a service and its neighbours.
+type Topology struct {
+	// Service is the ID of the node being described.
+	Service string         `json:"service"`
+	Nodes   []TopologyNode `json:"nodes"`
+	Edges   []TopologyEdge `json:"edges"`
+}
+
+// TopologyGrapher returns a typed topology graph. It is optional; when the
+// configured TopologyProvider also implements it, service.topology and the
+// scg://service/topology.* resources use the typed graph.
+type TopologyGrapher interface {
+	Topology(ctx context.Context) (*Topology, error)
+}
+
 // RouteInfo describes a single HTTP/gRPC route.
 type RouteInfo struct {
 	Method      string   `json:"method"`
@@ -98,6 +599,20 @@ type MigrationStatus struct {
 	Name    string `json:"name"`
 	Applied bool   `json:"applied"`
 	Batch   int    `json:"batch,omitempty"`
+
+	Version   string     `json:"version,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Checksum is computed from the migration file; AppliedChecksum is the
+	// value recorded by the migration tool when it applied the migration.
+	Checksum         string `json:"checksum,omitempty"`
+	AppliedChecksum  string `json:"applied_checksum,omitempty"`
+	ChecksumMismatch bool   `json:"checksum_mismatch,omitempty"`
+	// Dirty marks a migration that failed or was only partially applied.
+	Dirty bool `json:"dirty,omitempty"`
+	// OutOfOrder marks a pending migration older than the latest applied one.
+	OutOfOrder bool `json:"out_of_order,omitempty"`
+	// Missing marks a migration recorded as applied with no file on disk.
+	Missing bool `json:"missing,omitempty"`
 }
 
 // MigrationReader exposes migration status.
@@ -118,6 +633,35 @@ type CacheInspector interface {
 	Stats(ctx context.Context) (CacheStats, error)
 }
 
+// ConsumerGroup is a consumer group reading a queue or topic.
+type ConsumerGroup struct {
+	Name      string `json:"name"`
+	Consumers int    `json:"consumers"`
+	// Lag is the number of messages the group has not yet consumed.
+	Lag int64 `json:"lag"`
+}
+
+// QueueStatus describes one broker queue or topic.
+type QueueStatus struct {
+	Name string `json:"name"`
+	// Kind is "queue" or "topic" when the broker distinguishes them.
+	Kind  string `json:"kind,omitempty"`
+	Depth int64  `json:"depth"`
+	// Consumers counts connected consumers across all groups.
+	Consumers      int             `json:"consumers"`
+	ConsumerGroups []ConsumerGroup `json:"consumer_groups,omitempty"`
+	// DeadLetterQueue names the queue rejected messages are moved to.
+	DeadLetterQueue string `json:"dead_letter_queue,omitempty"`
+	DeadLetterDepth int64  `json:"dead_letter_depth"`
+	// OldestMessage is when the oldest unconsumed message was enqueued.
+	OldestMessage time.Time `json:"oldest_message,omitzero"`
+}
+
+// QueueInspector exposes broker queues, topics and consumer groups.
+type QueueInspector interface {
+	Queues(ctx context.Context) ([]QueueStatus, error)
+}
+
 // DocMatch represents a documentation search result.
 type DocMatch struct {
 	Path    string  `json:"path"`
@@ -136,6 +680,44 @@ type MetricsReader interface {
 	Summary(ctx context.Context) (map[string]any, error)
 }
 
+// Metric types, as declared by # TYPE in the exposition format.
+const (
+	MetricCounter   = "counter"
+	MetricGauge     = "gauge"
+	MetricHistogram = "histogram"
+	MetricSummary   = "summary"
+	MetricUntyped   = "untyped"
+)
+
+// MetricSample is one series value. Name is the full sample name, such as
+// http_request_duration_seconds_bucket.
+type MetricSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// MetricFamily groups the samples of one metric, including the _bucket,
+// _sum, _count and _total series of histograms, summaries and counters.
+type MetricFamily struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Help    string         `json:"help,omitempty"`
+	Samples []MetricSample `json:"samples"`
+}
+
+// MetricsScrape is one read of every metric family.
+type MetricsScrape struct {
+	At       time.Time      `json:"at"`
+	Families []MetricFamily `json:"families"`
+}
+
+// MetricsScraper is an optional extension of MetricsReader returning raw
+// samples; it enables metrics.query.
+type MetricsScraper interface {
+	Scrape(ctx context.Context) (*MetricsScrape, error)
+}
+
 // EnvIssue represents an environment configuration issue.
 type EnvIssue struct {
 	Key      string `json:"key"`